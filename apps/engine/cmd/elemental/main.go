@@ -0,0 +1,200 @@
+// Command elemental computes elemental weight percentages from chemical formulas and
+// audits the elemental_weight of supplement rows against their molecular_formula.
+//
+// Usage:
+//
+//	elemental [-element Zn] [-declared 21] [-tolerance 0.5] FORMULA
+//	elemental -audit [-tolerance 0.5]
+//
+// Given a formula, it prints the composition by mass; with -declared it also checks the
+// declared percentage of -element and exits with status 1 when it is off by more than
+// -tolerance percentage points. With -audit it checks every supplement with a molecular
+// formula in the database at DATABASE_URL and exits with status 1 when any row fails.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/config"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/db"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/formula"
+)
+
+// Percentage points a declared elemental weight may differ from the computed one,
+// allowing for rounding to one decimal place
+const defaultTolerance = 0.5
+
+// supplementFormula is a supplement row with a molecular formula.
+type supplementFormula struct {
+	Name            string
+	Formula         string
+	Element         *string
+	ElementalWeight *float64
+}
+
+// loader loads the supplements to audit.
+type loader func(ctx context.Context) ([]supplementFormula, error)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr, loadSupplementFormulas))
+}
+
+func run(args []string, stdout io.Writer, stderr io.Writer, load loader) int {
+	flags := flag.NewFlagSet("elemental", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	element := flags.String("element", "", "element symbol to report, e.g. Zn")
+	declared := flags.Float64("declared", 0, "declared elemental weight percentage of -element to check")
+	tolerance := flags.Float64("tolerance", defaultTolerance, "allowed difference in percentage points")
+	audit := flags.Bool("audit", false, "audit the supplements in the database at DATABASE_URL")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *audit {
+		if flags.NArg() != 0 {
+			fmt.Fprintln(stderr, "elemental: -audit takes no formula")
+			return 2
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		supplements, err := load(ctx)
+		if err != nil {
+			fmt.Fprintf(stderr, "elemental: %v\n", err)
+			return 1
+		}
+		return auditSupplements(stdout, supplements, *tolerance)
+	}
+
+	if flags.NArg() != 1 {
+		fmt.Fprintln(stderr, "elemental: expected one formula")
+		return 2
+	}
+	if *declared != 0 && *element == "" {
+		fmt.Fprintln(stderr, "elemental: -declared requires -element")
+		return 2
+	}
+
+	f, err := formula.Parse(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	if *element != "" {
+		if _, err := f.MassFraction(*element); err != nil {
+			fmt.Fprintf(stderr, "%v\n", err)
+			return 1
+		}
+	}
+
+	printComposition(stdout, f)
+
+	if *declared != 0 {
+		check, err := formula.CheckElementalWeight(flags.Arg(0), *element, *declared)
+		if err != nil {
+			fmt.Fprintf(stderr, "%v\n", err)
+			return 1
+		}
+		fmt.Fprintf(stdout, "\nDeclared %s %.2f%%, computed %.2f%% (%+.2f points): %s\n",
+			*element, check.DeclaredPercent, check.ComputedPercent, check.Difference, status(check, *tolerance))
+		if !check.Valid(*tolerance) {
+			return 1
+		}
+	}
+	return 0
+}
+
+func printComposition(w io.Writer, f formula.Formula) {
+	fmt.Fprintf(w, "%s, %.3f g/mol\n\n", f, f.MolarMass())
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "Element\tAtoms\tMass %")
+	for _, element := range f.Elements() {
+		fraction, _ := f.MassFraction(element)
+		fmt.Fprintf(table, "%s\t%g\t%.2f\n", element, f[element], fraction*100)
+	}
+	table.Flush()
+}
+
+// auditSupplements checks each supplement's elemental weight against its formula and
+// returns the exit status: 1 when any row fails.
+func auditSupplements(w io.Writer, supplements []supplementFormula, tolerance float64) int {
+	failed := 0
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "Supplement\tFormula\tElement\tDeclared %\tComputed %\tStatus")
+	for _, s := range supplements {
+		if s.Element == nil || s.ElementalWeight == nil {
+			failed++
+			fmt.Fprintf(table, "%s\t%s\t-\t-\t-\tmissing elemental symbol or weight\n", s.Name, s.Formula)
+			continue
+		}
+
+		check, err := formula.CheckElementalWeight(s.Formula, *s.Element, *s.ElementalWeight)
+		if err != nil {
+			failed++
+			fmt.Fprintf(table, "%s\t%s\t%s\t%.2f\t-\t%v\n", s.Name, s.Formula, *s.Element, *s.ElementalWeight, err)
+			continue
+		}
+		if !check.Valid(tolerance) {
+			failed++
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%.2f\t%.2f\t%s\n",
+			s.Name, s.Formula, *s.Element, check.DeclaredPercent, check.ComputedPercent, status(check, tolerance))
+	}
+	table.Flush()
+
+	fmt.Fprintf(w, "\n%d of %d supplements failed (tolerance %g points)\n", failed, len(supplements), tolerance)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func status(check formula.ElementalWeightCheck, tolerance float64) string {
+	if check.Valid(tolerance) {
+		return "ok"
+	}
+	return fmt.Sprintf("MISMATCH (%+.2f points)", check.Difference)
+}
+
+// loadSupplementFormulas loads the supplements with a molecular formula from DATABASE_URL.
+func loadSupplementFormulas(ctx context.Context) ([]supplementFormula, error) {
+	cfg := config.Load()
+	if cfg.DatabaseURL == "" {
+		return nil, errors.New("DATABASE_URL is required for -audit")
+	}
+
+	pool, err := db.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return nil, err
+	}
+	defer pool.Close()
+
+	rows, err := pool.Query(ctx, `
+		SELECT name, molecular_formula, elemental_symbol, elemental_weight
+		FROM supplement
+		WHERE molecular_formula IS NOT NULL
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var supplements []supplementFormula
+	for rows.Next() {
+		var s supplementFormula
+		if err := rows.Scan(&s.Name, &s.Formula, &s.Element, &s.ElementalWeight); err != nil {
+			return nil, err
+		}
+		supplements = append(supplements, s)
+	}
+	return supplements, rows.Err()
+}
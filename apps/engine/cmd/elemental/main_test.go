@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func noDatabase(context.Context) ([]supplementFormula, error) {
+	return nil, errors.New("no database")
+}
+
+func TestRun_Composition(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"Mg(C2H4NO2)2"}, &stdout, &stderr, noDatabase)
+
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	output := stdout.String()
+	for _, want := range []string{"C4H8MgN2O4, 172.4", "Mass %", "Mg", "14.10"} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestRun_DeclaredCheck(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-element", "Zn", "-declared", "14.3", "C12H22O14Zn"}, &stdout, &stderr, noDatabase); code != 0 {
+		t.Fatalf("expected anhydrous zinc gluconate to pass, got %d:\n%s%s", code, stdout.String(), stderr.String())
+	}
+
+	stdout.Reset()
+	if code := run([]string{"-element", "Zn", "-declared", "14.3", "C12H22O14Zn·3H2O"}, &stdout, &stderr, noDatabase); code != 1 {
+		t.Fatalf("expected the trihydrate to fail, got %d", code)
+	}
+	if !strings.Contains(stdout.String(), "MISMATCH") {
+		t.Fatalf("expected a mismatch, got:\n%s", stdout.String())
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"no formula", nil, 2},
+		{"two formulas", []string{"MgO", "ZnO"}, 2},
+		{"declared without element", []string{"-declared", "60", "MgO"}, 2},
+		{"audit with formula", []string{"-audit", "MgO"}, 2},
+		{"invalid formula", []string{"Mg(O"}, 1},
+		{"element not in formula", []string{"-element", "Zn", "MgO"}, 1},
+		{"audit without database", []string{"-audit"}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tt.args, &stdout, &stderr, noDatabase); code != tt.code {
+				t.Fatalf("expected exit code %d, got %d (%s)", tt.code, code, stderr.String())
+			}
+			if stderr.Len() == 0 {
+				t.Fatalf("expected an error message")
+			}
+		})
+	}
+}
+
+func TestRun_Audit(t *testing.T) {
+	symbol := func(s string) *string { return &s }
+	weight := func(w float64) *float64 { return &w }
+	supplements := []supplementFormula{
+		{Name: "Magnesium Glycinate", Formula: "Mg(C2H4NO2)2", Element: symbol("Mg"), ElementalWeight: weight(14.1)},
+		{Name: "Zinc Gluconate", Formula: "C12H22O14Zn·3H2O", Element: symbol("Zn"), ElementalWeight: weight(14.3)},
+		{Name: "Boron Glycinate", Formula: "B(C2H4NO2)3", Element: nil, ElementalWeight: weight(4.6)},
+		{Name: "Iodine", Formula: "KI", Element: symbol("Zn"), ElementalWeight: weight(76.5)},
+	}
+	load := func(context.Context) ([]supplementFormula, error) { return supplements, nil }
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-audit"}, &stdout, &stderr, load)
+
+	if code != 1 {
+		t.Fatalf("expected exit code 1, got %d: %s", code, stderr.String())
+	}
+	output := stdout.String()
+	for _, want := range []string{"Magnesium Glycinate", "ok", "MISMATCH (+1.47 points)", "missing elemental symbol", "does not occur", "3 of 4 supplements failed"} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	stdout.Reset()
+	supplements = supplements[:1]
+	if code := run([]string{"-audit"}, &stdout, &stderr, load); code != 0 {
+		t.Fatalf("expected a clean audit to pass, got %d:\n%s", code, stdout.String())
+	}
+}
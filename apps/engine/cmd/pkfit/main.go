@@ -0,0 +1,148 @@
+// Command pkfit fits SupplementPK parameters to concentration-time data.
+//
+// Usage:
+//
+//	pkfit [-kinetics first_order|one_compartment|michaelis_menten] [-dose mg]
+//	      [-time-unit min|h] [-confidence 0.95] [observations.csv]
+//
+// The CSV has two columns, time since a single dose and concentration, with an optional
+// header row. Lines starting with # are ignored. Observations are read from stdin when no
+// file is given.
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/kinetics"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/pkfit"
+)
+
+// Minutes per unit accepted by -time-unit
+var timeUnits = map[string]float64{
+	"min": 1,
+	"h":   60,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("pkfit", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	kineticsType := flags.String("kinetics", string(kinetics.FirstOrder), "kinetics model: first_order, one_compartment or michaelis_menten")
+	dose := flags.Float64("dose", 0, "administered dose in mg (required for michaelis_menten)")
+	timeUnit := flags.String("time-unit", "min", "unit of the time column: min or h")
+	confidence := flags.Float64("confidence", pkfit.DefaultConfidence, "confidence interval level")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	minutesPerUnit, ok := timeUnits[*timeUnit]
+	if !ok {
+		fmt.Fprintf(stderr, "pkfit: unknown time unit %q\n", *timeUnit)
+		return 2
+	}
+
+	input := stdin
+	if flags.NArg() > 1 {
+		fmt.Fprintln(stderr, "pkfit: expected at most one CSV file")
+		return 2
+	}
+	if flags.NArg() == 1 {
+		file, err := os.Open(flags.Arg(0))
+		if err != nil {
+			fmt.Fprintf(stderr, "pkfit: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		input = file
+	}
+
+	observations, err := readObservations(input, minutesPerUnit)
+	if err != nil {
+		fmt.Fprintf(stderr, "pkfit: %v\n", err)
+		return 1
+	}
+
+	result, err := pkfit.Fit(observations, pkfit.Options{
+		KineticsType: kinetics.KineticsType(*kineticsType),
+		Dose:         *dose,
+		Confidence:   *confidence,
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+
+	printResult(stdout, *kineticsType, *confidence, observations, result)
+	return 0
+}
+
+// readObservations parses time,concentration rows, converting times to minutes.
+// A first row that is not numeric is treated as a header.
+func readObservations(r io.Reader, minutesPerUnit float64) ([]pkfit.Observation, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var observations []pkfit.Observation
+	for row := 0; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		if len(record) < 2 {
+			return nil, fmt.Errorf("line %d: expected time and concentration columns", line)
+		}
+		t, errT := strconv.ParseFloat(strings.TrimSpace(record[0]), 64)
+		c, errC := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if errT != nil || errC != nil {
+			if row == 0 {
+				continue // Header
+			}
+			return nil, fmt.Errorf("line %d: invalid number", line)
+		}
+		observations = append(observations, pkfit.Observation{Minutes: t * minutesPerUnit, Concentration: c})
+	}
+
+	if len(observations) == 0 {
+		return nil, errors.New("no observations")
+	}
+	return observations, nil
+}
+
+func printResult(w io.Writer, kineticsType string, confidence float64, observations []pkfit.Observation, result pkfit.Result) {
+	fmt.Fprintf(w, "Fitted %s model to %d observations (%d degrees of freedom, %d iterations)\n\n",
+		kineticsType, len(observations), result.DegreesOfFreedom, result.Iterations)
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(table, "Parameter\tEstimate\tStd. error\t%g%% CI\n", confidence*100)
+	for _, p := range result.Parameters {
+		fmt.Fprintf(table, "%s\t%.4g\t%.3g\t[%.4g, %.4g]\n", p.Name, p.Value, p.StdError, p.Lower, p.Upper)
+	}
+	table.Flush()
+
+	fmt.Fprintf(w, "\nRMSE %.4g, R² %.4f\n\n", result.RMSE, result.RSquared)
+
+	table = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "Minutes\tObserved\tPredicted\tResidual")
+	for i, o := range observations {
+		fmt.Fprintf(table, "%g\t%.4g\t%.4g\t%+.3g\n", o.Minutes, o.Concentration, result.Predicted[i], result.Residuals[i])
+	}
+	table.Flush()
+}
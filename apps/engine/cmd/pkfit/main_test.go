@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const caffeineCSV = `# Caffeine 200 mg, plasma mg/L
+hours,concentration
+0.25,2.1
+0.5,3.4
+0.75,4.0
+1,4.2
+1.5,4.0
+2,3.7
+3,3.2
+4,2.8
+6,2.1
+8,1.6
+12,0.9
+`
+
+func TestRun_FitsCSV(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-kinetics", "one_compartment", "-time-unit", "h"}, strings.NewReader(caffeineCSV), &stdout, &stderr)
+
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	output := stdout.String()
+	for _, want := range []string{"one_compartment model to 11 observations", "PeakMinutes", "HalfLifeMinutes", "95% CI", "Residual"} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		input string
+		code  int
+	}{
+		{"unknown time unit", []string{"-time-unit", "days"}, caffeineCSV, 2},
+		{"invalid row", nil, "time,concentration\n30,1\nabc,2\n", 1},
+		{"missing column", nil, "30\n", 1},
+		{"no observations", nil, "time,concentration\n", 1},
+		{"dose required", []string{"-kinetics", "michaelis_menten"}, caffeineCSV, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tt.args, strings.NewReader(tt.input), &stdout, &stderr); code != tt.code {
+				t.Fatalf("expected exit code %d, got %d (%s)", tt.code, code, stderr.String())
+			}
+			if stderr.Len() == 0 {
+				t.Fatalf("expected an error message")
+			}
+		})
+	}
+}
@@ -43,6 +43,13 @@ func main() {
 	// Protected API endpoints
 	mux.HandleFunc("POST /api/analyze", authMiddleware.Protect(handler.Analyze))
 	mux.HandleFunc("POST /api/timing", authMiddleware.Protect(handler.CheckTiming))
+	mux.HandleFunc("POST /api/timeline", authMiddleware.Protect(handler.Timeline))
+	mux.HandleFunc("POST /api/steady-state", authMiddleware.Protect(handler.SteadyState))
+	mux.HandleFunc("POST /api/calibrate", authMiddleware.Protect(handler.Calibrate))
+	mux.HandleFunc("POST /api/optimize-dosing", authMiddleware.Protect(handler.OptimizeDosing))
+	mux.HandleFunc("POST /api/effect", authMiddleware.Protect(handler.Effect))
+	mux.HandleFunc("POST /api/exposure", authMiddleware.Protect(handler.Exposure))
+	mux.HandleFunc("POST /api/parse", authMiddleware.Protect(handler.Parse))
 
 	// Create server
 	server := &http.Server{
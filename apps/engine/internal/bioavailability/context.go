@@ -0,0 +1,128 @@
+package bioavailability
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/kinetics"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+)
+
+// PeakFactors holds the Tmax multiplier for each meal context.
+// Stored Tmax is taken as the fasted value; food slows gastric emptying and delays the
+// peak, fat-rich meals the most. Stored bioavailability has a different baseline: it is
+// the value outside the supplement's optimal contexts, which Evaluate scales up by the
+// rule's OptimalMultiplier, so fasted-optimal supplements gain F when fasted and
+// fat-soluble ones gain it with fat.
+var PeakFactors = map[models.MealContext]float64{
+	models.MealContextFasted:   1.0,
+	models.MealContextWithMeal: 1.5,
+	models.MealContextWithFat:  1.75,
+	models.MealContextPostMeal: 1.25,
+}
+
+// Result is the outcome of evaluating a meal context against a supplement's rule.
+type Result struct {
+	Rule       Rule
+	HasRule    bool
+	IsOptimal  bool
+	Warning    string
+	Multiplier float64 // Bioavailability multiplier (1 when not optimal)
+}
+
+// Evaluate checks whether mealContext is optimal for a supplement.
+// An empty meal context is only flagged when the rule requires a specific context.
+func Evaluate(supplementName string, safetyCategory string, mealContext models.MealContext) Result {
+	rule, ok := FindRule(supplementName, safetyCategory)
+	if !ok {
+		return Result{IsOptimal: true, Multiplier: 1}
+	}
+
+	result := Result{Rule: rule, HasRule: true, Multiplier: 1}
+
+	if mealContext == "" {
+		result.IsOptimal = !rule.Required
+		if rule.Required {
+			result.Warning = rule.WarningMessage
+		}
+		return result
+	}
+
+	result.IsOptimal = rule.IsOptimal(mealContext)
+	if result.IsOptimal {
+		result.Multiplier = rule.OptimalMultiplier
+	} else {
+		result.Warning = rule.WarningMessage
+	}
+
+	return result
+}
+
+// MealEffect returns the kinetics adjustment for a dose taken in mealContext.
+// Bioavailability follows the supplement's rule; Tmax follows PeakFactors.
+func MealEffect(supplementName string, safetyCategory string, mealContext models.MealContext) kinetics.MealEffect {
+	if mealContext == "" {
+		return kinetics.MealEffect{}
+	}
+
+	return kinetics.MealEffect{
+		BioavailabilityFactor: Evaluate(supplementName, safetyCategory, mealContext).Multiplier,
+		PeakFactor:            PeakFactors[mealContext],
+	}
+}
+
+// Advise returns an advisory when a supplement is taken in a suboptimal meal context,
+// e.g. "Vitamin D3 taken fasted: ~68% of optimal absorption".
+func Advise(supplement models.Supplement, mealContext models.MealContext) (models.MealAdvisory, bool) {
+	if mealContext == "" {
+		return models.MealAdvisory{}, false
+	}
+
+	safetyCategory := ""
+	if supplement.SafetyCategory != nil {
+		safetyCategory = *supplement.SafetyCategory
+	}
+
+	result := Evaluate(supplement.Name, safetyCategory, mealContext)
+	if !result.HasRule || result.IsOptimal {
+		return models.MealAdvisory{}, false
+	}
+
+	absorptionPercent := math.Round(100 / result.Rule.OptimalMultiplier)
+
+	advisory := models.MealAdvisory{
+		Supplement: models.SupplementInfo{
+			ID:   supplement.ID,
+			Name: supplement.Name,
+			Form: supplement.Form,
+		},
+		MealContext:       mealContext,
+		OptimalContexts:   result.Rule.OptimalContexts,
+		AbsorptionPercent: absorptionPercent,
+		Message: fmt.Sprintf("%s taken %s: ~%.0f%% of optimal absorption",
+			supplement.Name, describeMealContext(mealContext), absorptionPercent),
+		Recommendation: result.Rule.WarningMessage,
+		Mechanism:      result.Rule.Mechanism,
+	}
+	if result.Rule.ResearchURL != "" {
+		researchURL := result.Rule.ResearchURL
+		advisory.ResearchURL = &researchURL
+	}
+
+	return advisory, true
+}
+
+func describeMealContext(mealContext models.MealContext) string {
+	switch mealContext {
+	case models.MealContextFasted:
+		return "fasted"
+	case models.MealContextWithMeal:
+		return "with a meal"
+	case models.MealContextWithFat:
+		return "with fat"
+	case models.MealContextPostMeal:
+		return "after a meal"
+	default:
+		return string(mealContext)
+	}
+}
@@ -0,0 +1,127 @@
+package bioavailability
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+)
+
+func stringPtr(value string) *string {
+	return &value
+}
+
+func TestFindRule(t *testing.T) {
+	tests := []struct {
+		name           string
+		supplementName string
+		safetyCategory string
+		wantMatch      string
+		wantFound      bool
+	}{
+		{"name partial match", "Magnesium Glycinate", "", "Magnesium", true},
+		{"name case-insensitive", "curcumin phytosome", "", "Curcumin", true},
+		{"safety category match", "Vitamin D3", "vitamin-d", "vitamin-d", true},
+		{"name takes precedence", "Iron Bisglycinate", "zinc", "Iron", true},
+		{"no match", "Creatine", "", "", false},
+		{"empty name and category", "", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, found := FindRule(tt.supplementName, tt.safetyCategory)
+			if found != tt.wantFound {
+				t.Fatalf("FindRule() found = %v, want %v", found, tt.wantFound)
+			}
+			if rule.Match != tt.wantMatch {
+				t.Errorf("FindRule() match = %q, want %q", rule.Match, tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name           string
+		mealContext    models.MealContext
+		wantOptimal    bool
+		wantMultiplier float64
+		wantWarning    bool
+	}{
+		{"optimal context", models.MealContextWithFat, true, 1.47, false},
+		{"suboptimal context", models.MealContextFasted, false, 1, true},
+		{"missing context on required rule", "", false, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Evaluate("Vitamin D3", "vitamin-d", tt.mealContext)
+			if result.IsOptimal != tt.wantOptimal {
+				t.Errorf("Evaluate() isOptimal = %v, want %v", result.IsOptimal, tt.wantOptimal)
+			}
+			if result.Multiplier != tt.wantMultiplier {
+				t.Errorf("Evaluate() multiplier = %v, want %v", result.Multiplier, tt.wantMultiplier)
+			}
+			if (result.Warning != "") != tt.wantWarning {
+				t.Errorf("Evaluate() warning = %q, wantWarning %v", result.Warning, tt.wantWarning)
+			}
+		})
+	}
+
+	// Optional rules do not warn without a context
+	result := Evaluate("Zinc Picolinate", "", "")
+	if !result.IsOptimal || result.Warning != "" {
+		t.Errorf("Optional rule without context should be optimal, got %+v", result)
+	}
+
+	// Supplements without a rule are always optimal
+	result = Evaluate("Creatine", "", models.MealContextFasted)
+	if result.HasRule || !result.IsOptimal || result.Multiplier != 1 {
+		t.Errorf("Supplement without a rule should be optimal, got %+v", result)
+	}
+}
+
+func TestMealEffect(t *testing.T) {
+	effect := MealEffect("Iron Bisglycinate", "", models.MealContextFasted)
+	if effect.BioavailabilityFactor != 1.8 || effect.PeakFactor != 1 {
+		t.Errorf("Fasted iron should get 1.8x F with baseline Tmax, got %+v", effect)
+	}
+
+	effect = MealEffect("Iron Bisglycinate", "", models.MealContextWithMeal)
+	if effect.BioavailabilityFactor != 1 || effect.PeakFactor != 1.5 {
+		t.Errorf("Iron with a meal should get baseline F and delayed Tmax, got %+v", effect)
+	}
+
+	if effect := MealEffect("Iron Bisglycinate", "", ""); effect.BioavailabilityFactor != 0 || effect.PeakFactor != 0 {
+		t.Errorf("No meal context should give a zero effect, got %+v", effect)
+	}
+}
+
+func TestAdvise(t *testing.T) {
+	vitaminD := models.Supplement{
+		ID:             "supp-d3",
+		Name:           "Vitamin D3",
+		SafetyCategory: stringPtr("vitamin-d"),
+	}
+
+	advisory, ok := Advise(vitaminD, models.MealContextFasted)
+	if !ok {
+		t.Fatalf("expected advisory for fasted vitamin D3")
+	}
+	if advisory.AbsorptionPercent != 68 {
+		t.Errorf("expected ~68%% absorption, got %v", advisory.AbsorptionPercent)
+	}
+	if !strings.HasPrefix(advisory.Message, "Vitamin D3 taken fasted: ~68%") {
+		t.Errorf("unexpected advisory message %q", advisory.Message)
+	}
+	if advisory.ResearchURL == nil {
+		t.Errorf("expected research URL to be set")
+	}
+
+	if _, ok := Advise(vitaminD, models.MealContextWithFat); ok {
+		t.Errorf("expected no advisory for vitamin D3 taken with fat")
+	}
+	if _, ok := Advise(vitaminD, ""); ok {
+		t.Errorf("expected no advisory without a meal context")
+	}
+}
@@ -0,0 +1,152 @@
+// Package calibration fits personal pharmacokinetic factors from blood test results.
+//
+// It ports the Individual Absorption Factor (IAF) algorithm of the web app's calibration
+// service: the biomarker level predicted from logged intake is compared with the measured
+// level, and the ratio adjusts bioavailability (IAF < 1) or clearance (IAF > 1).
+package calibration
+
+import (
+	"strings"
+)
+
+// BiomarkerType identifies a blood marker. Values match the biomarker_type database enum.
+type BiomarkerType string
+
+const (
+	Biomarker25OHD        BiomarkerType = "25_oh_d"
+	BiomarkerFerritin     BiomarkerType = "ferritin"
+	BiomarkerSerumIron    BiomarkerType = "serum_iron"
+	BiomarkerRBCMagnesium BiomarkerType = "rbc_magnesium"
+	BiomarkerSerumZinc    BiomarkerType = "serum_zinc"
+	BiomarkerSerumCopper  BiomarkerType = "serum_copper"
+	BiomarkerB12          BiomarkerType = "b12"
+	BiomarkerFolate       BiomarkerType = "folate"
+)
+
+// Biomarker maps a blood marker to the supplements that drive it and its reference range.
+type Biomarker struct {
+	SupplementNames  []string
+	SafetyCategory   string  // Empty when the marker is not tied to a safety category
+	ConversionFactor float64 // Serum level per unit of average daily intake
+	HalfLifeDays     float64
+	SteadyStateDays  int // Intake lookback window
+	TargetMin        float64
+	TargetMax        float64
+	Unit             string
+}
+
+// Biomarkers holds the configuration of every supported marker (ported from BIOMARKER_CONFIG).
+var Biomarkers = map[BiomarkerType]Biomarker{
+	Biomarker25OHD: {
+		SupplementNames:  []string{"Vitamin D3"},
+		SafetyCategory:   "vitamin-d3",
+		ConversionFactor: 0.025, // IU to ng/mL serum level approximation
+		HalfLifeDays:     15,
+		SteadyStateDays:  90,
+		TargetMin:        40,
+		TargetMax:        60,
+		Unit:             "ng/mL",
+	},
+	BiomarkerFerritin: {
+		SupplementNames:  []string{"Iron Bisglycinate"},
+		SafetyCategory:   "iron",
+		ConversionFactor: 0.5, // mg elemental iron to ferritin approximation
+		HalfLifeDays:     30,
+		SteadyStateDays:  120,
+		TargetMin:        50,
+		TargetMax:        150,
+		Unit:             "ng/mL",
+	},
+	BiomarkerSerumIron: {
+		SupplementNames:  []string{"Iron Bisglycinate"},
+		SafetyCategory:   "iron",
+		ConversionFactor: 1.0,
+		HalfLifeDays:     1, // Serum iron is highly variable
+		SteadyStateDays:  7,
+		TargetMin:        60,
+		TargetMax:        170,
+		Unit:             "mcg/dL",
+	},
+	BiomarkerRBCMagnesium: {
+		SupplementNames: []string{
+			"Magnesium Glycinate",
+			"Magnesium Citrate",
+			"Magnesium L-Threonate",
+			"Magnesium Oxide",
+			"Magnesium Malate",
+		},
+		SafetyCategory:   "magnesium",
+		ConversionFactor: 0.01, // mg to mEq/L approximation
+		HalfLifeDays:     30,
+		SteadyStateDays:  90,
+		TargetMin:        5.0,
+		TargetMax:        6.5,
+		Unit:             "mg/dL",
+	},
+	BiomarkerSerumZinc: {
+		SupplementNames:  []string{"Zinc Picolinate", "Zinc Gluconate", "Zinc Carnosine"},
+		SafetyCategory:   "zinc",
+		ConversionFactor: 0.5,
+		HalfLifeDays:     14,
+		SteadyStateDays:  60,
+		TargetMin:        80,
+		TargetMax:        120,
+		Unit:             "mcg/dL",
+	},
+	BiomarkerSerumCopper: {
+		SupplementNames:  []string{"Copper Bisglycinate"},
+		SafetyCategory:   "copper",
+		ConversionFactor: 10,
+		HalfLifeDays:     30,
+		SteadyStateDays:  90,
+		TargetMin:        70,
+		TargetMax:        140,
+		Unit:             "mcg/dL",
+	},
+	BiomarkerB12: {
+		SupplementNames:  []string{"Vitamin B12"},
+		ConversionFactor: 0.1,
+		HalfLifeDays:     6,
+		SteadyStateDays:  60,
+		TargetMin:        500,
+		TargetMax:        1000,
+		Unit:             "pg/mL",
+	},
+	BiomarkerFolate: {
+		SupplementNames:  []string{"Folate"},
+		ConversionFactor: 0.05,
+		HalfLifeDays:     3,
+		SteadyStateDays:  30,
+		TargetMin:        10,
+		TargetMax:        25,
+		Unit:             "ng/mL",
+	},
+}
+
+// Tracks reports whether the marker reflects intake of a supplement, matched by name or,
+// for forms missing from SupplementNames, by safety category.
+func (b Biomarker) Tracks(supplementName string, safetyCategory string) bool {
+	for _, name := range b.SupplementNames {
+		if strings.EqualFold(name, supplementName) {
+			return true
+		}
+	}
+	return b.SafetyCategory != "" && b.SafetyCategory == safetyCategory
+}
+
+// Status classifies a measured value against the reference range:
+// "deficient", "suboptimal", "optimal", "elevated" or "high".
+func (b Biomarker) Status(value float64) string {
+	switch {
+	case value < b.TargetMin*0.5:
+		return "deficient"
+	case value < b.TargetMin:
+		return "suboptimal"
+	case value <= b.TargetMax:
+		return "optimal"
+	case value <= b.TargetMax*1.5:
+		return "elevated"
+	default:
+		return "high"
+	}
+}
@@ -0,0 +1,214 @@
+package calibration
+
+import (
+	"math"
+	"time"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/kinetics"
+)
+
+// Confidence grades how well logged intake supports a calibration.
+type Confidence string
+
+const (
+	ConfidenceHigh   Confidence = "high"
+	ConfidenceMedium Confidence = "medium"
+	ConfidenceLow    Confidence = "low"
+)
+
+// Confidence thresholds on the number of intake logs in the lookback window
+const (
+	highConfidenceLogs   = 60
+	mediumConfidenceLogs = 20
+)
+
+// Intake is one logged dose of a supplement tracked by the marker.
+// Dosage is the raw logged amount, as in the web calibration service.
+type Intake struct {
+	Dosage   float64
+	LoggedAt time.Time
+}
+
+// Measurement is a blood test result.
+type Measurement struct {
+	Value      float64
+	MeasuredAt time.Time
+}
+
+// Datapoint pairs a measurement with the level predicted from intake before it.
+type Datapoint struct {
+	MeasuredAt  time.Time
+	Measured    float64
+	Predicted   float64
+	IntakeCount int
+}
+
+// GoodnessOfFit summarises how well a single IAF explains all measurements.
+type GoodnessOfFit struct {
+	Measurements int      // Measurements with a non-zero prediction
+	RSquared     *float64 // Coefficient of determination; nil with fewer than two measurements
+	RMSE         float64  // Root mean square error, in the marker's unit
+}
+
+// Result is a fitted calibration for one marker.
+type Result struct {
+	PredictedValue             float64  // Prediction for the latest measurement
+	IndividualAbsorptionFactor float64  // Fitted measured / predicted ratio
+	CalibratedF                *float64 // F multiplier when IAF < 1
+	CalibratedCL               *float64 // CL multiplier when IAF > 1
+	Confidence                 Confidence
+	DatapointsUsed             int // Intake logs behind the latest measurement
+	Fit                        GoodnessOfFit
+}
+
+// Predict returns the steady-state level expected from the intake logged in the marker's
+// lookback window before measuredAt, and the number of logs used:
+//
+//	predicted = (Σ dosage / SteadyStateDays) * ConversionFactor
+func Predict(biomarker Biomarker, intake []Intake, measuredAt time.Time) (float64, int) {
+	lookbackStart := measuredAt.AddDate(0, 0, -biomarker.SteadyStateDays)
+
+	total := 0.0
+	count := 0
+	for _, in := range intake {
+		if in.LoggedAt.Before(lookbackStart) || in.LoggedAt.After(measuredAt) {
+			continue
+		}
+		total += in.Dosage
+		count++
+	}
+
+	if count == 0 || biomarker.SteadyStateDays <= 0 {
+		return 0, count
+	}
+	return total / float64(biomarker.SteadyStateDays) * biomarker.ConversionFactor, count
+}
+
+// Fit fits the Individual Absorption Factor to the user's measurements of one marker.
+// The last measurement is the newest; earlier ones refine the fit.
+//
+// The IAF is the least-squares slope through the origin of measured against predicted:
+//
+//	IAF = Σ(m_i * p_i) / Σ(p_i²)
+//
+// which reduces to measured / predicted for a single measurement. IAF < 1 lowers
+// bioavailability (CalibratedF = IAF); IAF > 1 lowers clearance (CalibratedCL = 1 / IAF).
+func Fit(biomarker Biomarker, measurements []Measurement, intake []Intake) Result {
+	result := Result{IndividualAbsorptionFactor: 1, Confidence: ConfidenceLow}
+	if len(measurements) == 0 {
+		return result
+	}
+
+	points := make([]Datapoint, 0, len(measurements))
+	for _, m := range measurements {
+		predicted, count := Predict(biomarker, intake, m.MeasuredAt)
+		points = append(points, Datapoint{
+			MeasuredAt:  m.MeasuredAt,
+			Measured:    m.Value,
+			Predicted:   predicted,
+			IntakeCount: count,
+		})
+	}
+
+	latest := points[len(points)-1]
+	result.PredictedValue = latest.Predicted
+	result.DatapointsUsed = latest.IntakeCount
+	if latest.Predicted <= 0 {
+		return result
+	}
+
+	var sumMP, sumPP float64
+	usable := make([]Datapoint, 0, len(points))
+	for _, p := range points {
+		if p.Predicted <= 0 {
+			continue
+		}
+		usable = append(usable, p)
+		sumMP += p.Measured * p.Predicted
+		sumPP += p.Predicted * p.Predicted
+	}
+
+	iaf := sumMP / sumPP
+	result.IndividualAbsorptionFactor = iaf
+	result.Fit = goodnessOfFit(usable, iaf)
+
+	if iaf < 1 {
+		f := iaf
+		result.CalibratedF = &f
+	} else if iaf > 1 {
+		cl := 1 / iaf
+		result.CalibratedCL = &cl
+	}
+
+	switch {
+	case latest.IntakeCount >= highConfidenceLogs:
+		result.Confidence = ConfidenceHigh
+	case latest.IntakeCount >= mediumConfidenceLogs:
+		result.Confidence = ConfidenceMedium
+	}
+
+	return result
+}
+
+// goodnessOfFit computes R² and RMSE of measured = iaf * predicted.
+func goodnessOfFit(points []Datapoint, iaf float64) GoodnessOfFit {
+	fit := GoodnessOfFit{Measurements: len(points)}
+	if len(points) == 0 {
+		return fit
+	}
+
+	mean := 0.0
+	for _, p := range points {
+		mean += p.Measured
+	}
+	mean /= float64(len(points))
+
+	var ssRes, ssTot float64
+	for _, p := range points {
+		residual := p.Measured - iaf*p.Predicted
+		ssRes += residual * residual
+		ssTot += (p.Measured - mean) * (p.Measured - mean)
+	}
+
+	fit.RMSE = math.Sqrt(ssRes / float64(len(points)))
+	if len(points) >= 2 && ssTot > 0 {
+		r2 := 1 - ssRes/ssTot
+		fit.RSquared = &r2
+	}
+	return fit
+}
+
+// Record is a stored calibration from the user_biomarker table.
+type Record struct {
+	BiomarkerType BiomarkerType
+	CalibratedF   *float32
+	CalibratedCL  *float32
+	MeasuredAt    time.Time
+}
+
+// ForSupplement returns the kinetics calibration from the most recent record whose marker
+// tracks the supplement. Records without factors reset the supplement to population values.
+func ForSupplement(records []Record, supplementName string, safetyCategory string) kinetics.Calibration {
+	var latest *Record
+	for i := range records {
+		biomarker, ok := Biomarkers[records[i].BiomarkerType]
+		if !ok || !biomarker.Tracks(supplementName, safetyCategory) {
+			continue
+		}
+		if latest == nil || records[i].MeasuredAt.After(latest.MeasuredAt) {
+			latest = &records[i]
+		}
+	}
+
+	var calibration kinetics.Calibration
+	if latest == nil {
+		return calibration
+	}
+	if latest.CalibratedF != nil && *latest.CalibratedF > 0 {
+		calibration.BioavailabilityFactor = float64(*latest.CalibratedF)
+	}
+	if latest.CalibratedCL != nil && *latest.CalibratedCL > 0 {
+		calibration.ClearanceFactor = float64(*latest.CalibratedCL)
+	}
+	return calibration
+}
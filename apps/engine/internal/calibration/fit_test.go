@@ -0,0 +1,163 @@
+package calibration
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var measuredAt = time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+
+// dailyIntake logs one dose per day for the given number of days before measuredAt.
+func dailyIntake(dosage float64, days int, before time.Time) []Intake {
+	intake := make([]Intake, 0, days)
+	for i := 1; i <= days; i++ {
+		intake = append(intake, Intake{Dosage: dosage, LoggedAt: before.AddDate(0, 0, -i)})
+	}
+	return intake
+}
+
+func TestPredict_AveragesOverLookback(t *testing.T) {
+	biomarker := Biomarkers[Biomarker25OHD]
+
+	// 2000 IU daily for the full 90 days: 2000 * 0.025 = 50 ng/mL
+	intake := dailyIntake(2000, 120, measuredAt)
+	predicted, count := Predict(biomarker, intake, measuredAt)
+	if count != 90 {
+		t.Errorf("Expected 90 logs in the lookback window, got %d", count)
+	}
+	if math.Abs(predicted-50) > 1e-9 {
+		t.Errorf("Expected prediction of 50, got %v", predicted)
+	}
+}
+
+func TestFit_SingleMeasurementMatchesIAF(t *testing.T) {
+	biomarker := Biomarkers[Biomarker25OHD]
+	intake := dailyIntake(2000, 90, measuredAt)
+
+	tests := []struct {
+		name       string
+		measured   float64
+		wantIAF    float64
+		wantF      bool
+		wantCL     bool
+		confidence Confidence
+	}{
+		{"poor_absorber", 30, 0.6, true, false, ConfidenceHigh},
+		{"high_retention", 80, 1.6, false, true, ConfidenceHigh},
+		{"as_predicted", 50, 1, false, false, ConfidenceHigh},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Fit(biomarker, []Measurement{{Value: tt.measured, MeasuredAt: measuredAt}}, intake)
+
+			if math.Abs(result.IndividualAbsorptionFactor-tt.wantIAF) > 1e-9 {
+				t.Errorf("IAF = %v, want %v", result.IndividualAbsorptionFactor, tt.wantIAF)
+			}
+			if (result.CalibratedF != nil) != tt.wantF || (result.CalibratedCL != nil) != tt.wantCL {
+				t.Errorf("Unexpected factors: F=%v CL=%v", result.CalibratedF, result.CalibratedCL)
+			}
+			if tt.wantCL && math.Abs(*result.CalibratedCL-1/tt.wantIAF) > 1e-9 {
+				t.Errorf("CL factor = %v, want %v", *result.CalibratedCL, 1/tt.wantIAF)
+			}
+			if result.Confidence != tt.confidence {
+				t.Errorf("Confidence = %v, want %v", result.Confidence, tt.confidence)
+			}
+			if result.Fit.Measurements != 1 || result.Fit.RSquared != nil || result.Fit.RMSE != 0 {
+				t.Errorf("Single measurement should fit exactly without R², got %+v", result.Fit)
+			}
+		})
+	}
+}
+
+func TestFit_NoIntake(t *testing.T) {
+	result := Fit(Biomarkers[BiomarkerFerritin], []Measurement{{Value: 80, MeasuredAt: measuredAt}}, nil)
+
+	if result.IndividualAbsorptionFactor != 1 || result.CalibratedF != nil || result.CalibratedCL != nil {
+		t.Errorf("No intake should not calibrate, got %+v", result)
+	}
+	if result.Confidence != ConfidenceLow || result.DatapointsUsed != 0 {
+		t.Errorf("No intake should have low confidence, got %+v", result)
+	}
+}
+
+func TestFit_MultipleMeasurementsGoodnessOfFit(t *testing.T) {
+	biomarker := Biomarkers[Biomarker25OHD]
+	earlier := measuredAt.AddDate(0, 0, -120)
+
+	// 1000 IU for the first window, 4000 IU for the second: predictions 25 and 100
+	intake := append(dailyIntake(1000, 90, earlier), dailyIntake(4000, 90, measuredAt)...)
+
+	// Consistent absorber at IAF 0.8: perfect fit
+	consistent := Fit(biomarker, []Measurement{
+		{Value: 20, MeasuredAt: earlier},
+		{Value: 80, MeasuredAt: measuredAt},
+	}, intake)
+	if math.Abs(consistent.IndividualAbsorptionFactor-0.8) > 1e-9 {
+		t.Errorf("IAF = %v, want 0.8", consistent.IndividualAbsorptionFactor)
+	}
+	if consistent.Fit.RSquared == nil || math.Abs(*consistent.Fit.RSquared-1) > 1e-9 {
+		t.Errorf("Expected R² of 1, got %v", consistent.Fit.RSquared)
+	}
+
+	// Inconsistent results fit worse
+	noisy := Fit(biomarker, []Measurement{
+		{Value: 40, MeasuredAt: earlier},
+		{Value: 60, MeasuredAt: measuredAt},
+	}, intake)
+	if noisy.Fit.RSquared == nil || *noisy.Fit.RSquared >= 1 {
+		t.Errorf("Expected R² below 1, got %v", noisy.Fit.RSquared)
+	}
+	if noisy.Fit.RMSE <= 0 {
+		t.Errorf("Expected positive RMSE, got %v", noisy.Fit.RMSE)
+	}
+	// Least squares through the origin: (40*25 + 60*100) / (25² + 100²)
+	if want := 7000.0 / 10625; math.Abs(noisy.IndividualAbsorptionFactor-want) > 1e-9 {
+		t.Errorf("IAF = %v, want %v", noisy.IndividualAbsorptionFactor, want)
+	}
+}
+
+func TestForSupplement_UsesLatestTrackingRecord(t *testing.T) {
+	f := float32(0.7)
+	cl := float32(0.5)
+	records := []Record{
+		{BiomarkerType: BiomarkerFerritin, CalibratedF: &f, MeasuredAt: measuredAt.AddDate(0, -2, 0)},
+		{BiomarkerType: BiomarkerSerumIron, CalibratedCL: &cl, MeasuredAt: measuredAt},
+		{BiomarkerType: Biomarker25OHD, CalibratedF: &f, MeasuredAt: measuredAt},
+	}
+
+	iron := ForSupplement(records, "Ferrous Sulfate", "iron")
+	if iron.ClearanceFactor != 0.5 || iron.BioavailabilityFactor != 0 {
+		t.Errorf("Expected the latest iron calibration, got %+v", iron)
+	}
+
+	vitaminD := ForSupplement(records, "vitamin d3", "")
+	if vitaminD.BioavailabilityFactor != float64(f) {
+		t.Errorf("Expected name match for Vitamin D3, got %+v", vitaminD)
+	}
+
+	if other := ForSupplement(records, "Creatine", ""); other.BioavailabilityFactor != 0 || other.ClearanceFactor != 0 {
+		t.Errorf("Untracked supplement should not be calibrated, got %+v", other)
+	}
+}
+
+func TestBiomarkerStatus(t *testing.T) {
+	biomarker := Biomarkers[Biomarker25OHD]
+	tests := []struct {
+		value float64
+		want  string
+	}{
+		{15, "deficient"},
+		{30, "suboptimal"},
+		{50, "optimal"},
+		{75, "elevated"},
+		{100, "high"},
+	}
+
+	for _, tt := range tests {
+		if got := biomarker.Status(tt.value); got != tt.want {
+			t.Errorf("Status(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
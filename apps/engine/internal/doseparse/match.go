@@ -0,0 +1,174 @@
+package doseparse
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+)
+
+const (
+	// Queries scoring below this against a supplement are not considered to name it
+	minMatchScore = 30
+	// A best match this far ahead of the runner-up is taken to be unambiguous
+	ambiguityMargin = 10
+	// Token similarity (1 - edit distance / length) below which a typo is not forgiven
+	minTokenSimilarity = 0.75
+)
+
+// Candidate is a supplement a query may refer to, scored from 0 to 100.
+type Candidate struct {
+	Supplement models.SupplementInfo
+	Score      float64
+}
+
+// RankSupplements scores each supplement against the query and returns the matches,
+// best first. Ties go to the shorter name, so "mag" ranks "Magnesium Oxide" ahead of
+// "Magnesium L-Threonate".
+//
+// Scores, from strongest to weakest:
+//   - 100: the name, ignoring case and punctuation ("l theanine" for "L-Theanine")
+//   - 85: the start of the name ("vitamin d" for "Vitamin D3")
+//   - 75: a prefix of a distinct word of the name for every query word ("mag" or "k2")
+//   - 65: as above, also using the words of the form
+//   - up to 60: the query words matched with typos ("magnesim glycinate")
+func RankSupplements(query string, supplements []models.SupplementInfo) []Candidate {
+	queryTokens := tokenize(query)
+	if len(queryTokens) == 0 {
+		return nil
+	}
+
+	var candidates []Candidate
+	for _, s := range supplements {
+		if score := matchScore(queryTokens, s); score >= minMatchScore {
+			candidates = append(candidates, Candidate{Supplement: s, Score: score})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if len(a.Supplement.Name) != len(b.Supplement.Name) {
+			return len(a.Supplement.Name) < len(b.Supplement.Name)
+		}
+		return a.Supplement.Name < b.Supplement.Name
+	})
+
+	return candidates
+}
+
+// Resolve returns the supplement the ranked candidates refer to, or false when there
+// are none or the best is not clearly ahead of the runner-up.
+func Resolve(candidates []Candidate) (models.SupplementInfo, bool) {
+	if len(candidates) == 0 {
+		return models.SupplementInfo{}, false
+	}
+	if len(candidates) > 1 && candidates[0].Score-candidates[1].Score < ambiguityMargin {
+		return models.SupplementInfo{}, false
+	}
+	return candidates[0].Supplement, true
+}
+
+func matchScore(queryTokens []string, s models.SupplementInfo) float64 {
+	nameTokens := tokenize(s.Name)
+	query := strings.Join(queryTokens, " ")
+	name := strings.Join(nameTokens, " ")
+
+	switch {
+	case query == name || strings.Join(queryTokens, "") == strings.Join(nameTokens, ""):
+		return 100
+	case strings.HasPrefix(name, query):
+		return 85
+	case prefixesOf(queryTokens, nameTokens):
+		return 75
+	}
+
+	allTokens := nameTokens
+	if s.Form != nil {
+		allTokens = append(allTokens, tokenize(*s.Form)...)
+	}
+	if prefixesOf(queryTokens, allTokens) {
+		return 65
+	}
+
+	return 60 * fuzzyCoverage(queryTokens, allTokens)
+}
+
+// prefixesOf reports whether each query token is a prefix of a different word.
+func prefixesOf(queryTokens []string, words []string) bool {
+	used := make([]bool, len(words))
+	for _, q := range queryTokens {
+		found := false
+		for i, w := range words {
+			if !used[i] && strings.HasPrefix(w, q) {
+				used[i], found = true, true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// fuzzyCoverage returns the mean similarity of each query token to its closest word,
+// counting tokens below minTokenSimilarity as unmatched.
+func fuzzyCoverage(queryTokens []string, words []string) float64 {
+	total := 0.0
+	for _, q := range queryTokens {
+		best := 0.0
+		for _, w := range words {
+			if similarity := tokenSimilarity(q, w); similarity > best {
+				best = similarity
+			}
+		}
+		if best >= minTokenSimilarity {
+			total += best
+		}
+	}
+	return total / float64(len(queryTokens))
+}
+
+// tokenSimilarity compares a query token with a word, or with the word's start of the
+// same length so that abbreviations with typos ("magne" for "magnesium") still match.
+func tokenSimilarity(q string, w string) float64 {
+	a, b := []rune(q), []rune(w)
+	similarity := 1 - float64(levenshtein(a, b))/float64(max(len(a), len(b)))
+	if len(b) > len(a) && len(a) >= 4 {
+		prefix := 1 - float64(levenshtein(a, b[:len(a)]))/float64(len(a))
+		similarity = max(similarity, prefix)
+	}
+	return similarity
+}
+
+func levenshtein(a []rune, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// tokenize lowercases s and splits it into letter and digit runs, so "L-Theanine" and
+// "l theanine" both give ["l", "theanine"].
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
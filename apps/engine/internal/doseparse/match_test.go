@@ -0,0 +1,116 @@
+package doseparse
+
+import (
+	"testing"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+)
+
+func testSupplements() []models.SupplementInfo {
+	form := func(s string) *string { return &s }
+	return []models.SupplementInfo{
+		{ID: "mg-gly", Name: "Magnesium Glycinate", Form: form("Magnesium Bisglycinate")},
+		{ID: "mg-cit", Name: "Magnesium Citrate"},
+		{ID: "mg-thr", Name: "Magnesium L-Threonate"},
+		{ID: "zn-pic", Name: "Zinc Picolinate"},
+		{ID: "zn-glu", Name: "Zinc Gluconate"},
+		{ID: "d3", Name: "Vitamin D3", Form: form("Cholecalciferol")},
+		{ID: "d2", Name: "Vitamin D2", Form: form("Ergocalciferol")},
+		{ID: "b12", Name: "Vitamin B12", Form: form("Methylcobalamin")},
+		{ID: "theanine", Name: "L-Theanine"},
+		{ID: "glycine", Name: "Glycine"},
+		{ID: "omega3", Name: "Omega-3 Fish Oil"},
+	}
+}
+
+func TestRankSupplements_Resolved(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"magnesium glycinate", "mg-gly"},
+		{"Magnesium  GLYCINATE", "mg-gly"},
+		{"d3", "d3"},
+		{"vitamin d3", "d3"},
+		{"vit d3", "d3"},
+		{"cholecalciferol", "d3"},
+		{"ltheanine", "theanine"},
+		{"l theanine", "theanine"},
+		{"theanine", "theanine"},
+		{"omega 3", "omega3"},
+		{"magnesim glycinate", "mg-gly"},
+		{"vitamn d3", "d3"},
+		{"methylcobalamine", "b12"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			candidates := RankSupplements(tt.query, testSupplements())
+			got, ok := Resolve(candidates)
+			if !ok {
+				t.Fatalf("Resolve(%q) was ambiguous: %+v", tt.query, candidates)
+			}
+			if got.ID != tt.want {
+				t.Errorf("Resolve(%q) = %s, want %s", tt.query, got.ID, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankSupplements_Ambiguous(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"mag", []string{"mg-cit", "mg-gly", "mg-thr"}},
+		{"zinc", []string{"zn-glu", "zn-pic"}},
+		// Vitamin B12 follows as a partial match
+		{"vitamin d", []string{"d2", "d3", "b12"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			candidates := RankSupplements(tt.query, testSupplements())
+			if _, ok := Resolve(candidates); ok {
+				t.Fatalf("Resolve(%q) should be ambiguous, got %+v", tt.query, candidates)
+			}
+			if len(candidates) < len(tt.want) {
+				t.Fatalf("RankSupplements(%q) = %+v, want %v first", tt.query, candidates, tt.want)
+			}
+			for i, id := range tt.want {
+				if candidates[i].Supplement.ID != id {
+					t.Errorf("candidate %d = %s, want %s", i, candidates[i].Supplement.ID, id)
+				}
+			}
+		})
+	}
+}
+
+func TestRankSupplements_Scores(t *testing.T) {
+	candidates := RankSupplements("magnesium", testSupplements())
+	if len(candidates) != 3 {
+		t.Fatalf("expected the three magnesium forms, got %+v", candidates)
+	}
+	for _, c := range candidates {
+		if !approxEqual(c.Score, 85, 1e-9) {
+			t.Errorf("%s score = %v, want 85 for a name prefix", c.Supplement.Name, c.Score)
+		}
+	}
+
+	// The exact name beats the other forms by more than the ambiguity margin
+	candidates = RankSupplements("magnesium citrate", testSupplements())
+	if candidates[0].Supplement.ID != "mg-cit" || candidates[0].Score != 100 {
+		t.Errorf("expected an exact match for Magnesium Citrate first, got %+v", candidates)
+	}
+}
+
+func TestRankSupplements_NoMatch(t *testing.T) {
+	for _, query := range []string{"", "unobtainium", "xyz"} {
+		if candidates := RankSupplements(query, testSupplements()); len(candidates) != 0 {
+			t.Errorf("RankSupplements(%q) = %+v, want no candidates", query, candidates)
+		}
+		if _, ok := Resolve(RankSupplements(query, testSupplements())); ok {
+			t.Errorf("Resolve(%q) should not resolve", query)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+// Package doseparse turns free-text log commands such as "mag 400mg" or
+// "2 caps zinc at 9pm" into structured dosages, and ranks supplements against the names
+// they mention.
+package doseparse
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+)
+
+// Times this far ahead of now are taken to mean the previous day, e.g. "9pm" typed at 8am.
+const futureTolerance = 5 * time.Minute
+
+// Item is one supplement mentioned in a command.
+type Item struct {
+	Query  string            // Text naming the supplement, lowercased
+	Amount float64           // Zero when no amount was given
+	Unit   models.DosageUnit // Empty when no amount was given
+}
+
+// Command is a parsed log command.
+type Command struct {
+	Items    []Item
+	LoggedAt time.Time // The parsed time, or now when none was given
+	// Matched time expression, e.g. "9pm" or "this morning"; empty when LoggedAt is now
+	TimeDescription string
+	MealContext     models.MealContext // Empty when no meal was mentioned
+}
+
+var (
+	thousandsPattern = regexp.MustCompile(`(\d),(\d{3})\b`)
+
+	clockPattern = regexp.MustCompile(`\b(?:at\s+)?(\d{1,2})(?::(\d{2}))?\s*(am|pm)\b`)
+	// 24-hour times need "at" and minutes so they are not mistaken for amounts
+	clock24Pattern   = regexp.MustCompile(`\bat\s+(\d{1,2}):(\d{2})\b`)
+	hoursAgoPattern  = regexp.MustCompile(`\b(\d+)\s*(?:hours?|hrs?|h)\s+ago\b`)
+	minsAgoPattern   = regexp.MustCompile(`\b(\d+)\s*(?:minutes?|mins?|m)\s+ago\b`)
+	dayPartPattern   = regexp.MustCompile(`\b(?:this\s+|in\s+the\s+)?(morning|afternoon|evening)\b`)
+	lastNightPattern = regexp.MustCompile(`\blast\s+night\b`)
+	yesterdayPattern = regexp.MustCompile(`\byesterday\b`)
+
+	dosagePattern    = regexp.MustCompile(`\b(\d+(?:\.\d+)?)\s*(mg|milligrams?|mcg|micrograms?|µg|ug|g|grams?|iu|ml|milliliters?|drops?|gtts?)\b`)
+	servingPattern   = regexp.MustCompile(`\b(\d+(?:\.\d+)?)\s*(capsules?|caps?|softgels?|gels?|tablets?|tabs?|pills?|scoops?|servings?)\b`)
+	separatorPattern = regexp.MustCompile(`\s*(?:[,;&+]|\band\b)\s*`)
+	fillerPattern    = regexp.MustCompile(`\b(?:took|taking|take|had|log|add|with|at|of|a|an|the|my|before\s+bed)\b`)
+	spacePattern     = regexp.MustCompile(`\s+`)
+)
+
+// dayPartHours are the clock hours assumed for parts of the day.
+var dayPartHours = map[string]int{
+	"morning":   8,
+	"afternoon": 14,
+	"evening":   19,
+}
+
+// mealPatterns map meal phrases to meal contexts, most specific first.
+var mealPatterns = []struct {
+	pattern *regexp.Regexp
+	context models.MealContext
+}{
+	{regexp.MustCompile(`\b(?:on\s+)?(?:an\s+)?empty\s+stomach\b|\bfast(?:ed|ing)\b`), models.MealContextFasted},
+	{regexp.MustCompile(`\bwith\s+(?:a\s+|my\s+)?(?:fatty\s+(?:meal|food|snack)|fats?)\b`), models.MealContextWithFat},
+	{regexp.MustCompile(`\bafter\s+(?:a\s+|my\s+)?(?:breakfast|lunch|dinner|meal|food|eating)\b`), models.MealContextPostMeal},
+	{regexp.MustCompile(`\bwith\s+(?:a\s+|my\s+)?(?:breakfast|lunch|dinner|meal|food|snack)\b`), models.MealContextWithMeal},
+}
+
+// unitAliases maps the spellings accepted after an amount to dosage units.
+var unitAliases = map[string]models.DosageUnit{
+	"mg": models.DosageUnitMg, "milligram": models.DosageUnitMg, "milligrams": models.DosageUnitMg,
+	"mcg": models.DosageUnitMcg, "microgram": models.DosageUnitMcg, "micrograms": models.DosageUnitMcg,
+	"µg": models.DosageUnitMcg, "ug": models.DosageUnitMcg,
+	"g": models.DosageUnitG, "gram": models.DosageUnitG, "grams": models.DosageUnitG,
+	"iu": models.DosageUnitIU,
+	"ml": models.DosageUnitMl, "milliliter": models.DosageUnitMl, "milliliters": models.DosageUnitMl,
+	"drop": models.DosageUnitDrops, "drops": models.DosageUnitDrops,
+	"gtt": models.DosageUnitDrops, "gtts": models.DosageUnitDrops,
+	"capsule": models.DosageUnitCapsule, "capsules": models.DosageUnitCapsule,
+	"cap": models.DosageUnitCapsule, "caps": models.DosageUnitCapsule,
+	"softgel": models.DosageUnitCapsule, "softgels": models.DosageUnitCapsule,
+	"gel": models.DosageUnitCapsule, "gels": models.DosageUnitCapsule,
+	"tablet": models.DosageUnitTablet, "tablets": models.DosageUnitTablet,
+	"tab": models.DosageUnitTablet, "tabs": models.DosageUnitTablet,
+	"pill": models.DosageUnitTablet, "pills": models.DosageUnitTablet,
+	"scoop": models.DosageUnitScoop, "scoops": models.DosageUnitScoop,
+	"serving": models.DosageUnitServing, "servings": models.DosageUnitServing,
+}
+
+// Parse parses a log command typed at now. Clock times are read in now's location; a
+// time more than a few minutes ahead of now is taken to be the previous day's.
+//
+// The command may name several supplements separated by commas, "and" or "+", e.g.
+// "zinc 30mg and copper 2mg after dinner"; the time and meal context apply to all of them.
+// Amounts need a unit, so numbers in names such as "omega 3" are left in the query.
+func Parse(input string, now time.Time) Command {
+	text := strings.ToLower(strings.TrimSpace(input))
+	text = thousandsPattern.ReplaceAllString(text, "$1$2")
+
+	command := Command{LoggedAt: now}
+	text = parseTime(text, now, &command)
+
+	for _, meal := range mealPatterns {
+		if loc := meal.pattern.FindStringIndex(text); loc != nil {
+			command.MealContext = meal.context
+			text = text[:loc[0]] + " " + text[loc[1]:]
+			break
+		}
+	}
+
+	for _, segment := range separatorPattern.Split(text, -1) {
+		if item, ok := parseItem(segment); ok {
+			command.Items = append(command.Items, item)
+		}
+	}
+
+	return command
+}
+
+// parseTime sets the command's time from the first time expression in text and returns
+// text without it.
+func parseTime(text string, now time.Time, command *Command) string {
+	if m := clockPattern.FindStringSubmatchIndex(text); m != nil {
+		hour, _ := strconv.Atoi(text[m[2]:m[3]])
+		minute := 0
+		if m[4] >= 0 {
+			minute, _ = strconv.Atoi(text[m[4]:m[5]])
+		}
+		if hour >= 1 && hour <= 12 && minute < 60 {
+			hour %= 12
+			if text[m[6]:m[7]] == "pm" {
+				hour += 12
+			}
+			return setTime(text, m, atClock(now, hour, minute), command)
+		}
+	}
+
+	if m := clock24Pattern.FindStringSubmatchIndex(text); m != nil {
+		hour, _ := strconv.Atoi(text[m[2]:m[3]])
+		minute, _ := strconv.Atoi(text[m[4]:m[5]])
+		if hour < 24 && minute < 60 {
+			return setTime(text, m, atClock(now, hour, minute), command)
+		}
+	}
+
+	if m := hoursAgoPattern.FindStringSubmatchIndex(text); m != nil {
+		hours, _ := strconv.Atoi(text[m[2]:m[3]])
+		return setTime(text, m, now.Add(-time.Duration(hours)*time.Hour), command)
+	}
+
+	if m := minsAgoPattern.FindStringSubmatchIndex(text); m != nil {
+		minutes, _ := strconv.Atoi(text[m[2]:m[3]])
+		return setTime(text, m, now.Add(-time.Duration(minutes)*time.Minute), command)
+	}
+
+	if m := lastNightPattern.FindStringIndex(text); m != nil {
+		yesterday := now.AddDate(0, 0, -1)
+		at := time.Date(yesterday.Year(), yesterday.Month(), yesterday.Day(), 22, 0, 0, 0, now.Location())
+		return setTime(text, m, at, command)
+	}
+
+	if m := dayPartPattern.FindStringSubmatchIndex(text); m != nil {
+		hour := dayPartHours[text[m[2]:m[3]]]
+		return setTime(text, m, atClock(now, hour, 0), command)
+	}
+
+	if m := yesterdayPattern.FindStringIndex(text); m != nil {
+		return setTime(text, m, now.AddDate(0, 0, -1), command)
+	}
+
+	return text
+}
+
+// setTime records the time matched at m[0]:m[1] and returns text without the match.
+func setTime(text string, m []int, at time.Time, command *Command) string {
+	command.LoggedAt = at
+	command.TimeDescription = strings.TrimPrefix(text[m[0]:m[1]], "at ")
+	return text[:m[0]] + " " + text[m[1]:]
+}
+
+// atClock returns the most recent occurrence of the wall-clock time, allowing for
+// futureTolerance.
+func atClock(now time.Time, hour int, minute int) time.Time {
+	at := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+	if at.Sub(now) > futureTolerance {
+		at = at.AddDate(0, 0, -1)
+	}
+	return at
+}
+
+// parseItem parses one supplement's amount and name. Segments with neither are skipped.
+func parseItem(segment string) (Item, bool) {
+	var item Item
+	for _, pattern := range []*regexp.Regexp{dosagePattern, servingPattern} {
+		m := pattern.FindStringSubmatchIndex(segment)
+		if m == nil {
+			continue
+		}
+		amount, err := strconv.ParseFloat(segment[m[2]:m[3]], 64)
+		if err != nil {
+			continue
+		}
+		item.Amount = amount
+		item.Unit = unitAliases[segment[m[4]:m[5]]]
+		segment = segment[:m[0]] + " " + segment[m[1]:]
+		break
+	}
+
+	segment = fillerPattern.ReplaceAllString(segment, " ")
+	item.Query = strings.TrimSpace(spacePattern.ReplaceAllString(segment, " "))
+
+	return item, item.Query != "" || item.Unit != ""
+}
@@ -0,0 +1,160 @@
+package doseparse
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+)
+
+func approxEqual(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+var testLocation = time.FixedZone("UTC-5", -5*60*60)
+
+// 2026-03-10 15:30 local
+var testNow = time.Date(2026, 3, 10, 15, 30, 0, 0, testLocation)
+
+func TestParse_Items(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Item
+	}{
+		{"name then dosage", "mag 400mg", []Item{{Query: "mag", Amount: 400, Unit: models.DosageUnitMg}}},
+		{"dosage then name", "5000 IU d3", []Item{{Query: "d3", Amount: 5000, Unit: models.DosageUnitIU}}},
+		{"thousands separator", "Vitamin D3 5,000 iu", []Item{{Query: "vitamin d3", Amount: 5000, Unit: models.DosageUnitIU}}},
+		{"spelled unit", "took 2 grams of creatine", []Item{{Query: "creatine", Amount: 2, Unit: models.DosageUnitG}}},
+		{"decimal", "melatonin 0.5 mg", []Item{{Query: "melatonin", Amount: 0.5, Unit: models.DosageUnitMg}}},
+		{"inexact decimal", "selenium 0.1mg", []Item{{Query: "selenium", Amount: 0.1, Unit: models.DosageUnitMg}}},
+		{"micrograms", "b12 1000µg", []Item{{Query: "b12", Amount: 1000, Unit: models.DosageUnitMcg}}},
+		{"drops", "4 drops d3", []Item{{Query: "d3", Amount: 4, Unit: models.DosageUnitDrops}}},
+		{"capsules", "2 caps zinc", []Item{{Query: "zinc", Amount: 2, Unit: models.DosageUnitCapsule}}},
+		{"softgel", "1 softgel fish oil", []Item{{Query: "fish oil", Amount: 1, Unit: models.DosageUnitCapsule}}},
+		{"scoop", "creatine 1 scoop", []Item{{Query: "creatine", Amount: 1, Unit: models.DosageUnitScoop}}},
+		{"number in name", "omega-3 1000mg", []Item{{Query: "omega-3", Amount: 1000, Unit: models.DosageUnitMg}}},
+		{"no amount", "ashwagandha", []Item{{Query: "ashwagandha"}}},
+		{
+			"several supplements",
+			"zinc 30mg, copper 2mg and 5000 iu d3",
+			[]Item{
+				{Query: "zinc", Amount: 30, Unit: models.DosageUnitMg},
+				{Query: "copper", Amount: 2, Unit: models.DosageUnitMg},
+				{Query: "d3", Amount: 5000, Unit: models.DosageUnitIU},
+			},
+		},
+		{"empty", "   ", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.input, testNow).Items
+			if len(got) != len(tt.want) {
+				t.Fatalf("Parse(%q) items = %+v, want %+v", tt.input, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Parse(%q) item %d = %+v, want %+v", tt.input, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParse_Time(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 3, day, hour, minute, 0, 0, testLocation)
+	}
+
+	tests := []struct {
+		name        string
+		input       string
+		want        time.Time
+		description string
+	}{
+		{"no time", "mag 400mg", testNow, ""},
+		{"clock time today", "2 caps zinc at 9am", at(10, 9, 0), "9am"},
+		{"clock time with minutes", "zinc 30mg 10:30 am", at(10, 10, 30), "10:30 am"},
+		{"noon", "zinc 30mg at 12pm", at(10, 12, 0), "12pm"},
+		{"midnight", "zinc 30mg at 12am", at(10, 0, 0), "12am"},
+		{"future clock time is yesterday", "2 caps zinc at 9pm", at(9, 21, 0), "9pm"},
+		{"within future tolerance", "zinc 30mg at 3:33pm", at(10, 15, 33), "3:33pm"},
+		{"24-hour clock", "zinc 30mg at 07:15", at(10, 7, 15), "07:15"},
+		{"hours ago", "mag 400mg 2 hours ago", at(10, 13, 30), "2 hours ago"},
+		{"minutes ago", "mag 400mg 45 min ago", at(10, 14, 45), "45 min ago"},
+		{"this morning", "d3 5000iu this morning", at(10, 8, 0), "this morning"},
+		{"evening not reached yet", "mag 400mg in the evening", at(9, 19, 0), "in the evening"},
+		{"last night", "melatonin 0.5mg last night", at(9, 22, 0), "last night"},
+		{"yesterday", "creatine 5g yesterday", at(9, 15, 30), "yesterday"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.input, testNow)
+			if !got.LoggedAt.Equal(tt.want) {
+				t.Errorf("Parse(%q) LoggedAt = %v, want %v", tt.input, got.LoggedAt, tt.want)
+			}
+			if got.TimeDescription != tt.description {
+				t.Errorf("Parse(%q) TimeDescription = %q, want %q", tt.input, got.TimeDescription, tt.description)
+			}
+			if len(got.Items) != 1 || got.Items[0].Unit == "" {
+				t.Errorf("Parse(%q) items = %+v, want one dosage without the time", tt.input, got.Items)
+			}
+		})
+	}
+}
+
+func TestParse_LastNightAfterMidnight(t *testing.T) {
+	now := time.Date(2026, 3, 10, 1, 0, 0, 0, testLocation)
+	got := Parse("melatonin 1mg last night", now)
+	if want := time.Date(2026, 3, 9, 22, 0, 0, 0, testLocation); !got.LoggedAt.Equal(want) {
+		t.Errorf("LoggedAt = %v, want %v", got.LoggedAt, want)
+	}
+}
+
+func TestParse_MealContext(t *testing.T) {
+	tests := []struct {
+		input string
+		want  models.MealContext
+		query string
+	}{
+		{"5000 IU d3 with breakfast", models.MealContextWithMeal, "d3"},
+		{"fish oil 2g with a fatty meal", models.MealContextWithFat, "fish oil"},
+		{"magnesium 400mg after dinner", models.MealContextPostMeal, "magnesium"},
+		{"iron 18mg on an empty stomach", models.MealContextFasted, "iron"},
+		{"l-tyrosine 500mg fasted", models.MealContextFasted, "l-tyrosine"},
+		{"zinc 30mg", "", "zinc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := Parse(tt.input, testNow)
+			if got.MealContext != tt.want {
+				t.Errorf("Parse(%q) MealContext = %q, want %q", tt.input, got.MealContext, tt.want)
+			}
+			if len(got.Items) != 1 || got.Items[0].Query != tt.query {
+				t.Errorf("Parse(%q) items = %+v, want query %q", tt.input, got.Items, tt.query)
+			}
+		})
+	}
+}
+
+func TestParse_CombinedCommand(t *testing.T) {
+	got := Parse("2 caps zinc and 1 tab copper at 9pm with dinner", testNow)
+
+	if want := time.Date(2026, 3, 9, 21, 0, 0, 0, testLocation); !got.LoggedAt.Equal(want) {
+		t.Errorf("LoggedAt = %v, want %v", got.LoggedAt, want)
+	}
+	if got.MealContext != models.MealContextWithMeal {
+		t.Errorf("MealContext = %q, want with_meal", got.MealContext)
+	}
+	want := []Item{
+		{Query: "zinc", Amount: 2, Unit: models.DosageUnitCapsule},
+		{Query: "copper", Amount: 1, Unit: models.DosageUnitTablet},
+	}
+	if len(got.Items) != len(want) || got.Items[0] != want[0] || got.Items[1] != want[1] {
+		t.Errorf("Items = %+v, want %+v", got.Items, want)
+	}
+}
@@ -0,0 +1,293 @@
+// Package formula parses chemical formulas and computes molar masses and elemental mass
+// fractions from standard atomic weights, so that the elemental weight of a supplement
+// form can be derived instead of typed in.
+package formula
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrSyntax         = errors.New("formula: invalid syntax")
+	ErrUnknownElement = errors.New("formula: unknown element")
+	ErrElementAbsent  = errors.New("formula: element does not occur in the formula")
+)
+
+// Formula is the number of atoms of each element in one formula unit. Counts are
+// fractional for hemihydrates and similar, e.g. "CaSO4·0.5H2O".
+type Formula map[string]float64
+
+// Parse parses a formula such as "MgO", "Mg(C2H4NO2)2", "[Cr(C6H4NO2)3]" or the hydrate
+// "C12H22O14Zn·3H2O". Parts of adducts and hydrates are separated by "·", "•", "." or "*"
+// and may start with a coefficient. Groups in parentheses or brackets nest and may be
+// followed by a count. Whitespace is ignored and subscript digits are accepted.
+func Parse(s string) (Formula, error) {
+	p := parser{input: []rune(normalize(s))}
+	if len(p.input) == 0 {
+		return nil, fmt.Errorf("%w: empty formula", ErrSyntax)
+	}
+
+	counts := make(Formula)
+	for {
+		coefficient, err := p.number(true)
+		if err != nil {
+			return nil, err
+		}
+
+		start := p.pos
+		part, err := p.sequence(0)
+		if err != nil {
+			return nil, err
+		}
+		if len(part) == 0 {
+			return nil, p.unexpected(start)
+		}
+		counts.add(part, coefficient)
+
+		if p.done() {
+			return counts, nil
+		}
+		if !isSeparator(p.input[p.pos]) {
+			return nil, p.unexpected(p.pos)
+		}
+		p.pos++
+	}
+}
+
+// MolarMass returns the mass of one mole of the formula in g/mol.
+func (f Formula) MolarMass() float64 {
+	total := 0.0
+	for element, count := range f {
+		total += AtomicWeights[element] * count
+	}
+	return total
+}
+
+// MassFraction returns the fraction of the formula's mass contributed by the element.
+func (f Formula) MassFraction(element string) (float64, error) {
+	count, ok := f[element]
+	if !ok {
+		if _, known := AtomicWeights[element]; !known {
+			return 0, fmt.Errorf("%w: %q", ErrUnknownElement, element)
+		}
+		return 0, fmt.Errorf("%w: %s", ErrElementAbsent, element)
+	}
+	return AtomicWeights[element] * count / f.MolarMass(), nil
+}
+
+// Elements returns the formula's elements in Hill order: carbon, then hydrogen, then the
+// rest alphabetically; without carbon, all alphabetically.
+func (f Formula) Elements() []string {
+	elements := make([]string, 0, len(f))
+	for element := range f {
+		elements = append(elements, element)
+	}
+
+	_, hasCarbon := f["C"]
+	rank := func(element string) int {
+		switch {
+		case hasCarbon && element == "C":
+			return 0
+		case hasCarbon && element == "H":
+			return 1
+		default:
+			return 2
+		}
+	}
+	sort.Slice(elements, func(i, j int) bool {
+		if ri, rj := rank(elements[i]), rank(elements[j]); ri != rj {
+			return ri < rj
+		}
+		return elements[i] < elements[j]
+	})
+	return elements
+}
+
+// String returns the formula in Hill notation, e.g. "C12H28O17Zn" for zinc gluconate
+// trihydrate.
+func (f Formula) String() string {
+	var b strings.Builder
+	for _, element := range f.Elements() {
+		b.WriteString(element)
+		if count := f[element]; count != 1 {
+			b.WriteString(strconv.FormatFloat(count, 'g', -1, 64))
+		}
+	}
+	return b.String()
+}
+
+// ElementalWeightPercent parses the formula and returns the element's mass percentage,
+// e.g. 14.1 for magnesium in "Mg(C2H4NO2)2".
+func ElementalWeightPercent(formula string, element string) (float64, error) {
+	f, err := Parse(formula)
+	if err != nil {
+		return 0, err
+	}
+	fraction, err := f.MassFraction(element)
+	if err != nil {
+		return 0, err
+	}
+	return fraction * 100, nil
+}
+
+// ElementalWeightCheck compares a declared elemental weight with the formula's.
+type ElementalWeightCheck struct {
+	DeclaredPercent float64
+	ComputedPercent float64
+	// Declared minus computed, in percentage points
+	Difference float64
+}
+
+// Valid reports whether the declared percentage is within tolerance percentage points of
+// the computed one.
+func (c ElementalWeightCheck) Valid(tolerance float64) bool {
+	return math.Abs(c.Difference) <= tolerance
+}
+
+// CheckElementalWeight computes the element's mass percentage in the formula and compares
+// it with the declared one.
+func CheckElementalWeight(formula string, element string, declaredPercent float64) (ElementalWeightCheck, error) {
+	computed, err := ElementalWeightPercent(formula, element)
+	if err != nil {
+		return ElementalWeightCheck{}, err
+	}
+	return ElementalWeightCheck{
+		DeclaredPercent: declaredPercent,
+		ComputedPercent: computed,
+		Difference:      declaredPercent - computed,
+	}, nil
+}
+
+func (f Formula) add(other Formula, multiplier float64) {
+	for element, count := range other {
+		f[element] += count * multiplier
+	}
+}
+
+type parser struct {
+	input []rune
+	pos   int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) unexpected(pos int) error {
+	if pos >= len(p.input) {
+		return fmt.Errorf("%w: unexpected end of formula", ErrSyntax)
+	}
+	return fmt.Errorf("%w: unexpected %q at position %d", ErrSyntax, p.input[pos], pos+1)
+}
+
+// sequence parses elements and groups up to a closing bracket, a separator or the end.
+func (p *parser) sequence(depth int) (Formula, error) {
+	counts := make(Formula)
+	for !p.done() {
+		r := p.input[p.pos]
+		switch {
+		case r == '(' || r == '[':
+			open := p.pos
+			p.pos++
+			inner, err := p.sequence(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			if len(inner) == 0 || p.done() || p.input[p.pos] != closing(r) {
+				return nil, p.unexpected(max(p.pos, open+1))
+			}
+			p.pos++
+			count, err := p.number(false)
+			if err != nil {
+				return nil, err
+			}
+			counts.add(inner, count)
+
+		case unicode.IsUpper(r):
+			symbol := string(r)
+			p.pos++
+			if !p.done() && unicode.IsLower(p.input[p.pos]) {
+				symbol += string(p.input[p.pos])
+				p.pos++
+			}
+			if _, ok := AtomicWeights[symbol]; !ok {
+				return nil, fmt.Errorf("%w: %q", ErrUnknownElement, symbol)
+			}
+			count, err := p.number(false)
+			if err != nil {
+				return nil, err
+			}
+			counts[symbol] += count
+
+		case r == ')' || r == ']':
+			if depth == 0 {
+				return nil, p.unexpected(p.pos)
+			}
+			return counts, nil
+
+		default:
+			return counts, nil
+		}
+	}
+	return counts, nil
+}
+
+// number parses an optional count, returning 1 when there is none. Counts after elements
+// and groups are integers so that "CuSO4.5H2O" reads as a pentahydrate; coefficients
+// before a part may be decimal.
+func (p *parser) number(decimal bool) (float64, error) {
+	start := p.pos
+	for !p.done() && isDigit(p.input[p.pos]) {
+		p.pos++
+	}
+	if decimal && p.pos > start && p.pos+1 < len(p.input) && p.input[p.pos] == '.' && isDigit(p.input[p.pos+1]) {
+		p.pos++
+		for !p.done() && isDigit(p.input[p.pos]) {
+			p.pos++
+		}
+	}
+	if p.pos == start {
+		return 1, nil
+	}
+
+	value, err := strconv.ParseFloat(string(p.input[start:p.pos]), 64)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("%w: invalid count %q at position %d", ErrSyntax, string(p.input[start:p.pos]), start+1)
+	}
+	return value, nil
+}
+
+func closing(open rune) rune {
+	if open == '[' {
+		return ']'
+	}
+	return ')'
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isSeparator(r rune) bool {
+	return r == '·' || r == '•' || r == '.' || r == '*' || r == '⋅'
+}
+
+// normalize drops whitespace and replaces subscript digits with ASCII ones.
+func normalize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsSpace(r):
+			return -1
+		case r >= '₀' && r <= '₉':
+			return '0' + (r - '₀')
+		default:
+			return r
+		}
+	}, s)
+}
@@ -0,0 +1,161 @@
+package formula
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func approxEqual(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		formula string
+		want    string // Hill notation
+	}{
+		{"oxide", "MgO", "MgO"},
+		{"chelate", "Mg(C2H4NO2)2", "C4H8MgN2O4"},
+		{"nested groups", "[Cr(C6H4NO2)3]", "C18H12CrN3O6"},
+		{"hydrate", "C12H22O14Zn·3H2O", "C12H28O17Zn"},
+		{"dot hydrate", "CuSO4.5H2O", "CuH10O9S"},
+		{"asterisk hydrate", "Na2MoO4*2H2O", "H4MoNa2O6"},
+		{"hemihydrate", "CaSO4·0.5H2O", "CaHO4.5S"},
+		{"whitespace and subscripts", "Ca₃ (C₆H₅O₇)₂", "C12H10Ca3O14"},
+		{"repeated element", "CH3COOH", "C2H4O2"},
+		{"two-letter symbols", "NaCl", "ClNa"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Parse(tt.formula)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.formula, err)
+			}
+			if got := f.String(); got != tt.want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.formula, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		formula string
+		want    error
+	}{
+		{"", ErrSyntax},
+		{"   ", ErrSyntax},
+		{"Mg(C2H4NO2", ErrSyntax},
+		{"MgC2H4NO2)2", ErrSyntax},
+		{"Mg(C2H4NO2]2", ErrSyntax},
+		{"Mg()2", ErrSyntax},
+		{"mgO", ErrSyntax},
+		{"MgO·", ErrSyntax},
+		{"·H2O", ErrSyntax},
+		{"Mg0", ErrSyntax},
+		{"Fe2+", ErrSyntax},
+		{"Xx2O", ErrUnknownElement},
+		{"Tc", ErrUnknownElement},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.formula, func(t *testing.T) {
+			if _, err := Parse(tt.formula); !errors.Is(err, tt.want) {
+				t.Errorf("Parse(%q) error = %v, want %v", tt.formula, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMolarMass(t *testing.T) {
+	tests := []struct {
+		formula string
+		want    float64
+	}{
+		{"H2O", 18.015},
+		{"MgO", 40.304},
+		{"Mg(C2H4NO2)2", 172.42},
+		{"C12H22O14Zn", 455.68},
+		{"C12H22O14Zn·3H2O", 509.72},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.formula, func(t *testing.T) {
+			f, err := Parse(tt.formula)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.formula, err)
+			}
+			if got := f.MolarMass(); !approxEqual(got, tt.want, 0.01) {
+				t.Errorf("MolarMass(%q) = %v, want %v", tt.formula, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestElementalWeightPercent(t *testing.T) {
+	tests := []struct {
+		name    string
+		formula string
+		element string
+		want    float64
+	}{
+		{"magnesium bisglycinate", "Mg(C2H4NO2)2", "Mg", 14.10},
+		{"magnesium oxide", "MgO", "Mg", 60.30},
+		{"magnesium citrate", "Mg3(C6H5O7)2", "Mg", 16.16},
+		{"zinc picolinate", "Zn(C6H4NO2)2", "Zn", 21.12},
+		{"zinc gluconate", "C12H22O14Zn", "Zn", 14.35},
+		{"zinc gluconate trihydrate", "C12H22O14Zn·3H2O", "Zn", 12.83},
+		{"selenomethionine", "C5H11NO2Se", "Se", 40.27},
+		{"potassium iodide", "KI", "I", 76.45},
+		{"sodium molybdate dihydrate", "Na2MoO4·2H2O", "Mo", 39.66},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ElementalWeightPercent(tt.formula, tt.element)
+			if err != nil {
+				t.Fatalf("ElementalWeightPercent(%q, %s) error = %v", tt.formula, tt.element, err)
+			}
+			if !approxEqual(got, tt.want, 0.01) {
+				t.Errorf("ElementalWeightPercent(%q, %s) = %.3f, want %.2f", tt.formula, tt.element, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestElementalWeightPercent_Errors(t *testing.T) {
+	if _, err := ElementalWeightPercent("MgO", "Zn"); !errors.Is(err, ErrElementAbsent) {
+		t.Errorf("expected ErrElementAbsent, got %v", err)
+	}
+	if _, err := ElementalWeightPercent("MgO", "Zz"); !errors.Is(err, ErrUnknownElement) {
+		t.Errorf("expected ErrUnknownElement, got %v", err)
+	}
+	if _, err := ElementalWeightPercent("Mg(", "Mg"); !errors.Is(err, ErrSyntax) {
+		t.Errorf("expected ErrSyntax, got %v", err)
+	}
+}
+
+func TestCheckElementalWeight(t *testing.T) {
+	// Zinc gluconate is sold anhydrous; 14.3% matches it but not the trihydrate
+	check, err := CheckElementalWeight("C12H22O14Zn", "Zn", 14.3)
+	if err != nil {
+		t.Fatalf("CheckElementalWeight() error = %v", err)
+	}
+	if !check.Valid(0.5) {
+		t.Errorf("expected 14.3%% to be valid for anhydrous zinc gluconate, got %+v", check)
+	}
+
+	check, err = CheckElementalWeight("C12H22O14Zn·3H2O", "Zn", 14.3)
+	if err != nil {
+		t.Fatalf("CheckElementalWeight() error = %v", err)
+	}
+	if check.Valid(0.5) {
+		t.Errorf("expected 14.3%% to be invalid for the trihydrate, got %+v", check)
+	}
+	if !approxEqual(check.Difference, 14.3-12.83, 0.01) {
+		t.Errorf("Difference = %v, want %v", check.Difference, 14.3-12.83)
+	}
+}
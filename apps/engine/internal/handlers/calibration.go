@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/auth"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/calibration"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+)
+
+// Earlier blood results within this window refine the calibration fit
+const calibrationHistory = 365 * 24 * time.Hour
+
+// Calibrate handles the calibration endpoint. It fits personal PK factors for a new blood
+// result; saving the result (and with it the calibration) is left to the caller.
+func (h *Handler) Calibrate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.CalibrateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	userID, ok := auth.GetUserID(ctx)
+	if !ok || userID == "" {
+		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	biomarkerType := calibration.BiomarkerType(req.BiomarkerType)
+	biomarker, ok := calibration.Biomarkers[biomarkerType]
+	if !ok {
+		http.Error(w, `{"error":"invalid biomarkerType"}`, http.StatusBadRequest)
+		return
+	}
+	if req.Value <= 0 {
+		http.Error(w, `{"error":"value must be positive"}`, http.StatusBadRequest)
+		return
+	}
+	if req.Unit != "" && !strings.EqualFold(req.Unit, biomarker.Unit) {
+		http.Error(w, `{"error":"unit must be `+biomarker.Unit+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	measuredAt := time.Now()
+	if req.MeasuredAt != nil {
+		measuredAt = *req.MeasuredAt
+	}
+
+	history, err := h.getBiomarkerHistory(ctx, userID, biomarkerType, biomarker.Unit, measuredAt.Add(-calibrationHistory), measuredAt)
+	if err != nil {
+		http.Error(w, `{"error":"calibration failed"}`, http.StatusInternalServerError)
+		return
+	}
+	measurements := append(history, calibration.Measurement{Value: float64(req.Value), MeasuredAt: measuredAt})
+
+	lookback := time.Duration(biomarker.SteadyStateDays) * 24 * time.Hour
+	intake, err := h.getBiomarkerIntake(ctx, userID, biomarker, measurements[0].MeasuredAt.Add(-lookback), measuredAt)
+	if err != nil {
+		http.Error(w, `{"error":"calibration failed"}`, http.StatusInternalServerError)
+		return
+	}
+
+	response := buildCalibrateResponse(req, biomarker, calibration.Fit(biomarker, measurements, intake))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// buildCalibrateResponse rounds a fit for display, like the web calibration service.
+func buildCalibrateResponse(req models.CalibrateRequest, biomarker calibration.Biomarker, fit calibration.Result) models.CalibrateResponse {
+	response := models.CalibrateResponse{
+		BiomarkerType:              req.BiomarkerType,
+		MeasuredValue:              req.Value,
+		PredictedValue:             RoundToDecimal(fit.PredictedValue, 1),
+		IndividualAbsorptionFactor: RoundToDecimal(fit.IndividualAbsorptionFactor, 2),
+		CalibratedF:                fit.CalibratedF,
+		CalibratedCL:               fit.CalibratedCL,
+		Confidence:                 string(fit.Confidence),
+		DatapointsUsed:             fit.DatapointsUsed,
+		Status:                     biomarker.Status(float64(req.Value)),
+		GoodnessOfFit: models.GoodnessOfFit{
+			Measurements: fit.Fit.Measurements,
+			RMSE:         RoundToDecimal(fit.Fit.RMSE, 2),
+		},
+	}
+	if fit.Fit.RSquared != nil {
+		r2 := RoundToDecimal(*fit.Fit.RSquared, 3)
+		response.GoodnessOfFit.RSquared = &r2
+	}
+	return response
+}
+
+// getBiomarkerHistory returns the user's earlier results for a marker, oldest first.
+// Results recorded in a different unit are skipped.
+func (h *Handler) getBiomarkerHistory(ctx context.Context, userID string, biomarkerType calibration.BiomarkerType, unit string, from time.Time, before time.Time) ([]calibration.Measurement, error) {
+	query := `
+		SELECT value, measured_at
+		FROM user_biomarker
+		WHERE user_id = $1
+		  AND biomarker_type = $2
+		  AND lower(unit) = lower($3)
+		  AND measured_at >= $4
+		  AND measured_at < $5
+		ORDER BY measured_at
+	`
+
+	rows, err := h.pool.Query(ctx, query, userID, string(biomarkerType), unit, from, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var measurements []calibration.Measurement
+	for rows.Next() {
+		var (
+			value      float32
+			measuredAt time.Time
+		)
+		if err := rows.Scan(&value, &measuredAt); err != nil {
+			return nil, err
+		}
+		measurements = append(measurements, calibration.Measurement{Value: float64(value), MeasuredAt: measuredAt})
+	}
+
+	return measurements, rows.Err()
+}
+
+// getBiomarkerIntake returns the user's logged doses of supplements tracked by a marker,
+// matching calibration.Biomarker.Tracks.
+func (h *Handler) getBiomarkerIntake(ctx context.Context, userID string, biomarker calibration.Biomarker, from time.Time, to time.Time) ([]calibration.Intake, error) {
+	query := `
+		SELECT l.dosage, l.logged_at
+		FROM log l
+		JOIN supplement s ON l.supplement_id = s.id
+		WHERE l.user_id = $1
+		  AND l.logged_at >= $2
+		  AND l.logged_at <= $3
+		  AND (lower(s.name) = ANY($4) OR ($5 <> '' AND s.safety_category = $5))
+		ORDER BY l.logged_at
+	`
+
+	names := make([]string, len(biomarker.SupplementNames))
+	for i, name := range biomarker.SupplementNames {
+		names[i] = strings.ToLower(name)
+	}
+
+	rows, err := h.pool.Query(ctx, query, userID, from, to, names, biomarker.SafetyCategory)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var intake []calibration.Intake
+	for rows.Next() {
+		var (
+			dosage   float32
+			loggedAt time.Time
+		)
+		if err := rows.Scan(&dosage, &loggedAt); err != nil {
+			return nil, err
+		}
+		intake = append(intake, calibration.Intake{Dosage: float64(dosage), LoggedAt: loggedAt})
+	}
+
+	return intake, rows.Err()
+}
+
+// getLatestCalibrations returns the user's most recent result for each marker.
+func (h *Handler) getLatestCalibrations(ctx context.Context, userID string) ([]calibration.Record, error) {
+	query := `
+		SELECT DISTINCT ON (biomarker_type) biomarker_type, calibrated_f, calibrated_cl, measured_at
+		FROM user_biomarker
+		WHERE user_id = $1
+		ORDER BY biomarker_type, measured_at DESC
+	`
+
+	rows, err := h.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []calibration.Record
+	for rows.Next() {
+		var (
+			record        calibration.Record
+			biomarkerType string
+		)
+		if err := rows.Scan(&biomarkerType, &record.CalibratedF, &record.CalibratedCL, &record.MeasuredAt); err != nil {
+			return nil, err
+		}
+		record.BiomarkerType = calibration.BiomarkerType(biomarkerType)
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// stringOrEmpty dereferences an optional string.
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/calibration"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+)
+
+func TestBuildCalibrateResponse_RoundsFit(t *testing.T) {
+	f := 0.6123
+	r2 := 0.98765
+	fit := calibration.Result{
+		PredictedValue:             48.96,
+		IndividualAbsorptionFactor: 0.6123,
+		CalibratedF:                &f,
+		Confidence:                 calibration.ConfidenceMedium,
+		DatapointsUsed:             42,
+		Fit:                        calibration.GoodnessOfFit{Measurements: 3, RSquared: &r2, RMSE: 1.2345},
+	}
+	req := models.CalibrateRequest{BiomarkerType: "25_oh_d", Value: 30}
+
+	response := buildCalibrateResponse(req, calibration.Biomarkers[calibration.Biomarker25OHD], fit)
+
+	if response.PredictedValue != 49 || response.IndividualAbsorptionFactor != 0.61 {
+		t.Fatalf("unexpected rounding: predicted %v, IAF %v", response.PredictedValue, response.IndividualAbsorptionFactor)
+	}
+	if response.CalibratedF == nil || *response.CalibratedF != f || response.CalibratedCL != nil {
+		t.Fatalf("unexpected factors: F=%v CL=%v", response.CalibratedF, response.CalibratedCL)
+	}
+	if response.Status != "suboptimal" || response.Confidence != "medium" {
+		t.Fatalf("unexpected status %q / confidence %q", response.Status, response.Confidence)
+	}
+	if response.GoodnessOfFit.RSquared == nil || *response.GoodnessOfFit.RSquared != 0.988 || response.GoodnessOfFit.RMSE != 1.23 {
+		t.Fatalf("unexpected goodness of fit: %+v", response.GoodnessOfFit)
+	}
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/auth"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/kinetics"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/pharmacodynamics"
+)
+
+// supplementEffect is a supplement's concentration-effect model.
+type supplementEffect struct {
+	Label *string
+	Model pharmacodynamics.EmaxModel
+}
+
+// Effect handles the pharmacodynamic effect curve endpoint
+func (h *Handler) Effect(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.EffectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	userID, ok := auth.GetUserID(ctx)
+	if !ok || userID == "" {
+		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	windowStart, windowEnd, interval, ok := resolveTimelineWindow(models.TimelineRequest{
+		WindowStart:     req.WindowStart,
+		WindowEnd:       req.WindowEnd,
+		IntervalMinutes: req.IntervalMinutes,
+	}, time.Now())
+	if !ok {
+		http.Error(w, `{"error":"invalid effect window"}`, http.StatusBadRequest)
+		return
+	}
+
+	if req.BodyWeightKg < 0 {
+		http.Error(w, `{"error":"invalid bodyWeightKg"}`, http.StatusBadRequest)
+		return
+	}
+
+	calibrations, err := h.getLatestCalibrations(ctx, userID)
+	if err != nil {
+		http.Error(w, `{"error":"effect analysis failed"}`, http.StatusInternalServerError)
+		return
+	}
+
+	doses, err := h.getTimelineDoses(ctx, userID, calibrations, windowStart.Add(-timelineCarryOver), windowEnd)
+	if err != nil {
+		http.Error(w, `{"error":"effect analysis failed"}`, http.StatusInternalServerError)
+		return
+	}
+	doses = filterTimelineDoses(doses, req.SupplementIDs)
+
+	effects, err := h.getSupplementEffects(ctx, timelineSupplementIDs(doses))
+	if err != nil {
+		http.Error(w, `{"error":"effect analysis failed"}`, http.StatusInternalServerError)
+		return
+	}
+
+	curves, unavailable := buildEffectCurves(doses, effects, windowStart, windowEnd, interval, req.BodyWeightKg)
+	response := models.EffectResponse{
+		WindowStart:     windowStart,
+		WindowEnd:       windowEnd,
+		IntervalMinutes: int(interval / time.Minute),
+		Curves:          curves,
+		Unavailable:     unavailable,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// getSupplementEffects loads the Emax models of the given supplements, keyed by ID.
+// Supplements without complete pharmacodynamic parameters are omitted.
+func (h *Handler) getSupplementEffects(ctx context.Context, supplementIDs []string) (map[string]supplementEffect, error) {
+	effects := make(map[string]supplementEffect)
+	if len(supplementIDs) == 0 {
+		return effects, nil
+	}
+
+	query := `
+		SELECT id, effect_label, emax, ec50, hill_coefficient
+		FROM supplement
+		WHERE id = ANY($1)
+	`
+
+	rows, err := h.pool.Query(ctx, query, supplementIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id         string
+			label      *string
+			emax, ec50 *float32
+			hill       *float32
+		)
+		if err := rows.Scan(&id, &label, &emax, &ec50, &hill); err != nil {
+			return nil, err
+		}
+		if emax == nil || ec50 == nil {
+			continue
+		}
+
+		model := pharmacodynamics.EmaxModel{Emax: float64(*emax), EC50: float64(*ec50)}
+		if hill != nil {
+			model.HillCoefficient = float64(*hill)
+		}
+		if model.Validate() != nil {
+			continue
+		}
+		effects[id] = supplementEffect{Label: label, Model: model}
+	}
+
+	return effects, rows.Err()
+}
+
+// filterTimelineDoses keeps the doses of the given supplements, or all doses when none are given.
+func filterTimelineDoses(doses []timelineDose, supplementIDs []string) []timelineDose {
+	if len(supplementIDs) == 0 {
+		return doses
+	}
+
+	allowed := make(map[string]struct{}, len(supplementIDs))
+	for _, id := range supplementIDs {
+		allowed[id] = struct{}{}
+	}
+
+	filtered := make([]timelineDose, 0, len(doses))
+	for _, d := range doses {
+		if _, ok := allowed[d.Supplement.ID]; ok {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered
+}
+
+// timelineSupplementIDs returns the distinct supplement IDs of the doses.
+func timelineSupplementIDs(doses []timelineDose) []string {
+	seen := make(map[string]struct{})
+	ids := make([]string, 0)
+	for _, d := range doses {
+		if _, ok := seen[d.Supplement.ID]; !ok {
+			seen[d.Supplement.ID] = struct{}{}
+			ids = append(ids, d.Supplement.ID)
+		}
+	}
+	return ids
+}
+
+// buildEffectCurves samples the predicted effect of each supplement across the window by
+// passing its combined mg/L concentration through the supplement's Emax model.
+// Supplements without an Emax model or a volume of distribution are reported as unavailable.
+func buildEffectCurves(doses []timelineDose, effects map[string]supplementEffect, windowStart time.Time, windowEnd time.Time, interval time.Duration, bodyWeightKg float64) ([]models.EffectCurve, []models.EffectGap) {
+	order, bySupplement := groupDosesBySupplement(doses, windowStart)
+
+	curves := make([]models.EffectCurve, 0, len(order))
+	var unavailable []models.EffectGap
+	for _, supplementID := range order {
+		entry := bySupplement[supplementID]
+
+		effect, ok := effects[supplementID]
+		if !ok {
+			unavailable = append(unavailable, models.EffectGap{Supplement: entry.info, Reason: models.EffectGapMissingPDParameters})
+			continue
+		}
+
+		curve := models.EffectCurve{
+			Supplement:      entry.info,
+			EffectLabel:     effect.Label,
+			Emax:            effect.Model.Emax,
+			EC50:            effect.Model.EC50,
+			HillCoefficient: effect.Model.HillCoefficient,
+			Samples:         make([]models.EffectSample, 0),
+		}
+
+		timestamps, times := sampleTimes(windowStart, windowEnd, interval)
+		concentrations, err := kinetics.CalculateMultiDosePlasmaConcentrationCurve(entry.events, times, bodyWeightKg)
+		if err != nil {
+			unavailable = append(unavailable, models.EffectGap{Supplement: entry.info, Reason: models.EffectGapMissingVolume})
+			continue
+		}
+
+		for i, concentration := range concentrations {
+			curve.Samples = append(curve.Samples, models.EffectSample{
+				MinutesFromStart:    times[i],
+				Timestamp:           timestamps[i],
+				ConcentrationMgPerL: concentration,
+				Effect:              effect.Model.Effect(concentration),
+				PercentOfMax:        effect.Model.PercentOfMax(concentration),
+			})
+		}
+		curves = append(curves, curve)
+	}
+
+	return curves, unavailable
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/kinetics"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/pharmacodynamics"
+)
+
+func TestBuildEffectCurves(t *testing.T) {
+	windowStart := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	pk := kinetics.SupplementPK{
+		KineticsType:         kinetics.FirstOrder,
+		PeakMinutes:          60,
+		HalfLifeMinutes:      300,
+		VolumeOfDistribution: 0.6,
+	}
+	caffeine := models.SupplementInfo{ID: "supp-caffeine", Name: "Caffeine"}
+	theanine := models.SupplementInfo{ID: "supp-theanine", Name: "L-Theanine"}
+	zinc := models.SupplementInfo{ID: "supp-zinc", Name: "Zinc"}
+
+	doses := []timelineDose{
+		{Supplement: caffeine, LoggedAt: windowStart, DoseMg: 100, PK: pk},
+		{Supplement: theanine, LoggedAt: windowStart, DoseMg: 200, PK: kinetics.SupplementPK{PeakMinutes: 60}},
+		{Supplement: zinc, LoggedAt: windowStart, DoseMg: 15, PK: pk},
+	}
+	label := "alertness"
+	effects := map[string]supplementEffect{
+		"supp-caffeine": {Label: &label, Model: pharmacodynamics.EmaxModel{Emax: 10, EC50: 2, HillCoefficient: 1.5}},
+		"supp-theanine": {Model: pharmacodynamics.EmaxModel{Emax: 5, EC50: 1}},
+	}
+
+	curves, unavailable := buildEffectCurves(doses, effects, windowStart, windowStart.Add(2*time.Hour), time.Hour, 70)
+
+	if len(curves) != 1 || curves[0].Supplement.ID != "supp-caffeine" {
+		t.Fatalf("expected only a caffeine curve, got %+v", curves)
+	}
+	if len(unavailable) != 2 {
+		t.Fatalf("expected 2 unavailable supplements, got %d", len(unavailable))
+	}
+	if unavailable[0].Supplement.ID != "supp-theanine" || unavailable[0].Reason != models.EffectGapMissingVolume {
+		t.Fatalf("expected theanine to lack a volume of distribution, got %+v", unavailable[0])
+	}
+	if unavailable[1].Supplement.ID != "supp-zinc" || unavailable[1].Reason != models.EffectGapMissingPDParameters {
+		t.Fatalf("expected zinc to lack PD parameters, got %+v", unavailable[1])
+	}
+
+	samples := curves[0].Samples
+	if len(samples) != 3 {
+		t.Fatalf("expected 3 samples, got %d", len(samples))
+	}
+	if samples[0].Effect != 0 {
+		t.Fatalf("expected no effect at ingestion, got %v", samples[0].Effect)
+	}
+
+	peak := samples[1]
+	expected, _ := kinetics.PeakPlasmaConcentration(kinetics.ConcentrationParams{Dose: 100, PK: pk, BodyWeightKg: 70})
+	if !almostEqual(peak.ConcentrationMgPerL, expected, 0.0001) {
+		t.Fatalf("expected %v mg/L at the peak, got %v", expected, peak.ConcentrationMgPerL)
+	}
+	model := effects["supp-caffeine"].Model
+	if !almostEqual(peak.Effect, model.Effect(expected), 0.0001) {
+		t.Fatalf("expected effect %v at the peak, got %v", model.Effect(expected), peak.Effect)
+	}
+	if !almostEqual(peak.PercentOfMax, 10*peak.Effect, 0.0001) {
+		t.Fatalf("expected percent of max to be effect / Emax, got %v", peak.PercentOfMax)
+	}
+	if curves[0].EffectLabel == nil || *curves[0].EffectLabel != "alertness" {
+		t.Fatalf("expected effect label to be carried through")
+	}
+}
+
+func TestFilterTimelineDoses(t *testing.T) {
+	doses := []timelineDose{
+		{Supplement: models.SupplementInfo{ID: "a"}},
+		{Supplement: models.SupplementInfo{ID: "b"}},
+		{Supplement: models.SupplementInfo{ID: "a"}},
+	}
+
+	if got := filterTimelineDoses(doses, nil); len(got) != 3 {
+		t.Fatalf("expected all doses without a filter, got %d", len(got))
+	}
+	if got := filterTimelineDoses(doses, []string{"a"}); len(got) != 2 {
+		t.Fatalf("expected 2 doses of a, got %d", len(got))
+	}
+	if ids := timelineSupplementIDs(doses); len(ids) != 2 {
+		t.Fatalf("expected 2 distinct supplements, got %v", ids)
+	}
+}
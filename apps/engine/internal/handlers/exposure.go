@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/auth"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/kinetics"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+)
+
+const (
+	defaultExposureThresholdPercent = 50
+	// Upper bound on days per request to keep responses bounded
+	maxExposureDays = 31
+	// Doses logged this long before the first day are considered; CalculateExposureMetrics
+	// skips the ones already cleared
+	exposureLookback = 120 * 24 * time.Hour
+)
+
+const exposureDateLayout = "2006-01-02"
+
+// Exposure handles the daily exposure metrics endpoint
+func (h *Handler) Exposure(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.ExposureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	userID, ok := auth.GetUserID(ctx)
+	if !ok || userID == "" {
+		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	days, loc, ok := resolveExposureDays(req, time.Now())
+	if !ok {
+		http.Error(w, `{"error":"invalid exposure range"}`, http.StatusBadRequest)
+		return
+	}
+
+	threshold := req.ThresholdPercent
+	if threshold == 0 {
+		threshold = defaultExposureThresholdPercent
+	}
+	if threshold < 0 {
+		http.Error(w, `{"error":"invalid thresholdPercent"}`, http.StatusBadRequest)
+		return
+	}
+
+	calibrations, err := h.getLatestCalibrations(ctx, userID)
+	if err != nil {
+		http.Error(w, `{"error":"exposure analysis failed"}`, http.StatusInternalServerError)
+		return
+	}
+
+	rangeEnd := days[len(days)-1].AddDate(0, 0, 1)
+	doses, err := h.getTimelineDoses(ctx, userID, calibrations, days[0].Add(-exposureLookback), rangeEnd)
+	if err != nil {
+		http.Error(w, `{"error":"exposure analysis failed"}`, http.StatusInternalServerError)
+		return
+	}
+
+	response := models.ExposureResponse{
+		TimeZone:         loc.String(),
+		ThresholdPercent: threshold,
+		Days:             buildDailyExposure(filterTimelineDoses(doses, req.SupplementIDs), days, threshold),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// resolveExposureDays returns the local midnight of each requested day and the time zone
+// they are in, applying defaults and validating the range.
+func resolveExposureDays(req models.ExposureRequest, now time.Time) ([]time.Time, *time.Location, bool) {
+	loc := time.UTC
+	if req.TimeZone != "" {
+		var err error
+		if loc, err = time.LoadLocation(req.TimeZone); err != nil {
+			return nil, nil, false
+		}
+	}
+
+	local := now.In(loc)
+	end := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	if req.EndDate != "" {
+		parsed, err := time.ParseInLocation(exposureDateLayout, req.EndDate, loc)
+		if err != nil {
+			return nil, nil, false
+		}
+		end = parsed
+	}
+
+	start := end
+	if req.StartDate != "" {
+		parsed, err := time.ParseInLocation(exposureDateLayout, req.StartDate, loc)
+		if err != nil {
+			return nil, nil, false
+		}
+		start = parsed
+	}
+
+	var days []time.Time
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		if len(days) == maxExposureDays {
+			return nil, nil, false
+		}
+		days = append(days, day)
+	}
+	if len(days) == 0 {
+		return nil, nil, false
+	}
+
+	return days, loc, true
+}
+
+// buildDailyExposure computes the exposure metrics of each supplement on each day, from
+// local midnight to the next. Days follow the calendar, so they are 23 or 25 hours long
+// across DST changes. Supplements with no doses and no exposure on a day are omitted from it.
+func buildDailyExposure(doses []timelineDose, days []time.Time, thresholdPercent float64) []models.DailyExposure {
+	order, bySupplement := groupDosesBySupplement(doses, days[0])
+
+	result := make([]models.DailyExposure, 0, len(days))
+	for _, day := range days {
+		start := day.Sub(days[0]).Minutes()
+		end := day.AddDate(0, 0, 1).Sub(days[0]).Minutes()
+
+		daily := models.DailyExposure{
+			Date:        day.Format(exposureDateLayout),
+			Supplements: make([]models.SupplementExposure, 0),
+		}
+		for _, supplementID := range order {
+			entry := bySupplement[supplementID]
+
+			doseCount := 0
+			for _, event := range entry.events {
+				if event.AtMinutes >= start && event.AtMinutes < end {
+					doseCount++
+				}
+			}
+
+			metrics := kinetics.CalculateExposureMetrics(entry.events, start, end, kinetics.DefaultExposureStepMinutes, thresholdPercent)
+			if doseCount == 0 && metrics.AUC == 0 {
+				continue
+			}
+
+			daily.Supplements = append(daily.Supplements, models.SupplementExposure{
+				Supplement:            entry.info,
+				DoseCount:             doseCount,
+				AUCPercentHours:       RoundToDecimal(metrics.AUC/60, 1),
+				Peak:                  RoundToDecimal(metrics.Cmax, 1),
+				PeakAt:                days[0].Add(time.Duration(metrics.TmaxMinutes * float64(time.Minute))),
+				MinutesAboveThreshold: RoundToDecimal(metrics.MinutesAboveThreshold, 0),
+			})
+		}
+		result = append(result, daily)
+	}
+
+	return result
+}
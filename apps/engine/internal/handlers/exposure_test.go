@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/kinetics"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+)
+
+func TestResolveExposureDays(t *testing.T) {
+	now := time.Date(2025, 3, 10, 2, 0, 0, 0, time.UTC)
+
+	days, loc, ok := resolveExposureDays(models.ExposureRequest{TimeZone: "America/Vancouver"}, now)
+	if !ok || len(days) != 1 {
+		t.Fatalf("expected today by default, got %v %v", days, ok)
+	}
+	if got := days[0].Format(exposureDateLayout); got != "2025-03-09" {
+		t.Fatalf("expected the local date 2025-03-09, got %s", got)
+	}
+	if loc.String() != "America/Vancouver" {
+		t.Fatalf("expected the requested time zone, got %s", loc)
+	}
+
+	days, _, ok = resolveExposureDays(models.ExposureRequest{StartDate: "2025-03-01", EndDate: "2025-03-07"}, now)
+	if !ok || len(days) != 7 {
+		t.Fatalf("expected 7 days, got %d", len(days))
+	}
+
+	invalid := []models.ExposureRequest{
+		{TimeZone: "Mars/Olympus"},
+		{EndDate: "03/07/2025"},
+		{StartDate: "2025-03-08", EndDate: "2025-03-07"},
+		{StartDate: "2025-01-01", EndDate: "2025-03-07"},
+	}
+	for _, req := range invalid {
+		if _, _, ok := resolveExposureDays(req, now); ok {
+			t.Fatalf("expected %+v to be rejected", req)
+		}
+	}
+}
+
+func TestBuildDailyExposure(t *testing.T) {
+	loc, err := time.LoadLocation("America/Vancouver")
+	if err != nil {
+		t.Skip("time zone data unavailable")
+	}
+	// DST starts on 2025-03-09, so that day is 23 hours long
+	days := []time.Time{
+		time.Date(2025, 3, 8, 0, 0, 0, 0, loc),
+		time.Date(2025, 3, 9, 0, 0, 0, 0, loc),
+		time.Date(2025, 3, 10, 0, 0, 0, 0, loc),
+	}
+	caffeine := models.SupplementInfo{ID: "supp-caffeine", Name: "Caffeine"}
+	magnesium := models.SupplementInfo{ID: "supp-magnesium", Name: "Magnesium"}
+	caffeinePK := kinetics.SupplementPK{KineticsType: kinetics.FirstOrder, PeakMinutes: 45, HalfLifeMinutes: 300}
+	magnesiumPK := kinetics.SupplementPK{KineticsType: kinetics.FirstOrder, PeakMinutes: 120, HalfLifeMinutes: 60}
+
+	doses := []timelineDose{
+		{Supplement: caffeine, LoggedAt: time.Date(2025, 3, 7, 23, 0, 0, 0, loc), DoseMg: 100, PK: caffeinePK},
+		{Supplement: caffeine, LoggedAt: time.Date(2025, 3, 9, 8, 0, 0, 0, loc), DoseMg: 100, PK: caffeinePK},
+		{Supplement: caffeine, LoggedAt: time.Date(2025, 3, 9, 13, 0, 0, 0, loc), DoseMg: 100, PK: caffeinePK},
+		{Supplement: magnesium, LoggedAt: time.Date(2025, 3, 9, 9, 0, 0, 0, loc), DoseMg: 200, PK: magnesiumPK},
+	}
+
+	result := buildDailyExposure(doses, days, 50)
+	if len(result) != 3 {
+		t.Fatalf("expected 3 days, got %d", len(result))
+	}
+
+	// The previous evening's caffeine carries into the first day without a dose on it
+	first := result[0]
+	if first.Date != "2025-03-08" || len(first.Supplements) != 1 {
+		t.Fatalf("expected carried-over caffeine only on 2025-03-08, got %+v", first)
+	}
+	if first.Supplements[0].DoseCount != 0 || first.Supplements[0].AUCPercentHours <= 0 {
+		t.Fatalf("expected carried-over exposure without doses, got %+v", first.Supplements[0])
+	}
+
+	second := result[1]
+	if len(second.Supplements) != 2 || second.Supplements[0].Supplement.ID != "supp-caffeine" {
+		t.Fatalf("expected caffeine and magnesium on 2025-03-09, got %+v", second.Supplements)
+	}
+	exposure := second.Supplements[0]
+	if exposure.DoseCount != 2 {
+		t.Fatalf("expected 2 caffeine doses, got %d", exposure.DoseCount)
+	}
+	if want := time.Date(2025, 3, 9, 13, 45, 0, 0, loc); !exposure.PeakAt.Equal(want) {
+		t.Fatalf("expected the peak after the second dose at %v, got %v", want, exposure.PeakAt)
+	}
+	if exposure.Peak <= 100 {
+		t.Fatalf("expected overlapping doses to peak above 100%%, got %v", exposure.Peak)
+	}
+
+	// Each hour of the 23-hour day is counted once
+	startMinutes := days[1].Sub(days[0]).Minutes()
+	events := []kinetics.DoseEvent{
+		{Dose: 100, AtMinutes: doses[0].LoggedAt.Sub(days[0]).Minutes(), PK: caffeinePK},
+		{Dose: 100, AtMinutes: doses[1].LoggedAt.Sub(days[0]).Minutes(), PK: caffeinePK},
+		{Dose: 100, AtMinutes: doses[2].LoggedAt.Sub(days[0]).Minutes(), PK: caffeinePK},
+	}
+	expected := kinetics.CalculateExposureMetrics(events, startMinutes, startMinutes+23*60, 1, 50)
+	if !almostEqual(exposure.MinutesAboveThreshold, expected.MinutesAboveThreshold, 1) {
+		t.Fatalf("expected %v minutes above 50%%, got %v", expected.MinutesAboveThreshold, exposure.MinutesAboveThreshold)
+	}
+
+	// Magnesium's short half-life has cleared by the third day
+	third := result[2]
+	if len(third.Supplements) != 1 || third.Supplements[0].Supplement.ID != "supp-caffeine" {
+		t.Fatalf("expected only caffeine on 2025-03-10, got %+v", third.Supplements)
+	}
+}
@@ -3,11 +3,13 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"math"
 	"net/http"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/nikitalbnv/stochi/apps/engine/internal/auth"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/bioavailability"
 	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
 )
 
@@ -59,13 +61,43 @@ func (h *Handler) Analyze(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(req.SupplementIDs) == 0 {
+	// Logged intake can be checked on its own, without a stack
+	if len(req.SupplementIDs) == 0 && len(req.Products) == 0 && req.RatioIntake != models.RatioIntakeLogged {
 		http.Error(w, `{"error":"supplementIds required"}`, http.StatusBadRequest)
 		return
 	}
 
+	for _, p := range req.Products {
+		if p.ProductID == "" || p.Servings <= 0 {
+			http.Error(w, `{"error":"invalid products"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	if req.MealContext != "" && !isValidMealContext(req.MealContext) {
+		http.Error(w, `{"error":"invalid mealContext"}`, http.StatusBadRequest)
+		return
+	}
+
+	if !isValidRatioIntake(req.RatioIntake) {
+		http.Error(w, `{"error":"invalid ratioIntake"}`, http.StatusBadRequest)
+		return
+	}
+	if req.IntakeWindowHours < 0 || req.IntakeWindowHours > maxIntakeWindowHours {
+		http.Error(w, `{"error":"invalid intakeWindowHours"}`, http.StatusBadRequest)
+		return
+	}
+	if _, err := time.LoadLocation(req.TimeZone); err != nil {
+		http.Error(w, `{"error":"invalid timeZone"}`, http.StatusBadRequest)
+		return
+	}
+
 	ctx := r.Context()
 	userID, _ := auth.GetUserID(ctx)
+	if req.RatioIntake == models.RatioIntakeLogged && userID == "" {
+		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
 
 	response, err := h.analyzeInteractions(ctx, userID, req)
 	if err != nil {
@@ -117,6 +149,15 @@ func (h *Handler) CheckTiming(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) analyzeInteractions(ctx context.Context, userID string, req models.AnalyzeRequest) (*models.AnalyzeResponse, error) {
+	// Expand products into the supplements they contain
+	if len(req.Products) > 0 {
+		ingredients, err := h.getProductIngredients(ctx, userID, productIDs(req.Products))
+		if err != nil {
+			return nil, err
+		}
+		req = expandProducts(req, ingredients)
+	}
+
 	// Fetch supplements
 	supplements, err := h.getSupplements(ctx, req.SupplementIDs)
 	if err != nil {
@@ -148,6 +189,22 @@ func (h *Handler) analyzeInteractions(ctx context.Context, userID string, req mo
 		}
 	}
 
+	// Resolve serving units through the products containing the dosed supplements
+	var servings productServings
+	if hasServingUnits(req.Dosages) {
+		if loaded, err := h.getProductServings(ctx, userID, req.SupplementIDs); err == nil {
+			servings = loaded
+		}
+	}
+
+	// Estimate the absorption lost to transporter competition if dosages are provided
+	if len(req.Dosages) > 0 {
+		pks, err := h.getSupplementPKs(ctx, req.SupplementIDs)
+		if err == nil {
+			applyAbsorptionLosses(warnings, req.Dosages, supplements, servings, pks)
+		}
+	}
+
 	// Determine traffic light status
 	status := h.calculateStatus(warnings)
 
@@ -157,6 +214,10 @@ func (h *Handler) analyzeInteractions(ctx context.Context, userID string, req mo
 		Synergies: synergies,
 	}
 
+	if len(req.Dosages) > 0 {
+		response.ResolvedDosages = buildResolvedDosages(req.Dosages, supplements, servings)
+	}
+
 	// Optionally include timing analysis
 	if req.IncludeTiming && userID != "" {
 		timingWarnings, err := h.checkTimingWarnings(ctx, userID, req.SupplementIDs)
@@ -165,9 +226,21 @@ func (h *Handler) analyzeInteractions(ctx context.Context, userID string, req mo
 		}
 	}
 
-	// Check ratio warnings if dosages are provided
-	if len(req.Dosages) > 0 {
-		ratioWarnings, ratioGaps, err := h.checkRatioWarnings(ctx, req.Dosages, supplements)
+	// Check ratio warnings against the dosages, or the day's logged intake plus the dosages.
+	// If the logged intake can't be loaded, the dosages are still checked and the missing
+	// RatioIntakeWindow tells the client the log was left out.
+	ratioDosages, ratioSupplements, ratioServings := req.Dosages, supplements, servings
+	if req.RatioIntake == models.RatioIntakeLogged && userID != "" {
+		loc, _ := time.LoadLocation(req.TimeZone)
+		window := intakeWindow(time.Now().In(loc), req.IntakeWindowHours)
+		dosages, loadedSupplements, loadedServings, err := h.loggedRatioIntake(ctx, userID, window, req.Dosages)
+		if err == nil {
+			ratioDosages, ratioSupplements, ratioServings = dosages, loadedSupplements, loadedServings
+			response.RatioIntakeWindow = &window
+		}
+	}
+	if len(ratioDosages) > 0 {
+		ratioWarnings, ratioGaps, err := h.checkRatioWarnings(ctx, ratioDosages, ratioSupplements, ratioServings)
 		if err == nil {
 			if len(ratioWarnings) > 0 {
 				response.RatioWarnings = ratioWarnings
@@ -181,14 +254,54 @@ func (h *Handler) analyzeInteractions(ctx context.Context, userID string, req mo
 		}
 	}
 
+	// Check CYP450-mediated clearance changes between the analyzed supplements
+	pathways, err := h.getCYP450Pathways(ctx, req.SupplementIDs)
+	if err == nil {
+		response.MetabolicWarnings = buildMetabolicWarnings(req.SupplementIDs, supplements, pathways)
+	}
+
+	// Check meal context advisories if the meal context is provided
+	if req.MealContext != "" {
+		response.MealAdvisories = buildMealAdvisories(req.SupplementIDs, supplements, req.MealContext)
+	}
+
 	return response, nil
 }
 
+func buildMealAdvisories(supplementIDs []string, supplements map[string]models.Supplement, mealContext models.MealContext) []models.MealAdvisory {
+	var advisories []models.MealAdvisory
+	seen := make(map[string]struct{}, len(supplementIDs))
+	for _, id := range supplementIDs {
+		if _, duplicate := seen[id]; duplicate {
+			continue
+		}
+		seen[id] = struct{}{}
+
+		supplement, ok := supplements[id]
+		if !ok {
+			continue
+		}
+		if advisory, ok := bioavailability.Advise(supplement, mealContext); ok {
+			advisories = append(advisories, advisory)
+		}
+	}
+	return advisories
+}
+
+func isValidMealContext(mealContext models.MealContext) bool {
+	switch mealContext {
+	case models.MealContextFasted, models.MealContextWithMeal, models.MealContextWithFat, models.MealContextPostMeal:
+		return true
+	default:
+		return false
+	}
+}
+
 func (h *Handler) getSupplements(ctx context.Context, ids []string) (map[string]models.Supplement, error) {
 	query := `
-		SELECT id, name, form, elemental_weight, default_unit
-		FROM supplement
-		WHERE id = ANY($1)
+		SELECT s.id, s.name, s.form, s.elemental_weight, s.default_unit, s.safety_category,` + supplementUnitColumns + `
+		FROM supplement s
+		WHERE s.id = ANY($1)
 	`
 
 	rows, err := h.pool.Query(ctx, query, ids)
@@ -199,10 +312,19 @@ func (h *Handler) getSupplements(ctx context.Context, ids []string) (map[string]
 
 	supplements := make(map[string]models.Supplement)
 	for rows.Next() {
-		var s models.Supplement
-		if err := rows.Scan(&s.ID, &s.Name, &s.Form, &s.ElementalWeight, &s.DefaultUnit); err != nil {
+		var (
+			s               models.Supplement
+			elementalWeight *float32
+			unitRecord      supplementUnitRecord
+		)
+		targets := []any{&s.ID, &s.Name, &s.Form, &elementalWeight, &s.DefaultUnit, &s.SafetyCategory}
+		if err := rows.Scan(append(targets, unitRecord.scanTargets()...)...); err != nil {
 			return nil, err
 		}
+		s.ElementalWeight = widenReal(elementalWeight)
+		s.McgPerIU = widenReal(unitRecord.McgPerIU)
+		s.ConcentrationMgPerMl = widenReal(unitRecord.ConcentrationMgPerMl)
+		s.DropsPerMl = widenReal(unitRecord.DropsPerMl)
 		supplements[s.ID] = s
 	}
 
@@ -571,13 +693,34 @@ func abs(x float64) float64 {
 	return x
 }
 
-func (h *Handler) checkRatioWarnings(ctx context.Context, dosages []models.DosageInput, supplements map[string]models.Supplement) ([]models.RatioWarning, []models.RatioEvaluationGap, error) {
-	// Build a map of supplement ID to dosage for quick lookup
-	dosageMap := make(map[string]models.DosageInput)
-	for _, d := range dosages {
-		dosageMap[d.SupplementID] = d
-	}
+// defaultRatioTolerance is the tolerance of ratio rules without their own: ratios up to
+// 15% beyond a bound are not flagged.
+const defaultRatioTolerance = 0.15
 
+// Ratios at least elevatedRatioFold times beyond a bound raise the rule's severity by one
+// level, and at least severeRatioFold times by two.
+const (
+	elevatedRatioFold = 1.5
+	severeRatioFold   = 2.0
+)
+
+type ratioRuleRecord struct {
+	ID                 string
+	SourceSupplementID string
+	TargetSupplementID string
+	MinRatio           *float64
+	MaxRatio           *float64
+	OptimalRatio       *float64
+	Tolerance          *float64 // Defaults to defaultRatioTolerance, as do values outside [0, 1)
+	WarningMessage     string
+	Severity           models.Severity
+	SourceName         string
+	SourceForm         *string
+	TargetName         string
+	TargetForm         *string
+}
+
+func (h *Handler) checkRatioWarnings(ctx context.Context, dosages []models.DosageInput, supplements map[string]models.Supplement, servings productServings) ([]models.RatioWarning, []models.RatioEvaluationGap, error) {
 	// Get all supplement IDs from dosages
 	supplementIDs := make([]string, 0, len(dosages))
 	for _, d := range dosages {
@@ -587,7 +730,7 @@ func (h *Handler) checkRatioWarnings(ctx context.Context, dosages []models.Dosag
 	// Fetch ratio rules that apply to the given supplements
 	rulesQuery := `
 		SELECT rr.id, rr.source_supplement_id, rr.target_supplement_id,
-		       rr.min_ratio, rr.max_ratio, rr.optimal_ratio,
+		       rr.min_ratio, rr.max_ratio, rr.optimal_ratio, rr.tolerance,
 		       rr.warning_message, rr.severity,
 		       s1.name as source_name, s1.form as source_form,
 		       s2.name as target_name, s2.form as target_form
@@ -604,38 +747,46 @@ func (h *Handler) checkRatioWarnings(ctx context.Context, dosages []models.Dosag
 	}
 	defer rows.Close()
 
-	var warnings []models.RatioWarning
-	var gaps []models.RatioEvaluationGap
-
+	var rules []ratioRuleRecord
 	for rows.Next() {
-		var rule struct {
-			ID                 string
-			SourceSupplementID string
-			TargetSupplementID string
-			MinRatio           *float32
-			MaxRatio           *float32
-			OptimalRatio       *float32
-			WarningMessage     string
-			Severity           models.Severity
-			SourceName         string
-			SourceForm         *string
-			TargetName         string
-			TargetForm         *string
-		}
+		var (
+			rule                                        ratioRuleRecord
+			minRatio, maxRatio, optimalRatio, tolerance *float32
+		)
 
 		if err := rows.Scan(
 			&rule.ID, &rule.SourceSupplementID, &rule.TargetSupplementID,
-			&rule.MinRatio, &rule.MaxRatio, &rule.OptimalRatio,
+			&minRatio, &maxRatio, &optimalRatio, &tolerance,
 			&rule.WarningMessage, &rule.Severity,
 			&rule.SourceName, &rule.SourceForm,
 			&rule.TargetName, &rule.TargetForm,
 		); err != nil {
 			return nil, nil, err
 		}
+		rule.MinRatio, rule.MaxRatio, rule.OptimalRatio = widenReal(minRatio), widenReal(maxRatio), widenReal(optimalRatio)
+		rule.Tolerance = widenReal(tolerance)
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	warnings, gaps := buildRatioWarnings(rules, dosages, supplements, servings)
+	return warnings, gaps, nil
+}
+
+// buildRatioWarnings evaluates the ratio rules against the elemental intake of each
+// supplement, summed over all of its dosages.
+func buildRatioWarnings(rules []ratioRuleRecord, dosages []models.DosageInput, supplements map[string]models.Supplement, servings productServings) ([]models.RatioWarning, []models.RatioEvaluationGap) {
+	intake := sumElementalIntake(dosages, supplements, servings)
+
+	var warnings []models.RatioWarning
+	var gaps []models.RatioEvaluationGap
 
-		// Get dosages for source and target
-		sourceDosage, hasSource := dosageMap[rule.SourceSupplementID]
-		targetDosage, hasTarget := dosageMap[rule.TargetSupplementID]
+	for _, rule := range rules {
+		// Get the intake of source and target
+		sourceIntake, hasSource := intake[rule.SourceSupplementID]
+		targetIntake, hasTarget := intake[rule.TargetSupplementID]
 
 		if !hasSource || !hasTarget {
 			gaps = append(gaps, buildRatioEvaluationGap(
@@ -646,11 +797,7 @@ func (h *Handler) checkRatioWarnings(ctx context.Context, dosages []models.Dosag
 			continue
 		}
 
-		// Get supplement info for elemental weight
-		sourceSupp, hasSourceSupp := supplements[rule.SourceSupplementID]
-		targetSupp, hasTargetSupp := supplements[rule.TargetSupplementID]
-
-		if !hasSourceSupp || !hasTargetSupp {
+		if sourceIntake.missingSupplement || targetIntake.missingSupplement {
 			gaps = append(gaps, buildRatioEvaluationGap(
 				rule.SourceSupplementID,
 				rule.TargetSupplementID,
@@ -659,22 +806,7 @@ func (h *Handler) checkRatioWarnings(ctx context.Context, dosages []models.Dosag
 			continue
 		}
 
-		// Calculate elemental amounts
-		sourceInput := DosageInput{
-			SupplementID:           sourceDosage.SupplementID,
-			Amount:                 sourceDosage.Amount,
-			Unit:                   sourceDosage.Unit,
-			ElementalWeightPercent: getElementalWeight(sourceSupp),
-		}
-		targetInput := DosageInput{
-			SupplementID:           targetDosage.SupplementID,
-			Amount:                 targetDosage.Amount,
-			Unit:                   targetDosage.Unit,
-			ElementalWeightPercent: getElementalWeight(targetSupp),
-		}
-
-		ratio, err := CalculateRatio(sourceInput, targetInput)
-		if err != nil {
+		if sourceIntake.normalizationFailed || targetIntake.normalizationFailed || targetIntake.elementalMg == 0 {
 			gaps = append(gaps, buildRatioEvaluationGap(
 				rule.SourceSupplementID,
 				rule.TargetSupplementID,
@@ -682,23 +814,41 @@ func (h *Handler) checkRatioWarnings(ctx context.Context, dosages []models.Dosag
 			))
 			continue
 		}
+		ratio := sourceIntake.elementalMg / targetIntake.elementalMg
 
-		// Check if ratio is outside acceptable range
-		modelRule := applyRatioTolerance(models.RatioRule{
+		// Check if ratio is outside acceptable range, widened by the rule's tolerance.
+		// Tolerances outside [0, 1) would drop or invert the minimum, so they get the default.
+		tolerance := defaultRatioTolerance
+		if rule.Tolerance != nil && *rule.Tolerance >= 0 && *rule.Tolerance < 1 {
+			tolerance = *rule.Tolerance
+		}
+		modelRule := models.RatioRule{
 			MinRatio: rule.MinRatio,
 			MaxRatio: rule.MaxRatio,
-		}, 0.15)
+		}
 
-		isCompliant, _ := CheckRatioCompliance(ratio, modelRule)
+		isCompliant, _ := CheckRatioCompliance(ratio, applyRatioTolerance(modelRule, tolerance))
 		if !isCompliant {
+			// Grade by how far the ratio is beyond the rule's own bounds
+			_, deviation := CheckRatioCompliance(ratio, modelRule)
+
+			var distanceToOptimal *float64
+			if rule.OptimalRatio != nil {
+				distance := RoundToDecimal(ratio-*rule.OptimalRatio, 1)
+				distanceToOptimal = &distance
+			}
+
 			warnings = append(warnings, models.RatioWarning{
-				ID:             rule.ID,
-				Severity:       rule.Severity,
-				CurrentRatio:   RoundToDecimal(ratio, 1),
-				OptimalRatio:   rule.OptimalRatio,
-				MinRatio:       rule.MinRatio,
-				MaxRatio:       rule.MaxRatio,
-				WarningMessage: rule.WarningMessage,
+				ID:                rule.ID,
+				Severity:          gradeRatioSeverity(rule.Severity, ratio, deviation),
+				CurrentRatio:      RoundToDecimal(ratio, 1),
+				OptimalRatio:      rule.OptimalRatio,
+				MinRatio:          rule.MinRatio,
+				MaxRatio:          rule.MaxRatio,
+				Tolerance:         tolerance,
+				Deviation:         RoundToDecimal(deviation, 1),
+				DistanceToOptimal: distanceToOptimal,
+				WarningMessage:    rule.WarningMessage,
 				Source: models.SupplementInfo{
 					ID:   rule.SourceSupplementID,
 					Name: rule.SourceName,
@@ -713,10 +863,45 @@ func (h *Handler) checkRatioWarnings(ctx context.Context, dosages []models.Dosag
 		}
 	}
 
-	return warnings, gaps, rows.Err()
+	return warnings, gaps
+}
+
+// elementalIntake is the total elemental amount of a supplement across its dosages.
+type elementalIntake struct {
+	elementalMg float64
+	// The supplement's record was not loaded
+	missingSupplement bool
+	// A dosage could not be converted to mass, so the total is incomplete
+	normalizationFailed bool
+}
+
+// sumElementalIntake converts each dosage to its elemental amount and sums them per
+// supplement, so a supplement taken several times, or also contained in a product,
+// counts in full.
+func sumElementalIntake(dosages []models.DosageInput, supplements map[string]models.Supplement, servings productServings) map[string]elementalIntake {
+	intake := make(map[string]elementalIntake)
+	for _, d := range dosages {
+		total := intake[d.SupplementID]
+
+		s, ok := supplements[d.SupplementID]
+		if !ok {
+			total.missingSupplement = true
+			intake[d.SupplementID] = total
+			continue
+		}
+
+		conversion, _ := dosageConversion(d, s, servings)
+		elementalMg, err := NormalizeDosage(d.Amount, d.Unit, getElementalWeight(s), conversion)
+		if err != nil {
+			total.normalizationFailed = true
+		}
+		total.elementalMg += elementalMg
+		intake[d.SupplementID] = total
+	}
+	return intake
 }
 
-func applyRatioTolerance(rule models.RatioRule, toleranceFactor float32) models.RatioRule {
+func applyRatioTolerance(rule models.RatioRule, toleranceFactor float64) models.RatioRule {
 	if toleranceFactor <= 0 {
 		return rule
 	}
@@ -734,6 +919,44 @@ func applyRatioTolerance(rule models.RatioRule, toleranceFactor float32) models.
 	return adjusted
 }
 
+// gradeRatioSeverity escalates the rule's severity by how many times beyond the violated
+// bound the ratio is, given the deviation CheckRatioCompliance computed for it.
+func gradeRatioSeverity(severity models.Severity, ratio float64, deviation float64) models.Severity {
+	bound := ratio - deviation
+
+	var fold float64
+	switch {
+	case deviation > 0 && bound > 0:
+		fold = ratio / bound
+	case deviation > 0 || (deviation < 0 && ratio <= 0):
+		// Above a maximum of zero, or none of the source at all
+		fold = math.Inf(1)
+	case deviation < 0:
+		fold = bound / ratio
+	default:
+		return severity
+	}
+
+	switch {
+	case fold >= severeRatioFold:
+		return escalateSeverity(severity, 2)
+	case fold >= elevatedRatioFold:
+		return escalateSeverity(severity, 1)
+	default:
+		return severity
+	}
+}
+
+func escalateSeverity(severity models.Severity, levels int) models.Severity {
+	order := []models.Severity{models.SeverityLow, models.SeverityMedium, models.SeverityCritical}
+	for i, s := range order {
+		if s == severity {
+			return order[min(i+levels, len(order)-1)]
+		}
+	}
+	return severity
+}
+
 func buildRatioEvaluationGap(sourceID string, targetID string, reason models.RatioGapReason) models.RatioEvaluationGap {
 	return models.RatioEvaluationGap{
 		SourceSupplementID: sourceID,
@@ -742,13 +965,23 @@ func buildRatioEvaluationGap(sourceID string, targetID string, reason models.Rat
 	}
 }
 
-func getElementalWeight(s models.Supplement) float32 {
+func getElementalWeight(s models.Supplement) float64 {
 	if s.ElementalWeight != nil {
 		return *s.ElementalWeight
 	}
 	return 100 // Default to 100% if not specified
 }
 
+// getDosageConversion returns the supplement's unit factors; missing factors stay zero so
+// dosages in those units fail to normalize.
+func getDosageConversion(s models.Supplement) DosageConversion {
+	return DosageConversion{
+		McgPerIU:             valueOrZero(s.McgPerIU),
+		ConcentrationMgPerMl: valueOrZero(s.ConcentrationMgPerMl),
+		DropsPerMl:           valueOrZero(s.DropsPerMl),
+	}
+}
+
 func (h *Handler) calculateStatusWithRatios(currentStatus models.TrafficLightStatus, ratioWarnings []models.RatioWarning) models.TrafficLightStatus {
 	// If already red, stay red
 	if currentStatus == models.TrafficLightRed {
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+)
+
+func TestBuildMealAdvisories(t *testing.T) {
+	category := "vitamin-d"
+	supplements := map[string]models.Supplement{
+		"supp-d3":       {ID: "supp-d3", Name: "Vitamin D3", SafetyCategory: &category},
+		"supp-creatine": {ID: "supp-creatine", Name: "Creatine"},
+	}
+
+	advisories := buildMealAdvisories(
+		[]string{"supp-d3", "supp-creatine", "supp-d3", "supp-missing"},
+		supplements,
+		models.MealContextFasted,
+	)
+
+	if len(advisories) != 1 {
+		t.Fatalf("expected 1 advisory, got %d", len(advisories))
+	}
+	if advisories[0].Supplement.ID != "supp-d3" {
+		t.Fatalf("expected advisory for supp-d3, got %s", advisories[0].Supplement.ID)
+	}
+}
+
+func TestIsValidMealContext(t *testing.T) {
+	if !isValidMealContext(models.MealContextWithFat) {
+		t.Fatalf("expected with_fat to be valid")
+	}
+	if isValidMealContext("breakfast") {
+		t.Fatalf("expected breakfast to be invalid")
+	}
+}
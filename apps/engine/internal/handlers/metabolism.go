@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/kinetics"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+)
+
+const (
+	// A perpetrator counts as present while its combined concentration is at least this
+	// percentage of Cmax
+	cypActiveThresholdPercent = 10
+	// Clearance changes smaller than this fraction are not reported, to avoid alert fatigue
+	minReportedClearanceChange = 0.1
+	// Perpetrator doses are only evaluated up to Tmax plus this many half-lives after
+	// intake; first-order curves fall under the 1% clearance cutoff by then
+	cypPerpetratorHalfLives = 7
+)
+
+// cypPathway is a cyp450_pathway row with the half-life of its supplement.
+type cypPathway struct {
+	SupplementID    string
+	Enzyme          string
+	Effect          kinetics.EnzymeEffect
+	Strength        *string
+	Confidence      float64
+	HalfLifeMinutes float64 // Stored t½ of the supplement (0 when unknown)
+}
+
+func (h *Handler) getCYP450Pathways(ctx context.Context, supplementIDs []string) ([]cypPathway, error) {
+	if len(supplementIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT cp.supplement_id, cp.enzyme, cp.effect, cp.strength, cp.confidence_score, s.half_life_minutes
+		FROM cyp450_pathway cp
+		JOIN supplement s ON cp.supplement_id = s.id
+		WHERE cp.supplement_id = ANY($1)
+		ORDER BY cp.supplement_id, cp.enzyme
+	`
+
+	rows, err := h.pool.Query(ctx, query, supplementIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pathways []cypPathway
+	for rows.Next() {
+		var (
+			pathway    cypPathway
+			effect     string
+			confidence *float32
+			halfLife   *int32
+		)
+		if err := rows.Scan(&pathway.SupplementID, &pathway.Enzyme, &effect, &pathway.Strength, &confidence, &halfLife); err != nil {
+			return nil, err
+		}
+
+		pathway.Effect = kinetics.EnzymeEffect(effect)
+		pathway.Confidence = kinetics.DefaultEnzymeConfidence
+		if confidence != nil {
+			pathway.Confidence = float64(*confidence)
+		}
+		if halfLife != nil {
+			pathway.HalfLifeMinutes = float64(*halfLife)
+		}
+		pathways = append(pathways, pathway)
+	}
+
+	return pathways, rows.Err()
+}
+
+// metabolicClearance returns the clearance factor of a substrate given the supplements
+// present alongside it, and the pathways of the inhibitors and inducers responsible.
+func metabolicClearance(substrateID string, pathways []cypPathway, present func(supplementID string) bool) (float64, []cypPathway) {
+	var enzymes []string
+	for _, p := range pathways {
+		if p.SupplementID == substrateID && p.Effect == kinetics.EnzymeSubstrate {
+			enzymes = append(enzymes, p.Enzyme)
+		}
+	}
+	if len(enzymes) == 0 {
+		return 1, nil
+	}
+
+	var (
+		perpetrators []cypPathway
+		modulations  []kinetics.EnzymeModulation
+	)
+	for _, p := range pathways {
+		if p.SupplementID == substrateID || p.Effect == kinetics.EnzymeSubstrate {
+			continue
+		}
+		if !containsString(enzymes, p.Enzyme) || !present(p.SupplementID) {
+			continue
+		}
+		perpetrators = append(perpetrators, p)
+		modulations = append(modulations, kinetics.EnzymeModulation{
+			Enzyme:     p.Enzyme,
+			Effect:     p.Effect,
+			Strength:   stringOrEmpty(p.Strength),
+			Confidence: p.Confidence,
+		})
+	}
+
+	return kinetics.MetabolicClearanceFactor(enzymes, modulations), perpetrators
+}
+
+// buildMetabolicWarnings reports substrates whose clearance is changed by CYP450 inhibitors
+// or inducers among the analyzed supplements.
+func buildMetabolicWarnings(supplementIDs []string, supplements map[string]models.Supplement, pathways []cypPathway) []models.MetabolicWarning {
+	analyzed := make(map[string]struct{}, len(supplementIDs))
+	for _, id := range supplementIDs {
+		analyzed[id] = struct{}{}
+	}
+	present := func(supplementID string) bool {
+		_, ok := analyzed[supplementID]
+		return ok
+	}
+
+	ids := make([]string, 0, len(analyzed))
+	for id := range analyzed {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var warnings []models.MetabolicWarning
+	for _, substrateID := range ids {
+		factor, perpetrators := metabolicClearance(substrateID, pathways, present)
+		if len(perpetrators) == 0 || abs(factor-1) < minReportedClearanceChange {
+			continue
+		}
+
+		baseline := float64(kinetics.DefaultHalfLifeMinutes)
+		for _, p := range pathways {
+			if p.SupplementID == substrateID && p.HalfLifeMinutes > 0 {
+				baseline = p.HalfLifeMinutes
+			}
+		}
+		adjusted := kinetics.ApplyClearanceFactor(kinetics.SupplementPK{HalfLifeMinutes: baseline}, factor).HalfLifeMinutes
+
+		substrate := supplementInfo(supplements, substrateID)
+		warning := models.MetabolicWarning{
+			Substrate:             substrate,
+			ClearanceFactor:       RoundToDecimal(factor, 2),
+			BaselineHalfLifeHours: RoundToDecimal(baseline/60, 1),
+			AdjustedHalfLifeHours: RoundToDecimal(adjusted/60, 1),
+		}
+
+		causes := make([]string, 0, len(perpetrators))
+		for _, p := range perpetrators {
+			perpetrator := supplementInfo(supplements, p.SupplementID)
+			warning.Perpetrators = append(warning.Perpetrators, models.MetabolicPerpetrator{
+				Supplement: perpetrator,
+				Enzyme:     p.Enzyme,
+				Effect:     string(p.Effect),
+				Strength:   p.Strength,
+				Confidence: p.Confidence,
+			})
+			description := fmt.Sprintf("%s %s", p.Enzyme, p.Effect)
+			if p.Strength != nil {
+				description = fmt.Sprintf("%s %s", *p.Strength, description)
+			}
+			causes = append(causes, fmt.Sprintf("%s (%s)", perpetrator.Name, description))
+		}
+
+		direction := "slows"
+		if factor > 1 {
+			direction = "speeds up"
+		}
+		warning.Message = fmt.Sprintf(
+			"%s %s the clearance of %s, changing its half-life from %.1fh to %.1fh",
+			strings.Join(causes, " and "), direction, substrate.Name,
+			warning.BaselineHalfLifeHours, warning.AdjustedHalfLifeHours,
+		)
+
+		warnings = append(warnings, warning)
+	}
+
+	return warnings
+}
+
+// applyMetabolicInteractions adjusts the half-life of substrate doses for the CYP450
+// inhibitors and inducers present in the timeline. A perpetrator is present when its
+// combined concentration reaches cypActiveThresholdPercent at the substrate's dose time,
+// its peak, or one half-life after the peak. Only perpetrator doses taken within
+// cypPerpetratorHalfLives of those times are evaluated.
+func applyMetabolicInteractions(doses []timelineDose, pathways []cypPathway) []timelineDose {
+	if len(pathways) == 0 {
+		return doses
+	}
+
+	byPerpetrator := indexPerpetratorDoses(doses, pathways)
+
+	adjusted := make([]timelineDose, len(doses))
+	copy(adjusted, doses)
+	for i, dose := range doses {
+		peak, halfLife := dosePeakAndHalfLife(dose)
+		checkpoints := []time.Time{
+			dose.LoggedAt,
+			dose.LoggedAt.Add(time.Duration(peak * float64(time.Minute))),
+			dose.LoggedAt.Add(time.Duration((peak + halfLife) * float64(time.Minute))),
+		}
+
+		present := func(supplementID string) bool {
+			nearby := byPerpetrator[supplementID].window(checkpoints[0], checkpoints[len(checkpoints)-1])
+			for _, c := range concentrationsAt(nearby, checkpoints) {
+				if c >= cypActiveThresholdPercent {
+					return true
+				}
+			}
+			return false
+		}
+
+		factor, perpetrators := metabolicClearance(dose.Supplement.ID, pathways, present)
+		if len(perpetrators) > 0 {
+			adjusted[i].PK = kinetics.ApplyClearanceFactor(dose.PK, factor)
+		}
+	}
+
+	return adjusted
+}
+
+// perpetratorDoses holds one supplement's timeline doses in time order, with the longest
+// time after intake that any of them is evaluated.
+type perpetratorDoses struct {
+	doses   []timelineDose
+	horizon time.Duration
+}
+
+// indexPerpetratorDoses groups the doses of the inhibitors and inducers in pathways by
+// supplement.
+func indexPerpetratorDoses(doses []timelineDose, pathways []cypPathway) map[string]*perpetratorDoses {
+	index := make(map[string]*perpetratorDoses)
+	for _, p := range pathways {
+		if p.Effect == kinetics.EnzymeInhibitor || p.Effect == kinetics.EnzymeInducer {
+			index[p.SupplementID] = &perpetratorDoses{}
+		}
+	}
+
+	for _, d := range doses {
+		group, ok := index[d.Supplement.ID]
+		if !ok {
+			continue
+		}
+		group.doses = append(group.doses, d)
+
+		peak, halfLife := dosePeakAndHalfLife(d)
+		horizon := time.Duration((peak + cypPerpetratorHalfLives*halfLife) * float64(time.Minute))
+		if horizon > group.horizon {
+			group.horizon = horizon
+		}
+	}
+
+	for _, group := range index {
+		sort.SliceStable(group.doses, func(i, j int) bool {
+			return group.doses[i].LoggedAt.Before(group.doses[j].LoggedAt)
+		})
+	}
+	return index
+}
+
+// window returns the doses that can contribute to the concentration between from and to.
+func (p *perpetratorDoses) window(from, to time.Time) []timelineDose {
+	if p == nil {
+		return nil
+	}
+	earliest := from.Add(-p.horizon)
+	start := sort.Search(len(p.doses), func(i int) bool { return !p.doses[i].LoggedAt.Before(earliest) })
+	end := sort.Search(len(p.doses), func(i int) bool { return p.doses[i].LoggedAt.After(to) })
+	if end < start {
+		return nil
+	}
+	return p.doses[start:end]
+}
+
+// dosePeakAndHalfLife returns the stored Tmax and t½ of a dose in minutes, or the defaults.
+func dosePeakAndHalfLife(dose timelineDose) (float64, float64) {
+	peak := dose.PK.PeakMinutes
+	if peak <= 0 {
+		peak = kinetics.DefaultPeakMinutes
+	}
+	halfLife := dose.PK.HalfLifeMinutes
+	if halfLife <= 0 {
+		halfLife = kinetics.DefaultHalfLifeMinutes
+	}
+	return peak, halfLife
+}
+
+// concentrationsAt returns the combined concentration (% of Cmax) of doses at each of the
+// given times, evaluating each dose as one curve.
+func concentrationsAt(doses []timelineDose, at []time.Time) []float64 {
+	totals := make([]float64, len(at))
+	minutes := make([]float64, len(at))
+	for _, d := range doses {
+		for i, t := range at {
+			minutes[i] = t.Sub(d.LoggedAt).Minutes()
+		}
+		curve := kinetics.CalculateConcentrationCurve(kinetics.ConcentrationParams{
+			Dose:  d.DoseMg,
+			PK:    d.PK,
+			Route: d.Route,
+			Meal:  d.Meal,
+		}, minutes)
+		for i, c := range curve {
+			totals[i] += c
+		}
+	}
+	return totals
+}
+
+// supplementInfo returns the info of a loaded supplement, or just its ID when not loaded.
+func supplementInfo(supplements map[string]models.Supplement, id string) models.SupplementInfo {
+	s, ok := supplements[id]
+	if !ok {
+		return models.SupplementInfo{ID: id}
+	}
+	return models.SupplementInfo{ID: s.ID, Name: s.Name, Form: s.Form}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
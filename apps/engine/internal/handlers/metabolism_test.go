@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/kinetics"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+)
+
+func cypTestPathways() []cypPathway {
+	strong := "strong"
+	return []cypPathway{
+		{SupplementID: "supp-caffeine", Enzyme: "CYP1A2", Effect: kinetics.EnzymeSubstrate, Confidence: 1, HalfLifeMinutes: 300},
+		{SupplementID: "supp-fluvoxamine", Enzyme: "CYP1A2", Effect: kinetics.EnzymeInhibitor, Strength: &strong, Confidence: 1},
+		{SupplementID: "supp-fluvoxamine", Enzyme: "CYP1A2", Effect: kinetics.EnzymeSubstrate, Confidence: 1},
+		{SupplementID: "supp-st-johns", Enzyme: "CYP3A4", Effect: kinetics.EnzymeInducer, Strength: &strong, Confidence: 1},
+	}
+}
+
+func TestBuildMetabolicWarnings(t *testing.T) {
+	supplements := map[string]models.Supplement{
+		"supp-caffeine":    {ID: "supp-caffeine", Name: "Caffeine"},
+		"supp-fluvoxamine": {ID: "supp-fluvoxamine", Name: "Fluvoxamine"},
+		"supp-st-johns":    {ID: "supp-st-johns", Name: "St. John's Wort"},
+	}
+
+	warnings := buildMetabolicWarnings([]string{"supp-caffeine", "supp-fluvoxamine", "supp-st-johns"}, supplements, cypTestPathways())
+
+	if len(warnings) != 1 {
+		t.Fatalf("expected 1 metabolic warning, got %d", len(warnings))
+	}
+	warning := warnings[0]
+	if warning.Substrate.ID != "supp-caffeine" {
+		t.Fatalf("expected caffeine as substrate, got %s", warning.Substrate.ID)
+	}
+	if warning.ClearanceFactor != 0.2 || warning.BaselineHalfLifeHours != 5 || warning.AdjustedHalfLifeHours != 25 {
+		t.Fatalf("expected t½ 5h → 25h at 0.2x clearance, got %+v", warning)
+	}
+	if len(warning.Perpetrators) != 1 || warning.Perpetrators[0].Supplement.Name != "Fluvoxamine" {
+		t.Fatalf("expected fluvoxamine as the only perpetrator, got %+v", warning.Perpetrators)
+	}
+	if !strings.Contains(warning.Message, "strong CYP1A2 inhibitor") || !strings.Contains(warning.Message, "slows") {
+		t.Fatalf("expected message to explain the inhibition, got %q", warning.Message)
+	}
+
+	if warnings := buildMetabolicWarnings([]string{"supp-caffeine", "supp-st-johns"}, supplements, cypTestPathways()); len(warnings) != 0 {
+		t.Fatalf("expected no warning for an inducer of an unrelated enzyme, got %+v", warnings)
+	}
+}
+
+func TestApplyMetabolicInteractions(t *testing.T) {
+	start := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	caffeinePK := kinetics.SupplementPK{KineticsType: kinetics.FirstOrder, PeakMinutes: 45, HalfLifeMinutes: 300}
+	fluvoxaminePK := kinetics.SupplementPK{KineticsType: kinetics.FirstOrder, PeakMinutes: 240, HalfLifeMinutes: 900}
+	caffeine := models.SupplementInfo{ID: "supp-caffeine", Name: "Caffeine"}
+	fluvoxamine := models.SupplementInfo{ID: "supp-fluvoxamine", Name: "Fluvoxamine"}
+
+	doses := []timelineDose{
+		{Supplement: caffeine, LoggedAt: start.Add(-72 * time.Hour), DoseMg: 100, PK: caffeinePK},
+		{Supplement: fluvoxamine, LoggedAt: start.Add(-2 * time.Hour), DoseMg: 50, PK: fluvoxaminePK},
+		{Supplement: caffeine, LoggedAt: start, DoseMg: 100, PK: caffeinePK},
+	}
+
+	adjusted := applyMetabolicInteractions(doses, cypTestPathways())
+
+	if adjusted[0].PK.HalfLifeMinutes != 300 {
+		t.Fatalf("expected caffeine before fluvoxamine to be unaffected, got t½ %v", adjusted[0].PK.HalfLifeMinutes)
+	}
+	if adjusted[1].PK != fluvoxaminePK {
+		t.Fatalf("expected the inhibitor's own PK to be unchanged, got %+v", adjusted[1].PK)
+	}
+	if adjusted[2].PK.HalfLifeMinutes != 1500 {
+		t.Fatalf("expected co-present caffeine t½ of 1500, got %v", adjusted[2].PK.HalfLifeMinutes)
+	}
+	if doses[2].PK.HalfLifeMinutes != 300 {
+		t.Fatalf("expected input doses to be left unmodified")
+	}
+}
+
+func TestIndexPerpetratorDoses(t *testing.T) {
+	start := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	fluvoxaminePK := kinetics.SupplementPK{KineticsType: kinetics.FirstOrder, PeakMinutes: 240, HalfLifeMinutes: 900}
+	fluvoxamine := models.SupplementInfo{ID: "supp-fluvoxamine", Name: "Fluvoxamine"}
+	caffeine := models.SupplementInfo{ID: "supp-caffeine", Name: "Caffeine"}
+
+	doses := []timelineDose{
+		{Supplement: fluvoxamine, LoggedAt: start.Add(-10 * 24 * time.Hour), DoseMg: 50, PK: fluvoxaminePK},
+		{Supplement: caffeine, LoggedAt: start, DoseMg: 100},
+		{Supplement: fluvoxamine, LoggedAt: start.Add(6 * time.Hour), DoseMg: 50, PK: fluvoxaminePK},
+		{Supplement: fluvoxamine, LoggedAt: start.Add(-24 * time.Hour), DoseMg: 50, PK: fluvoxaminePK},
+	}
+
+	index := indexPerpetratorDoses(doses, cypTestPathways())
+	if _, ok := index["supp-caffeine"]; ok {
+		t.Fatalf("expected substrates not to be indexed")
+	}
+
+	// Tmax + 7 t½ = 4h + 105h; the dose 10 days earlier is past it, the later one is after the window
+	nearby := index["supp-fluvoxamine"].window(start, start.Add(2*time.Hour))
+	if len(nearby) != 1 || !nearby[0].LoggedAt.Equal(start.Add(-24*time.Hour)) {
+		t.Fatalf("expected only the previous day's dose, got %+v", nearby)
+	}
+
+	if nearby := index["supp-st-johns"].window(start, start.Add(2*time.Hour)); len(nearby) != 0 {
+		t.Fatalf("expected no doses of an absent perpetrator, got %+v", nearby)
+	}
+	if nearby := index["supp-unknown"].window(start, start); nearby != nil {
+		t.Fatalf("expected no doses of an unindexed supplement, got %+v", nearby)
+	}
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/auth"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/calibration"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/kinetics"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+)
+
+// OptimizeDosing handles the dosing optimizer endpoint
+func (h *Handler) OptimizeDosing(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.OptimizeDosingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	userID, ok := auth.GetUserID(ctx)
+	if !ok || userID == "" {
+		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	if req.SupplementID == "" {
+		http.Error(w, `{"error":"supplementId required"}`, http.StatusBadRequest)
+		return
+	}
+
+	target, constraints, err := resolveDosingProblem(req)
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	calibrations, err := h.getLatestCalibrations(ctx, userID)
+	if err != nil {
+		http.Error(w, `{"error":"optimization failed"}`, http.StatusInternalServerError)
+		return
+	}
+
+	info, pk, err := h.getSupplementPK(ctx, req.SupplementID, calibrations)
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, `{"error":"supplement not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error":"optimization failed"}`, http.StatusInternalServerError)
+		return
+	}
+
+	recommendation, err := kinetics.OptimizeDosing(pk, target, constraints)
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	response := models.OptimizeDosingResponse{
+		Supplement:          info,
+		Recommended:         toDosingPlan(recommendation.Recommended),
+		SingleDose:          toDosingPlan(recommendation.SingleDose),
+		SplitSavingsPercent: RoundToDecimal(recommendation.SplitSavingsPercent, 1),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// resolveDosingProblem validates the request and converts it to kinetics inputs in mg.
+func resolveDosingProblem(req models.OptimizeDosingRequest) (kinetics.DosingTarget, kinetics.DosingConstraints, error) {
+	start, okStart := parseClockMinutes(req.WindowStart)
+	end, okEnd := parseClockMinutes(req.WindowEnd)
+	if !okStart || !okEnd {
+		return kinetics.DosingTarget{}, kinetics.DosingConstraints{}, errors.New("windowStart and windowEnd must be HH:MM")
+	}
+
+	unit := req.Unit
+	if unit == "" {
+		unit = models.DosageUnitMg
+	}
+	toMg := func(amount float64) (float64, error) {
+		mg, err := ToMilligrams(amount, unit)
+		if err != nil {
+			return 0, fmt.Errorf("unit %s cannot be converted to mg", unit)
+		}
+		return mg, nil
+	}
+
+	maxDailyDose, err := toMg(req.MaxDailyDose)
+	if err != nil {
+		return kinetics.DosingTarget{}, kinetics.DosingConstraints{}, err
+	}
+	doseStep, _ := toMg(req.DoseStep)
+	referenceDose, _ := toMg(req.ReferenceDose)
+
+	slots := make([]float64, 0, len(req.AllowedSlots))
+	for _, slot := range req.AllowedSlots {
+		minutes, ok := parseClockMinutes(slot)
+		if !ok {
+			return kinetics.DosingTarget{}, kinetics.DosingConstraints{}, errors.New("allowedSlots must be HH:MM")
+		}
+		slots = append(slots, float64(minutes))
+	}
+	if len(slots) == 0 {
+		for _, minutes := range defaultSlotMinutes {
+			slots = append(slots, float64(minutes))
+		}
+		sort.Float64s(slots)
+	}
+
+	target := kinetics.DosingTarget{
+		StartMinutes: float64(start),
+		EndMinutes:   float64(end),
+		MinPercent:   req.MinPercent,
+	}
+	constraints := kinetics.DosingConstraints{
+		MaxDailyDose:   maxDailyDose,
+		AllowedSlots:   slots,
+		MaxDosesPerDay: req.MaxDosesPerDay,
+		DoseStep:       doseStep,
+		ReferenceDose:  referenceDose,
+	}
+	return target, constraints, nil
+}
+
+// getSupplementPK loads a supplement's PK parameters with the user's calibration applied.
+func (h *Handler) getSupplementPK(ctx context.Context, supplementID string, calibrations []calibration.Record) (models.SupplementInfo, kinetics.SupplementPK, error) {
+	query := `
+		SELECT s.id, s.name, s.form, s.safety_category,` + supplementPKColumns + `
+		FROM supplement s
+		WHERE s.id = $1
+	`
+
+	var (
+		info           models.SupplementInfo
+		safetyCategory *string
+		pkRecord       supplementPKRecord
+	)
+	targets := []any{&info.ID, &info.Name, &info.Form, &safetyCategory}
+	if err := h.pool.QueryRow(ctx, query, supplementID).Scan(append(targets, pkRecord.scanTargets()...)...); err != nil {
+		return models.SupplementInfo{}, kinetics.SupplementPK{}, err
+	}
+
+	pk := pkRecord.toPK()
+	pk.Calibration = calibration.ForSupplement(calibrations, info.Name, stringOrEmpty(safetyCategory))
+	return info, pk, nil
+}
+
+// toDosingPlan converts a kinetics plan for the response, with times as "HH:MM".
+func toDosingPlan(plan kinetics.DosingPlan) models.DosingPlan {
+	result := models.DosingPlan{
+		Doses:                make([]models.PlannedDose, 0, len(plan.Doses)),
+		TotalDailyDoseMg:     RoundToDecimal(plan.TotalDailyDose, 1),
+		MinPercentInWindow:   RoundToDecimal(plan.MinPercentInWindow, 1),
+		AbsorptionEfficiency: RoundToDecimal(plan.AbsorptionEfficiency, 3),
+		MeetsTarget:          plan.MeetsTarget,
+	}
+	for _, dose := range plan.Doses {
+		minutes := int(math.Round(dose.AtMinutes)) % kinetics.MinutesPerDay
+		result.Doses = append(result.Doses, models.PlannedDose{
+			Time:   fmt.Sprintf("%02d:%02d", minutes/60, minutes%60),
+			DoseMg: RoundToDecimal(dose.Dose, 1),
+		})
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/kinetics"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+)
+
+func TestResolveDosingProblem_ConvertsUnitsAndDefaultsSlots(t *testing.T) {
+	req := models.OptimizeDosingRequest{
+		WindowStart:  "08:00",
+		WindowEnd:    "20:00",
+		MinPercent:   40,
+		MaxDailyDose: 2,
+		Unit:         models.DosageUnitG,
+		DoseStep:     0.5,
+	}
+
+	target, constraints, err := resolveDosingProblem(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.StartMinutes != 480 || target.EndMinutes != 1200 || target.MinPercent != 40 {
+		t.Fatalf("unexpected target: %+v", target)
+	}
+	if constraints.MaxDailyDose != 2000 || constraints.DoseStep != 500 {
+		t.Fatalf("expected doses converted to mg, got %+v", constraints)
+	}
+
+	want := []float64{480, 720, 1080, 1320}
+	if len(constraints.AllowedSlots) != len(want) {
+		t.Fatalf("expected default slots %v, got %v", want, constraints.AllowedSlots)
+	}
+	for i, slot := range want {
+		if constraints.AllowedSlots[i] != slot {
+			t.Fatalf("expected default slots %v, got %v", want, constraints.AllowedSlots)
+		}
+	}
+}
+
+func TestResolveDosingProblem_RejectsInvalidInput(t *testing.T) {
+	tests := []struct {
+		name string
+		req  models.OptimizeDosingRequest
+	}{
+		{"malformed window", models.OptimizeDosingRequest{WindowStart: "8am", WindowEnd: "20:00", MaxDailyDose: 100}},
+		{"malformed slot", models.OptimizeDosingRequest{WindowStart: "08:00", WindowEnd: "20:00", MaxDailyDose: 100, AllowedSlots: []string{"25:00"}}},
+		{"unconvertible unit", models.OptimizeDosingRequest{WindowStart: "08:00", WindowEnd: "20:00", MaxDailyDose: 100, Unit: models.DosageUnitIU}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := resolveDosingProblem(tt.req); err == nil {
+				t.Fatalf("expected an error")
+			}
+		})
+	}
+}
+
+func TestToDosingPlan_FormatsTimes(t *testing.T) {
+	plan := kinetics.DosingPlan{
+		Doses: []kinetics.ScheduledDose{
+			{Dose: 250.04, AtMinutes: 450},
+			{Dose: 250.04, AtMinutes: 1320},
+		},
+		TotalDailyDose:       500.08,
+		MinPercentInWindow:   41.26,
+		AbsorptionEfficiency: 0.44444,
+		MeetsTarget:          true,
+	}
+
+	result := toDosingPlan(plan)
+
+	if result.Doses[0].Time != "07:30" || result.Doses[1].Time != "22:00" {
+		t.Fatalf("unexpected times: %+v", result.Doses)
+	}
+	if result.Doses[0].DoseMg != 250 || result.TotalDailyDoseMg != 500.1 {
+		t.Fatalf("unexpected rounding: %+v", result)
+	}
+	if result.MinPercentInWindow != 41.3 || result.AbsorptionEfficiency != 0.444 || !result.MeetsTarget {
+		t.Fatalf("unexpected plan summary: %+v", result)
+	}
+}
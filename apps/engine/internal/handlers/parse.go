@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/auth"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/doseparse"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+)
+
+const (
+	// Upper bound on input length; command-bar entries are a line at most
+	maxParseTextLength = 500
+	maxParseCandidates = 5
+)
+
+// Parse handles the free-text dose parsing endpoint
+func (h *Handler) Parse(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.ParseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	if strings.TrimSpace(req.Text) == "" {
+		http.Error(w, `{"error":"text required"}`, http.StatusBadRequest)
+		return
+	}
+	if len(req.Text) > maxParseTextLength {
+		http.Error(w, `{"error":"text too long"}`, http.StatusBadRequest)
+		return
+	}
+
+	loc := time.UTC
+	if req.TimeZone != "" {
+		var err error
+		if loc, err = time.LoadLocation(req.TimeZone); err != nil {
+			http.Error(w, `{"error":"invalid timeZone"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	supplements, err := h.getSupplementCatalog(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"parse failed"}`, http.StatusInternalServerError)
+		return
+	}
+
+	command := doseparse.Parse(req.Text, time.Now().In(loc))
+	response := buildParseResponse(command, supplements)
+
+	// Servings are converted to mass through the user's products, then the catalog's
+	if ids := servingSupplementIDs(response.Items); len(ids) > 0 {
+		userID, _ := auth.GetUserID(r.Context())
+		servings, err := h.getProductServings(r.Context(), userID, ids)
+		if err != nil {
+			http.Error(w, `{"error":"parse failed"}`, http.StatusInternalServerError)
+			return
+		}
+		resolveParsedServings(response.Items, servings)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// getSupplementCatalog loads the names and forms of all supplements for name matching.
+func (h *Handler) getSupplementCatalog(ctx context.Context) ([]models.SupplementInfo, error) {
+	rows, err := h.pool.Query(ctx, `SELECT id, name, form FROM supplement ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var supplements []models.SupplementInfo
+	for rows.Next() {
+		var s models.SupplementInfo
+		if err := rows.Scan(&s.ID, &s.Name, &s.Form); err != nil {
+			return nil, err
+		}
+		supplements = append(supplements, s)
+	}
+
+	return supplements, rows.Err()
+}
+
+// buildParseResponse resolves the supplement named by each parsed item. Items whose name
+// matches several supplements about equally well are returned with ranked candidates and
+// no dosage, for the client to ask which one was meant.
+func buildParseResponse(command doseparse.Command, supplements []models.SupplementInfo) models.ParseResponse {
+	response := models.ParseResponse{
+		LoggedAt:    command.LoggedAt,
+		MealContext: command.MealContext,
+		Items:       make([]models.ParsedItem, 0, len(command.Items)),
+	}
+	if command.TimeDescription != "" {
+		response.TimeDescription = &command.TimeDescription
+	}
+
+	for _, parsed := range command.Items {
+		item := models.ParsedItem{
+			Query:      parsed.Query,
+			Unit:       parsed.Unit,
+			Candidates: make([]models.SupplementCandidate, 0),
+		}
+		if parsed.Unit != "" {
+			amount := parsed.Amount
+			item.Amount = &amount
+		}
+
+		candidates := doseparse.RankSupplements(parsed.Query, supplements)
+		for i, c := range candidates {
+			if i == maxParseCandidates {
+				break
+			}
+			item.Candidates = append(item.Candidates, models.SupplementCandidate{
+				Supplement: c.Supplement,
+				Score:      RoundToDecimal(c.Score, 1),
+			})
+		}
+
+		if supplement, ok := doseparse.Resolve(candidates); ok {
+			item.Supplement = &supplement
+			// Serving units get their dosage from resolveParsedServings
+			if item.Amount != nil && !parsed.Unit.IsServing() {
+				item.Dosage = &models.DosageInput{
+					SupplementID: supplement.ID,
+					Amount:       parsed.Amount,
+					Unit:         parsed.Unit,
+				}
+			}
+		} else {
+			item.Ambiguous = len(candidates) > 1
+		}
+
+		response.Items = append(response.Items, item)
+	}
+
+	return response
+}
+
+// servingSupplementIDs returns the resolved supplements of items given in serving units.
+func servingSupplementIDs(items []models.ParsedItem) []string {
+	var ids []string
+	for _, item := range items {
+		if item.Supplement != nil && item.Amount != nil && item.Unit.IsServing() {
+			ids = append(ids, item.Supplement.ID)
+		}
+	}
+	return ids
+}
+
+// resolveParsedServings sets the dosage of items given in serving units to the servings'
+// amount of the supplement in the preferred product with that serving unit, e.g.
+// "2 caps zinc" to 50 mg for a 25 mg capsule. Items no product resolves keep no dosage,
+// as serving units cannot be logged directly.
+func resolveParsedServings(items []models.ParsedItem, servings productServings) {
+	for i, item := range items {
+		if item.Supplement == nil || item.Amount == nil || !item.Unit.IsServing() {
+			continue
+		}
+
+		serving, ok := servings.resolve(models.DosageInput{SupplementID: item.Supplement.ID, Unit: item.Unit})
+		if !ok {
+			continue
+		}
+
+		product := serving.Product
+		items[i].Product = &product
+		items[i].Dosage = &models.DosageInput{
+			SupplementID: item.Supplement.ID,
+			Amount:       RoundToDecimal(*item.Amount*serving.Serving.Amount, 3),
+			Unit:         serving.Serving.Unit,
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/doseparse"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+)
+
+func TestBuildParseResponse(t *testing.T) {
+	supplements := []models.SupplementInfo{
+		{ID: "supp-mg-gly", Name: "Magnesium Glycinate"},
+		{ID: "supp-mg-cit", Name: "Magnesium Citrate"},
+		{ID: "supp-zn", Name: "Zinc Picolinate"},
+		{ID: "supp-d3", Name: "Vitamin D3"},
+	}
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	response := buildParseResponse(doseparse.Parse("2 caps zinc, mag 400mg and d3 at 9am with breakfast", now), supplements)
+
+	if want := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC); !response.LoggedAt.Equal(want) {
+		t.Fatalf("expected loggedAt %v, got %v", want, response.LoggedAt)
+	}
+	if response.TimeDescription == nil || *response.TimeDescription != "9am" {
+		t.Fatalf("expected time description 9am, got %v", response.TimeDescription)
+	}
+	if response.MealContext != models.MealContextWithMeal {
+		t.Fatalf("expected with_meal, got %q", response.MealContext)
+	}
+	if len(response.Items) != 3 {
+		t.Fatalf("expected 3 items, got %+v", response.Items)
+	}
+
+	zinc := response.Items[0]
+	if zinc.Supplement == nil || zinc.Amount == nil || *zinc.Amount != 2 || zinc.Unit != models.DosageUnitCapsule {
+		t.Fatalf("expected 2 capsules of zinc, got %+v", zinc)
+	}
+	if zinc.Dosage != nil {
+		t.Fatalf("expected servings to stay unresolved until products are applied, got %+v", zinc.Dosage)
+	}
+	if zinc.Ambiguous || len(zinc.Candidates) != 1 {
+		t.Fatalf("expected one unambiguous candidate, got %+v", zinc)
+	}
+
+	mag := response.Items[1]
+	if !mag.Ambiguous || mag.Supplement != nil || mag.Dosage != nil {
+		t.Fatalf("expected an ambiguous item without a dosage, got %+v", mag)
+	}
+	if len(mag.Candidates) != 2 || mag.Amount == nil || *mag.Amount != 400 || mag.Unit != models.DosageUnitMg {
+		t.Fatalf("expected the parsed amount and both magnesium forms, got %+v", mag)
+	}
+
+	d3 := response.Items[2]
+	if d3.Supplement == nil || d3.Supplement.ID != "supp-d3" {
+		t.Fatalf("expected Vitamin D3, got %+v", d3.Supplement)
+	}
+	if d3.Amount != nil || d3.Dosage != nil {
+		t.Fatalf("expected no dosage without an amount, got %+v", d3)
+	}
+}
+
+func TestBuildParseResponse_Unmatched(t *testing.T) {
+	response := buildParseResponse(doseparse.Parse("unobtainium 5mg", time.Now()), []models.SupplementInfo{{ID: "supp-zn", Name: "Zinc Picolinate"}})
+
+	if response.TimeDescription != nil {
+		t.Fatalf("expected no time description, got %v", *response.TimeDescription)
+	}
+	item := response.Items[0]
+	if item.Ambiguous || item.Supplement != nil || item.Dosage != nil || len(item.Candidates) != 0 {
+		t.Fatalf("expected an unmatched item, got %+v", item)
+	}
+}
+
+func TestResolveParsedServings(t *testing.T) {
+	supplements := []models.SupplementInfo{
+		{ID: "supp-zn", Name: "Zinc Picolinate"},
+		{ID: "supp-d3", Name: "Vitamin D3"},
+	}
+	response := buildParseResponse(doseparse.Parse("3 caps zinc, 2 tabs d3", time.Now()), supplements)
+	if ids := servingSupplementIDs(response.Items); len(ids) != 2 {
+		t.Fatalf("expected both serving items to need products, got %v", ids)
+	}
+
+	servings := productServings{
+		"supp-zn": {
+			{Product: models.ProductInfo{ID: "prod-zn-tab"}, ServingUnit: models.DosageUnitTablet, Serving: Serving{Amount: 50, Unit: models.DosageUnitMg}},
+			{Product: models.ProductInfo{ID: "prod-zn-cap", Name: "Zinc 25"}, ServingUnit: models.DosageUnitCapsule, Serving: Serving{Amount: 0.1, Unit: models.DosageUnitG}},
+		},
+	}
+	resolveParsedServings(response.Items, servings)
+
+	zinc := response.Items[0]
+	if zinc.Product == nil || zinc.Product.ID != "prod-zn-cap" {
+		t.Fatalf("expected the capsule product, got %+v", zinc.Product)
+	}
+	if zinc.Dosage == nil || zinc.Dosage.SupplementID != "supp-zn" || zinc.Dosage.Amount != 0.3 || zinc.Dosage.Unit != models.DosageUnitG {
+		t.Fatalf("expected 3 capsules resolved to 0.3 g, got %+v", zinc.Dosage)
+	}
+
+	d3 := response.Items[1]
+	if d3.Dosage != nil || d3.Product != nil {
+		t.Fatalf("expected no loggable dosage without a product, got %+v", d3)
+	}
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/kinetics"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+)
+
+// supplementPKColumns selects the pharmacokinetic columns of the supplement table.
+// It expects the supplement table to be aliased as "s" and must be scanned with
+// supplementPKRecord.scanTargets.
+const supplementPKColumns = `
+		s.route, s.peak_minutes, s.half_life_minutes, s.bioavailability_percent,
+		s.kinetics_type, s.vmax, s.km,
+		s.absorption_saturation_dose, s.rda_amount,
+		s.absorption_rate_constant, s.compartment_model, s.compartment_params,
+		s.half_life_cv, s.peak_cv, s.bioavailability_cv, s.vmax_cv, s.km_cv,
+		s.volume_of_distribution, s.absorption_window_minutes`
+
+// supplementPKRecord holds the nullable PK columns of a supplement row.
+type supplementPKRecord struct {
+	Route                    string
+	PeakMinutes              *int32
+	HalfLifeMinutes          *int32
+	BioavailabilityPercent   *float32
+	KineticsType             *string
+	Vmax                     *float32
+	Km                       *float32
+	AbsorptionSaturationDose *float32
+	RDAAmount                *float32
+	AbsorptionRateConstant   *float32
+	CompartmentModel         *string
+	CompartmentParams        *kinetics.CompartmentParams
+	HalfLifeCV               *float32
+	PeakCV                   *float32
+	BioavailabilityCV        *float32
+	VmaxCV                   *float32
+	KmCV                     *float32
+	VolumeOfDistribution     *float32
+	AbsorptionWindowMinutes  *int32
+}
+
+// scanTargets returns the scan destinations matching supplementPKColumns.
+func (r *supplementPKRecord) scanTargets() []any {
+	return []any{
+		&r.Route, &r.PeakMinutes, &r.HalfLifeMinutes, &r.BioavailabilityPercent,
+		&r.KineticsType, &r.Vmax, &r.Km,
+		&r.AbsorptionSaturationDose, &r.RDAAmount,
+		&r.AbsorptionRateConstant, &r.CompartmentModel, &r.CompartmentParams,
+		&r.HalfLifeCV, &r.PeakCV, &r.BioavailabilityCV, &r.VmaxCV, &r.KmCV,
+		&r.VolumeOfDistribution, &r.AbsorptionWindowMinutes,
+	}
+}
+
+// toPK converts the record to kinetics parameters.
+// Missing values are left at zero so the kinetics package applies its defaults.
+func (r supplementPKRecord) toPK() kinetics.SupplementPK {
+	pk := kinetics.SupplementPK{
+		KineticsType: kinetics.FirstOrder,
+		Route:        kinetics.Route(r.Route),
+	}
+
+	if r.KineticsType != nil && *r.KineticsType != "" {
+		pk.KineticsType = kinetics.KineticsType(*r.KineticsType)
+	}
+	if r.PeakMinutes != nil {
+		pk.PeakMinutes = float64(*r.PeakMinutes)
+	}
+	if r.HalfLifeMinutes != nil {
+		pk.HalfLifeMinutes = float64(*r.HalfLifeMinutes)
+	}
+	if r.BioavailabilityPercent != nil {
+		pk.BioavailabilityPercent = float64(*r.BioavailabilityPercent)
+	}
+	if r.Vmax != nil {
+		pk.Vmax = float64(*r.Vmax)
+	}
+	if r.Km != nil {
+		pk.Km = float64(*r.Km)
+	}
+	if r.AbsorptionSaturationDose != nil {
+		pk.AbsorptionSaturationDose = float64(*r.AbsorptionSaturationDose)
+	}
+	if r.RDAAmount != nil {
+		pk.RDAAmount = float64(*r.RDAAmount)
+	}
+	if r.AbsorptionRateConstant != nil {
+		pk.AbsorptionRateConstant = float64(*r.AbsorptionRateConstant)
+	}
+	if r.VolumeOfDistribution != nil {
+		pk.VolumeOfDistribution = float64(*r.VolumeOfDistribution)
+	}
+	if r.AbsorptionWindowMinutes != nil {
+		pk.AbsorptionWindowMinutes = float64(*r.AbsorptionWindowMinutes)
+	}
+	if r.CompartmentModel != nil {
+		pk.CompartmentModel = *r.CompartmentModel
+	}
+	if r.CompartmentParams != nil {
+		pk.Compartment = *r.CompartmentParams
+	}
+
+	pk.Variability = kinetics.Variability{
+		HalfLifeCV:        cvOrDefault(r.HalfLifeCV),
+		PeakCV:            cvOrDefault(r.PeakCV),
+		BioavailabilityCV: cvOrDefault(r.BioavailabilityCV),
+		VmaxCV:            cvOrDefault(r.VmaxCV),
+		KmCV:              cvOrDefault(r.KmCV),
+	}
+
+	return pk
+}
+
+// getSupplementPKs loads the PK parameters of the given supplements, keyed by ID.
+func (h *Handler) getSupplementPKs(ctx context.Context, supplementIDs []string) (map[string]kinetics.SupplementPK, error) {
+	query := `
+		SELECT s.id,` + supplementPKColumns + `
+		FROM supplement s
+		WHERE s.id = ANY($1)
+	`
+
+	rows, err := h.pool.Query(ctx, query, supplementIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pks := make(map[string]kinetics.SupplementPK)
+	for rows.Next() {
+		var (
+			id       string
+			pkRecord supplementPKRecord
+		)
+		if err := rows.Scan(append([]any{&id}, pkRecord.scanTargets()...)...); err != nil {
+			return nil, err
+		}
+		pks[id] = pkRecord.toPK()
+	}
+
+	return pks, rows.Err()
+}
+
+// cvOrDefault returns a stored coefficient of variation, or kinetics.DefaultParameterCV
+// when the supplement has no curated value.
+func cvOrDefault(cv *float32) float64 {
+	if cv == nil {
+		return kinetics.DefaultParameterCV
+	}
+	return float64(*cv)
+}
+
+// kineticsDoseMg converts a dosage to mg for the kinetics models. IU, ml and drops use
+// the supplement's conversion factors; when a factor is missing the raw amount is kept,
+// like the web timeline.
+func kineticsDoseMg(amount float64, unit models.DosageUnit, conversion DosageConversion) float64 {
+	mg, err := conversion.ToMilligrams(amount, unit)
+	if err != nil {
+		return amount
+	}
+	return mg
+}
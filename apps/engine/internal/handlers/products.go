@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+)
+
+// productServing is the serving size of one supplement in a product.
+type productServing struct {
+	Product     models.ProductInfo
+	ServingUnit models.DosageUnit
+	Serving     Serving
+}
+
+// productIngredient is one supplement in a product, with its amount per serving.
+type productIngredient struct {
+	SupplementID string
+	productServing
+}
+
+// productServings lists the products containing each supplement, keyed by supplement ID
+// and ordered by preference.
+type productServings map[string][]productServing
+
+// getProductServings loads the products containing the given supplements that the user
+// can see: their own products first, then the catalog's, oldest first.
+func (h *Handler) getProductServings(ctx context.Context, userID string, supplementIDs []string) (productServings, error) {
+	query := `
+		SELECT pi.supplement_id, p.id, p.name, p.brand, p.serving_unit, pi.amount, pi.unit
+		FROM product_ingredient pi
+		JOIN product p ON pi.product_id = p.id
+		WHERE pi.supplement_id = ANY($1)
+		  AND (p.user_id IS NULL OR p.user_id = $2)
+		ORDER BY pi.supplement_id, p.user_id IS NULL, p.created_at, p.id
+	`
+
+	rows, err := h.pool.Query(ctx, query, supplementIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	servings := make(productServings)
+	for rows.Next() {
+		var (
+			supplementID string
+			serving      productServing
+			amount       float32
+		)
+		if err := rows.Scan(
+			&supplementID, &serving.Product.ID, &serving.Product.Name, &serving.Product.Brand,
+			&serving.ServingUnit, &amount, &serving.Serving.Unit,
+		); err != nil {
+			return nil, err
+		}
+		serving.Serving.Amount = realToFloat64(amount)
+		servings[supplementID] = append(servings[supplementID], serving)
+	}
+
+	return servings, rows.Err()
+}
+
+// hasServingUnits reports whether any dosage is in a serving unit.
+func hasServingUnits(dosages []models.DosageInput) bool {
+	for _, d := range dosages {
+		if d.Unit.IsServing() {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve returns the product serving for a serving-unit dosage: the requested product
+// if it has that serving unit, otherwise the preferred product with that serving unit.
+func (p productServings) resolve(d models.DosageInput) (productServing, bool) {
+	for _, serving := range p[d.SupplementID] {
+		if serving.ServingUnit != d.Unit {
+			continue
+		}
+		if d.ProductID == nil || *d.ProductID == serving.Product.ID {
+			return serving, true
+		}
+	}
+	return productServing{}, false
+}
+
+// dosageConversion returns the conversion factors of a dosage: its supplement's, plus the
+// serving size of the resolved product for serving units.
+func dosageConversion(d models.DosageInput, s models.Supplement, servings productServings) (DosageConversion, *models.ProductInfo) {
+	conversion := getDosageConversion(s)
+	if !d.Unit.IsServing() {
+		return conversion, nil
+	}
+
+	serving, ok := servings.resolve(d)
+	if !ok {
+		return conversion, nil
+	}
+	conversion.Serving = &serving.Serving
+	return conversion, &serving.Product
+}
+
+// buildResolvedDosages echoes each dosage with the product used for it and its mass in mg.
+func buildResolvedDosages(dosages []models.DosageInput, supplements map[string]models.Supplement, servings productServings) []models.ResolvedDosage {
+	resolved := make([]models.ResolvedDosage, 0, len(dosages))
+	for _, d := range dosages {
+		conversion, product := dosageConversion(d, supplements[d.SupplementID], servings)
+		entry := models.ResolvedDosage{
+			SupplementID: d.SupplementID,
+			Amount:       d.Amount,
+			Unit:         d.Unit,
+			Product:      product,
+		}
+		if mg, err := conversion.ToMilligrams(d.Amount, d.Unit); err == nil {
+			rounded := RoundToDecimal(mg, 3)
+			entry.AmountMg = &rounded
+		}
+		resolved = append(resolved, entry)
+	}
+	return resolved
+}
+
+// getProductIngredients loads the ingredients of the given products that the user can
+// see, keyed by product ID.
+func (h *Handler) getProductIngredients(ctx context.Context, userID string, productIDs []string) (map[string][]productIngredient, error) {
+	query := `
+		SELECT p.id, p.name, p.brand, p.serving_unit, pi.supplement_id, pi.amount, pi.unit
+		FROM product p
+		JOIN product_ingredient pi ON pi.product_id = p.id
+		WHERE p.id = ANY($1)
+		  AND (p.user_id IS NULL OR p.user_id = $2)
+		ORDER BY p.id, pi.supplement_id
+	`
+
+	rows, err := h.pool.Query(ctx, query, productIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ingredients := make(map[string][]productIngredient)
+	for rows.Next() {
+		var (
+			ingredient productIngredient
+			amount     float32
+		)
+		if err := rows.Scan(
+			&ingredient.Product.ID, &ingredient.Product.Name, &ingredient.Product.Brand, &ingredient.ServingUnit,
+			&ingredient.SupplementID, &amount, &ingredient.Serving.Unit,
+		); err != nil {
+			return nil, err
+		}
+		ingredient.Serving.Amount = realToFloat64(amount)
+		ingredients[ingredient.Product.ID] = append(ingredients[ingredient.Product.ID], ingredient)
+	}
+
+	return ingredients, rows.Err()
+}
+
+// productIDs returns the product IDs of the product dosages.
+func productIDs(products []models.ProductDosageInput) []string {
+	ids := make([]string, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ProductID)
+	}
+	return ids
+}
+
+// expandProducts replaces the request's products with their ingredients: each ingredient's
+// supplement is added to the analyzed supplements, with a dosage of the product's servings
+// in its serving unit. Those dosages resolve through the product like any serving-unit
+// dosage, so ratio and interaction checks see a multivitamin's zinc and copper as if they
+// were logged separately. Products the user cannot see are dropped.
+func expandProducts(req models.AnalyzeRequest, ingredients map[string][]productIngredient) models.AnalyzeRequest {
+	expanded := req
+	expanded.SupplementIDs = append([]string(nil), req.SupplementIDs...)
+	expanded.Dosages = append([]models.DosageInput(nil), req.Dosages...)
+	expanded.Products = nil
+
+	seen := make(map[string]struct{}, len(req.SupplementIDs))
+	for _, id := range req.SupplementIDs {
+		seen[id] = struct{}{}
+	}
+
+	for _, p := range req.Products {
+		for _, ingredient := range ingredients[p.ProductID] {
+			if _, ok := seen[ingredient.SupplementID]; !ok {
+				seen[ingredient.SupplementID] = struct{}{}
+				expanded.SupplementIDs = append(expanded.SupplementIDs, ingredient.SupplementID)
+			}
+
+			productID := p.ProductID
+			expanded.Dosages = append(expanded.Dosages, models.DosageInput{
+				SupplementID: ingredient.SupplementID,
+				Amount:       p.Servings,
+				Unit:         ingredient.ServingUnit,
+				ProductID:    &productID,
+			})
+		}
+	}
+
+	return expanded
+}
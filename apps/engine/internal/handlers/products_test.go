@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+)
+
+func testProductServings() productServings {
+	return productServings{
+		"supp-d3": {
+			{Product: models.ProductInfo{ID: "prod-user-d3", Name: "My D3"}, ServingUnit: models.DosageUnitCapsule, Serving: Serving{Amount: 2000, Unit: models.DosageUnitIU}},
+			{Product: models.ProductInfo{ID: "prod-d3-drops", Name: "D3 Drops"}, ServingUnit: models.DosageUnitServing, Serving: Serving{Amount: 4, Unit: models.DosageUnitDrops}},
+			{Product: models.ProductInfo{ID: "prod-d3-5000", Name: "D3 5000"}, ServingUnit: models.DosageUnitCapsule, Serving: Serving{Amount: 5000, Unit: models.DosageUnitIU}},
+		},
+	}
+}
+
+func TestProductServingsResolve(t *testing.T) {
+	servings := testProductServings()
+
+	serving, ok := servings.resolve(models.DosageInput{SupplementID: "supp-d3", Amount: 1, Unit: models.DosageUnitCapsule})
+	if !ok || serving.Product.ID != "prod-user-d3" {
+		t.Fatalf("expected the first capsule product, got %+v (ok=%v)", serving.Product, ok)
+	}
+
+	productID := "prod-d3-5000"
+	serving, ok = servings.resolve(models.DosageInput{SupplementID: "supp-d3", Amount: 1, Unit: models.DosageUnitCapsule, ProductID: &productID})
+	if !ok || serving.Product.ID != productID {
+		t.Fatalf("expected the requested product, got %+v (ok=%v)", serving.Product, ok)
+	}
+
+	// The requested product is only used for its own serving unit
+	if _, ok := servings.resolve(models.DosageInput{SupplementID: "supp-d3", Amount: 1, Unit: models.DosageUnitTablet, ProductID: &productID}); ok {
+		t.Fatalf("expected no product for a serving unit the product does not use")
+	}
+	if _, ok := servings.resolve(models.DosageInput{SupplementID: "supp-mg", Amount: 1, Unit: models.DosageUnitCapsule}); ok {
+		t.Fatalf("expected no product for a supplement without products")
+	}
+}
+
+func TestBuildResolvedDosages(t *testing.T) {
+	mcgPerIU := float64(0.025)
+	concentration := float64(0.125)
+	supplements := map[string]models.Supplement{
+		"supp-d3": {ID: "supp-d3", Name: "Vitamin D3", McgPerIU: &mcgPerIU, ConcentrationMgPerMl: &concentration},
+		"supp-mg": {ID: "supp-mg", Name: "Magnesium"},
+	}
+	productID := "prod-d3-5000"
+	dosages := []models.DosageInput{
+		{SupplementID: "supp-d3", Amount: 2, Unit: models.DosageUnitCapsule, ProductID: &productID},
+		{SupplementID: "supp-d3", Amount: 1, Unit: models.DosageUnitServing},
+		{SupplementID: "supp-mg", Amount: 400, Unit: models.DosageUnitMg},
+		{SupplementID: "supp-mg", Amount: 1, Unit: models.DosageUnitScoop},
+	}
+
+	resolved := buildResolvedDosages(dosages, supplements, testProductServings())
+	if len(resolved) != len(dosages) {
+		t.Fatalf("expected every dosage to be echoed, got %d", len(resolved))
+	}
+
+	// 2 capsules * 5000 IU * 0.025 mcg = 250 mcg
+	if resolved[0].Product == nil || resolved[0].Product.ID != productID {
+		t.Fatalf("expected product %s, got %+v", productID, resolved[0].Product)
+	}
+	if resolved[0].AmountMg == nil || !almostEqual(*resolved[0].AmountMg, 0.25, 0.0001) {
+		t.Fatalf("expected 0.25 mg, got %v", resolved[0].AmountMg)
+	}
+
+	// 4 drops / 20 drops/ml * 0.125 mg/ml = 0.025 mg
+	if resolved[1].AmountMg == nil || !almostEqual(*resolved[1].AmountMg, 0.025, 0.0001) {
+		t.Fatalf("expected 0.025 mg, got %v", resolved[1].AmountMg)
+	}
+
+	if resolved[2].Product != nil || resolved[2].AmountMg == nil || *resolved[2].AmountMg != 400 {
+		t.Fatalf("expected 400 mg without a product, got %+v", resolved[2])
+	}
+
+	if resolved[3].Product != nil || resolved[3].AmountMg != nil {
+		t.Fatalf("expected an unresolved scoop dosage, got %+v", resolved[3])
+	}
+}
+
+func TestExpandProducts(t *testing.T) {
+	multi := models.ProductInfo{ID: "prod-multi", Name: "Daily Multi"}
+	ingredients := map[string][]productIngredient{
+		"prod-multi": {
+			{SupplementID: "supp-zn", productServing: productServing{Product: multi, ServingUnit: models.DosageUnitTablet, Serving: Serving{Amount: 15, Unit: models.DosageUnitMg}}},
+			{SupplementID: "supp-cu", productServing: productServing{Product: multi, ServingUnit: models.DosageUnitTablet, Serving: Serving{Amount: 1, Unit: models.DosageUnitMg}}},
+		},
+	}
+	req := models.AnalyzeRequest{
+		SupplementIDs: []string{"supp-zn", "supp-mg"},
+		Dosages:       []models.DosageInput{{SupplementID: "supp-mg", Amount: 400, Unit: models.DosageUnitMg}},
+		Products: []models.ProductDosageInput{
+			{ProductID: "prod-multi", Servings: 2},
+			{ProductID: "prod-hidden", Servings: 1},
+		},
+	}
+
+	expanded := expandProducts(req, ingredients)
+
+	wantIDs := []string{"supp-zn", "supp-mg", "supp-cu"}
+	if len(expanded.SupplementIDs) != len(wantIDs) {
+		t.Fatalf("expected supplements %v, got %v", wantIDs, expanded.SupplementIDs)
+	}
+	for i, id := range wantIDs {
+		if expanded.SupplementIDs[i] != id {
+			t.Fatalf("expected supplements %v, got %v", wantIDs, expanded.SupplementIDs)
+		}
+	}
+	if len(expanded.Products) != 0 || len(req.Dosages) != 1 {
+		t.Fatalf("expected products replaced without modifying the request")
+	}
+	if len(expanded.Dosages) != 3 {
+		t.Fatalf("expected the dosage and two ingredients, got %+v", expanded.Dosages)
+	}
+
+	// The ingredient dosages resolve through the product's serving sizes
+	servings := productServings{}
+	for _, ingredient := range ingredients["prod-multi"] {
+		servings[ingredient.SupplementID] = append(servings[ingredient.SupplementID], ingredient.productServing)
+	}
+	supplements := map[string]models.Supplement{"supp-zn": {ID: "supp-zn"}, "supp-cu": {ID: "supp-cu"}}
+
+	zinc, copper := expanded.Dosages[1], expanded.Dosages[2]
+	zincConversion, product := dosageConversion(zinc, supplements["supp-zn"], servings)
+	if product == nil || product.ID != "prod-multi" || zinc.Unit != models.DosageUnitTablet || zinc.Amount != 2 {
+		t.Fatalf("expected 2 tablets of the multi, got %+v (product %v)", zinc, product)
+	}
+	copperConversion, _ := dosageConversion(copper, supplements["supp-cu"], servings)
+
+	ratio, err := CalculateRatio(
+		DosageInput{Amount: zinc.Amount, Unit: zinc.Unit, ElementalWeightPercent: 100, DosageConversion: zincConversion},
+		DosageInput{Amount: copper.Amount, Unit: copper.Unit, ElementalWeightPercent: 100, DosageConversion: copperConversion},
+	)
+	if err != nil {
+		t.Fatalf("CalculateRatio() error = %v", err)
+	}
+	if !almostEqual(ratio, 15, 0.001) {
+		t.Fatalf("expected a 15:1 Zn:Cu ratio, got %v", ratio)
+	}
+}
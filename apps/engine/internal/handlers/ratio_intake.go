@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+)
+
+// Longest rolling window of logged intake that ratio rules can be evaluated over
+const maxIntakeWindowHours = 7 * 24
+
+func isValidRatioIntake(intake models.RatioIntake) bool {
+	switch intake {
+	case "", models.RatioIntakeDosages, models.RatioIntakeLogged:
+		return true
+	default:
+		return false
+	}
+}
+
+// intakeWindow returns the period of logged intake ending at now: the last windowHours,
+// or since midnight in now's location when windowHours is zero.
+func intakeWindow(now time.Time, windowHours float64) models.IntakeWindow {
+	if windowHours > 0 {
+		return models.IntakeWindow{
+			From: now.Add(-time.Duration(windowHours * float64(time.Hour))),
+			To:   now,
+		}
+	}
+	return models.IntakeWindow{
+		From: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()),
+		To:   now,
+	}
+}
+
+// loggedRatioIntake returns the user's logged dosages in the window followed by the
+// planned dosages, with the supplements and product servings needed to convert them.
+func (h *Handler) loggedRatioIntake(ctx context.Context, userID string, window models.IntakeWindow, planned []models.DosageInput) ([]models.DosageInput, map[string]models.Supplement, productServings, error) {
+	dosages, err := h.getLoggedDosages(ctx, userID, window)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	dosages = append(dosages, planned...)
+
+	supplementIDs := make([]string, 0, len(dosages))
+	for _, d := range dosages {
+		supplementIDs = append(supplementIDs, d.SupplementID)
+	}
+
+	supplements, err := h.getSupplements(ctx, supplementIDs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Logged serving units resolve through the user's products, then the catalog's
+	var servings productServings
+	if hasServingUnits(dosages) {
+		if servings, err = h.getProductServings(ctx, userID, supplementIDs); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	return dosages, supplements, servings, nil
+}
+
+// getLoggedDosages loads the user's logged dosages in the window.
+func (h *Handler) getLoggedDosages(ctx context.Context, userID string, window models.IntakeWindow) ([]models.DosageInput, error) {
+	query := `
+		SELECT supplement_id, dosage, unit
+		FROM log
+		WHERE user_id = $1
+		  AND logged_at >= $2
+		  AND logged_at <= $3
+		ORDER BY logged_at
+	`
+
+	rows, err := h.pool.Query(ctx, query, userID, window.From, window.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dosages []models.DosageInput
+	for rows.Next() {
+		var (
+			d      models.DosageInput
+			dosage float32
+		)
+		if err := rows.Scan(&d.SupplementID, &dosage, &d.Unit); err != nil {
+			return nil, err
+		}
+		d.Amount = realToFloat64(dosage)
+		dosages = append(dosages, d)
+	}
+
+	return dosages, rows.Err()
+}
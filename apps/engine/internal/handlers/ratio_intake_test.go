@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIntakeWindow(t *testing.T) {
+	loc, err := time.LoadLocation("America/Toronto")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	now := time.Date(2026, 3, 10, 8, 30, 0, 0, loc)
+
+	day := intakeWindow(now, 0)
+	if want := time.Date(2026, 3, 10, 0, 0, 0, 0, loc); !day.From.Equal(want) || !day.To.Equal(now) {
+		t.Fatalf("expected the window to start at local midnight %v, got %+v", want, day)
+	}
+
+	rolling := intakeWindow(now, 24)
+	if want := now.Add(-24 * time.Hour); !rolling.From.Equal(want) || !rolling.To.Equal(now) {
+		t.Fatalf("expected a 24h rolling window from %v, got %+v", want, rolling)
+	}
+}
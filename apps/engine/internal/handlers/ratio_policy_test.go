@@ -1,12 +1,13 @@
 package handlers
 
 import (
+	"math"
 	"testing"
 
 	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
 )
 
-func ratioPtr(value float32) *float32 {
+func ratioPtr(value float64) *float64 {
 	return &value
 }
 
@@ -55,3 +56,219 @@ func TestBuildRatioEvaluationGap(t *testing.T) {
 		t.Fatalf("expected reason to be missing_dosage, got %s", gap.Reason)
 	}
 }
+
+func TestBuildRatioWarnings_SumsRepeatedDosages(t *testing.T) {
+	rules := []ratioRuleRecord{
+		{
+			ID:                 "rule-zn-cu",
+			SourceSupplementID: "supp-zn",
+			TargetSupplementID: "supp-cu",
+			MinRatio:           ratioPtr(8),
+			MaxRatio:           ratioPtr(15),
+			OptimalRatio:       ratioPtr(10),
+			Severity:           models.SeverityMedium,
+			SourceName:         "Zinc",
+			TargetName:         "Copper",
+		},
+	}
+	supplements := map[string]models.Supplement{
+		"supp-zn": {ID: "supp-zn", Name: "Zinc"},
+		"supp-cu": {ID: "supp-cu", Name: "Copper"},
+	}
+	// 25mg of zinc twice is 50mg, 25:1 against copper; counting one entry gives 12.5:1
+	dosages := []models.DosageInput{
+		{SupplementID: "supp-zn", Amount: 25, Unit: models.DosageUnitMg},
+		{SupplementID: "supp-cu", Amount: 2000, Unit: models.DosageUnitMcg},
+		{SupplementID: "supp-zn", Amount: 0.025, Unit: models.DosageUnitG},
+	}
+
+	warnings, gaps := buildRatioWarnings(rules, dosages, supplements, nil)
+
+	if len(gaps) != 0 {
+		t.Fatalf("expected no gaps, got %+v", gaps)
+	}
+	if len(warnings) != 1 || warnings[0].CurrentRatio != 25 {
+		t.Fatalf("expected one warning at 25:1, got %+v", warnings)
+	}
+	warning := warnings[0]
+	if warning.Deviation != 10 || warning.DistanceToOptimal == nil || *warning.DistanceToOptimal != 15 {
+		t.Fatalf("expected deviation 10 and distance to optimal 15, got %+v", warning)
+	}
+	if warning.Tolerance != defaultRatioTolerance {
+		t.Fatalf("expected the default tolerance, got %v", warning.Tolerance)
+	}
+	// 25:1 is 1.67 times the maximum
+	if warning.Severity != models.SeverityCritical {
+		t.Fatalf("expected medium to escalate to critical, got %s", warning.Severity)
+	}
+}
+
+func TestBuildRatioWarnings_PerRuleTolerance(t *testing.T) {
+	supplements := map[string]models.Supplement{
+		"supp-a": {ID: "supp-a"},
+		"supp-b": {ID: "supp-b"},
+	}
+	dosages := []models.DosageInput{
+		{SupplementID: "supp-a", Amount: 16, Unit: models.DosageUnitMg},
+		{SupplementID: "supp-b", Amount: 1, Unit: models.DosageUnitMg},
+	}
+
+	tests := []struct {
+		name      string
+		tolerance *float64
+		wantWarn  bool
+	}{
+		{"default tolerance", nil, false},
+		{"strict rule", ratioPtr(0), true},
+		{"loose rule", ratioPtr(0.5), false},
+		{"narrow rule", ratioPtr(0.05), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := ratioRuleRecord{
+				ID:                 "rule",
+				SourceSupplementID: "supp-a",
+				TargetSupplementID: "supp-b",
+				MaxRatio:           ratioPtr(15),
+				Tolerance:          tt.tolerance,
+				Severity:           models.SeverityLow,
+			}
+
+			warnings, _ := buildRatioWarnings([]ratioRuleRecord{rule}, dosages, supplements, nil)
+
+			if got := len(warnings) == 1; got != tt.wantWarn {
+				t.Fatalf("expected warning %v for 16:1 against a maximum of 15, got %+v", tt.wantWarn, warnings)
+			}
+			if tt.wantWarn {
+				if warnings[0].Severity != models.SeverityLow || warnings[0].Deviation != 1 || warnings[0].Tolerance != *tt.tolerance {
+					t.Fatalf("expected a low warning 1 above the maximum, got %+v", warnings[0])
+				}
+				if warnings[0].DistanceToOptimal != nil {
+					t.Fatalf("expected no distance without an optimal ratio, got %v", *warnings[0].DistanceToOptimal)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildRatioWarnings_OutOfRangeToleranceUsesDefault(t *testing.T) {
+	supplements := map[string]models.Supplement{
+		"supp-a": {ID: "supp-a"},
+		"supp-b": {ID: "supp-b"},
+	}
+	// 18:1 is beyond a maximum of 15 widened by the default 15%, but not by 100% or more
+	dosages := []models.DosageInput{
+		{SupplementID: "supp-a", Amount: 18, Unit: models.DosageUnitMg},
+		{SupplementID: "supp-b", Amount: 1, Unit: models.DosageUnitMg},
+	}
+
+	for _, tolerance := range []float64{-0.2, 1, 1.5, math.NaN()} {
+		rule := ratioRuleRecord{
+			ID:                 "rule",
+			SourceSupplementID: "supp-a",
+			TargetSupplementID: "supp-b",
+			MinRatio:           ratioPtr(8),
+			MaxRatio:           ratioPtr(15),
+			Tolerance:          ratioPtr(tolerance),
+			Severity:           models.SeverityLow,
+		}
+
+		warnings, _ := buildRatioWarnings([]ratioRuleRecord{rule}, dosages, supplements, nil)
+
+		if len(warnings) != 1 || warnings[0].Tolerance != defaultRatioTolerance {
+			t.Fatalf("tolerance %v: expected one warning at the default tolerance, got %+v", tolerance, warnings)
+		}
+	}
+}
+
+func TestGradeRatioSeverity(t *testing.T) {
+	tests := []struct {
+		name      string
+		severity  models.Severity
+		ratio     float64
+		deviation float64
+		want      models.Severity
+	}{
+		{"just above max", models.SeverityLow, 18, 3, models.SeverityLow},              // 1.2x max 15
+		{"well above max", models.SeverityLow, 24, 9, models.SeverityMedium},           // 1.6x
+		{"double max", models.SeverityLow, 30, 15, models.SeverityCritical},            // 2x
+		{"just below min", models.SeverityMedium, 6.5, -1.5, models.SeverityMedium},    // min 8 is 1.23x
+		{"well below min", models.SeverityMedium, 5, -3, models.SeverityCritical},      // 1.6x
+		{"half of min", models.SeverityLow, 4, -4, models.SeverityCritical},            // 2x
+		{"no source", models.SeverityLow, 0, -8, models.SeverityCritical},              // infinitely far
+		{"already critical", models.SeverityCritical, 30, 15, models.SeverityCritical}, // capped
+		{"compliant", models.SeverityMedium, 10, 0, models.SeverityMedium},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gradeRatioSeverity(tt.severity, tt.ratio, tt.deviation); got != tt.want {
+				t.Errorf("gradeRatioSeverity(%s, %v, %v) = %s, want %s", tt.severity, tt.ratio, tt.deviation, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildRatioWarnings_Gaps(t *testing.T) {
+	rule := ratioRuleRecord{ID: "rule", SourceSupplementID: "supp-a", TargetSupplementID: "supp-b", MaxRatio: ratioPtr(2)}
+	supplements := map[string]models.Supplement{
+		"supp-a": {ID: "supp-a"},
+		"supp-b": {ID: "supp-b"},
+	}
+
+	tests := []struct {
+		name        string
+		dosages     []models.DosageInput
+		supplements map[string]models.Supplement
+		want        models.RatioGapReason
+	}{
+		{
+			name:        "missing dosage",
+			dosages:     []models.DosageInput{{SupplementID: "supp-a", Amount: 1, Unit: models.DosageUnitMg}},
+			supplements: supplements,
+			want:        models.RatioGapMissingDosage,
+		},
+		{
+			name: "missing supplement data",
+			dosages: []models.DosageInput{
+				{SupplementID: "supp-a", Amount: 1, Unit: models.DosageUnitMg},
+				{SupplementID: "supp-b", Amount: 1, Unit: models.DosageUnitMg},
+			},
+			supplements: map[string]models.Supplement{"supp-a": {ID: "supp-a"}},
+			want:        models.RatioGapMissingSupplementData,
+		},
+		{
+			// One unconvertible entry leaves the total incomplete
+			name: "normalization failed",
+			dosages: []models.DosageInput{
+				{SupplementID: "supp-a", Amount: 1, Unit: models.DosageUnitMg},
+				{SupplementID: "supp-b", Amount: 1, Unit: models.DosageUnitMg},
+				{SupplementID: "supp-b", Amount: 1000, Unit: models.DosageUnitIU},
+			},
+			supplements: supplements,
+			want:        models.RatioGapNormalizationFailed,
+		},
+		{
+			name: "zero target",
+			dosages: []models.DosageInput{
+				{SupplementID: "supp-a", Amount: 1, Unit: models.DosageUnitMg},
+				{SupplementID: "supp-b", Amount: 0, Unit: models.DosageUnitMg},
+			},
+			supplements: supplements,
+			want:        models.RatioGapNormalizationFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, gaps := buildRatioWarnings([]ratioRuleRecord{rule}, tt.dosages, tt.supplements, nil)
+			if len(warnings) != 0 {
+				t.Fatalf("expected no warnings, got %+v", warnings)
+			}
+			if len(gaps) != 1 || gaps[0].Reason != tt.want {
+				t.Fatalf("expected a %s gap, got %+v", tt.want, gaps)
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/auth"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/calibration"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/kinetics"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+)
+
+// Default protocol slot times in minutes after midnight, matching the protocol table defaults
+var defaultSlotMinutes = map[string]int{
+	"morning":   8 * 60,
+	"afternoon": 12 * 60,
+	"evening":   18 * 60,
+	"bedtime":   22 * 60,
+}
+
+// Protocol days of week, offset from Monday
+var dayOffsets = map[string]int{
+	"monday":    0,
+	"tuesday":   1,
+	"wednesday": 2,
+	"thursday":  3,
+	"friday":    4,
+	"saturday":  5,
+	"sunday":    6,
+}
+
+// protocolScheduleItem is a scheduled protocol item with its supplement's PK parameters.
+type protocolScheduleItem struct {
+	Supplement  models.SupplementInfo
+	DoseMg      float64
+	SlotMinutes int
+	Frequency   string
+	DaysOfWeek  []string
+	PK          kinetics.SupplementPK
+}
+
+// supplementSchedule is the combined repeating schedule of one supplement.
+type supplementSchedule struct {
+	Supplement models.SupplementInfo
+	Schedule   kinetics.DosingSchedule
+}
+
+// SteadyState handles the protocol steady-state analysis endpoint
+func (h *Handler) SteadyState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.SteadyStateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	userID, ok := auth.GetUserID(ctx)
+	if !ok || userID == "" {
+		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	calibrations, err := h.getLatestCalibrations(ctx, userID)
+	if err != nil {
+		http.Error(w, `{"error":"steady-state analysis failed"}`, http.StatusInternalServerError)
+		return
+	}
+
+	items, err := h.getProtocolScheduleItems(ctx, userID, calibrations)
+	if err != nil {
+		http.Error(w, `{"error":"steady-state analysis failed"}`, http.StatusInternalServerError)
+		return
+	}
+
+	response := models.SteadyStateResponse{
+		Supplements: analyzeProtocolSteadyState(filterScheduleItems(items, req.SupplementIDs)),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *Handler) getProtocolScheduleItems(ctx context.Context, userID string, calibrations []calibration.Record) ([]protocolScheduleItem, error) {
+	query := `
+		SELECT pi.supplement_id, pi.dosage, pi.unit, pi.time_slot, pi.frequency, pi.days_of_week,
+		       p.morning_time, p.afternoon_time, p.evening_time, p.bedtime_time,
+		       s.name, s.form, s.safety_category,` + supplementUnitColumns + `,` + supplementPKColumns + `
+		FROM protocol_item pi
+		JOIN protocol p ON pi.protocol_id = p.id
+		JOIN supplement s ON pi.supplement_id = s.id
+		WHERE p.user_id = $1
+		  AND pi.frequency <> 'as_needed'
+		ORDER BY pi.supplement_id, pi.time_slot, pi.sort_order
+	`
+
+	rows, err := h.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []protocolScheduleItem
+	for rows.Next() {
+		var (
+			item           protocolScheduleItem
+			dosage         float32
+			unit           models.DosageUnit
+			timeSlot       string
+			slotTimes      [4]string
+			safetyCategory *string
+			unitRecord     supplementUnitRecord
+			pkRecord       supplementPKRecord
+		)
+
+		targets := []any{
+			&item.Supplement.ID, &dosage, &unit, &timeSlot, &item.Frequency, &item.DaysOfWeek,
+			&slotTimes[0], &slotTimes[1], &slotTimes[2], &slotTimes[3],
+			&item.Supplement.Name, &item.Supplement.Form, &safetyCategory,
+		}
+		targets = append(targets, unitRecord.scanTargets()...)
+		if err := rows.Scan(append(targets, pkRecord.scanTargets()...)...); err != nil {
+			return nil, err
+		}
+
+		slotTimeBySlot := map[string]string{
+			"morning":   slotTimes[0],
+			"afternoon": slotTimes[1],
+			"evening":   slotTimes[2],
+			"bedtime":   slotTimes[3],
+		}
+		item.SlotMinutes = resolveSlotMinutes(timeSlot, slotTimeBySlot[timeSlot])
+		item.DoseMg = kineticsDoseMg(realToFloat64(dosage), unit, unitRecord.toConversion())
+		item.PK = pkRecord.toPK()
+		item.PK.Calibration = calibration.ForSupplement(calibrations, item.Supplement.Name, stringOrEmpty(safetyCategory))
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// resolveSlotMinutes parses a protocol slot time ("HH:MM") into minutes after midnight,
+// falling back to the slot's default time when it is missing or malformed.
+func resolveSlotMinutes(timeSlot string, slotTime string) int {
+	if minutes, ok := parseClockMinutes(slotTime); ok {
+		return minutes
+	}
+	return defaultSlotMinutes[timeSlot]
+}
+
+// parseClockMinutes parses "HH:MM" into minutes after midnight.
+func parseClockMinutes(clock string) (int, bool) {
+	hours, minutes, found := strings.Cut(clock, ":")
+	if !found {
+		return 0, false
+	}
+	h, errH := strconv.Atoi(hours)
+	m, errM := strconv.Atoi(minutes)
+	if errH != nil || errM != nil || h < 0 || h >= 24 || m < 0 || m >= 60 {
+		return 0, false
+	}
+	return h*60 + m, true
+}
+
+func filterScheduleItems(items []protocolScheduleItem, supplementIDs []string) []protocolScheduleItem {
+	if len(supplementIDs) == 0 {
+		return items
+	}
+
+	allowed := make(map[string]struct{}, len(supplementIDs))
+	for _, id := range supplementIDs {
+		allowed[id] = struct{}{}
+	}
+
+	filtered := make([]protocolScheduleItem, 0, len(items))
+	for _, item := range items {
+		if _, ok := allowed[item.Supplement.ID]; ok {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+// buildDosingSchedules combines protocol items into one repeating schedule per supplement.
+// Daily items repeat every day; if any item of a supplement is day-specific, its schedule
+// repeats weekly (starting Monday 00:00) and daily items expand to seven doses.
+func buildDosingSchedules(items []protocolScheduleItem) []supplementSchedule {
+	bySupplement := make(map[string][]protocolScheduleItem)
+	order := make([]string, 0)
+	for _, item := range items {
+		if _, exists := bySupplement[item.Supplement.ID]; !exists {
+			order = append(order, item.Supplement.ID)
+		}
+		bySupplement[item.Supplement.ID] = append(bySupplement[item.Supplement.ID], item)
+	}
+	sort.Strings(order)
+
+	schedules := make([]supplementSchedule, 0, len(order))
+	for _, supplementID := range order {
+		supplementItems := bySupplement[supplementID]
+
+		weekly := false
+		for _, item := range supplementItems {
+			if item.Frequency == "specific_days" {
+				weekly = true
+			}
+		}
+
+		schedule := kinetics.DosingSchedule{
+			PeriodMinutes: kinetics.MinutesPerDay,
+			PK:            supplementItems[0].PK,
+		}
+		if weekly {
+			schedule.PeriodMinutes = kinetics.MinutesPerWeek
+		}
+
+		for _, item := range supplementItems {
+			switch {
+			case item.Frequency == "specific_days":
+				for _, day := range item.DaysOfWeek {
+					offset, ok := dayOffsets[strings.ToLower(day)]
+					if !ok {
+						continue
+					}
+					schedule.Doses = append(schedule.Doses, kinetics.ScheduledDose{
+						Dose:      item.DoseMg,
+						AtMinutes: float64(offset*kinetics.MinutesPerDay + item.SlotMinutes),
+					})
+				}
+			case weekly:
+				for offset := 0; offset < 7; offset++ {
+					schedule.Doses = append(schedule.Doses, kinetics.ScheduledDose{
+						Dose:      item.DoseMg,
+						AtMinutes: float64(offset*kinetics.MinutesPerDay + item.SlotMinutes),
+					})
+				}
+			default:
+				schedule.Doses = append(schedule.Doses, kinetics.ScheduledDose{
+					Dose:      item.DoseMg,
+					AtMinutes: float64(item.SlotMinutes),
+				})
+			}
+		}
+
+		if len(schedule.Doses) == 0 {
+			continue
+		}
+
+		schedules = append(schedules, supplementSchedule{
+			Supplement: supplementItems[0].Supplement,
+			Schedule:   schedule,
+		})
+	}
+
+	return schedules
+}
+
+func analyzeProtocolSteadyState(items []protocolScheduleItem) []models.SupplementSteadyState {
+	schedules := buildDosingSchedules(items)
+
+	results := make([]models.SupplementSteadyState, 0, len(schedules))
+	for _, s := range schedules {
+		ss := kinetics.AnalyzeSteadyState(s.Schedule)
+
+		// Report the user's calibrated half-life
+		calibrated, _ := kinetics.ApplyCalibration(s.Schedule.PK)
+		halfLife := calibrated.HalfLifeMinutes
+		if halfLife <= 0 {
+			halfLife = kinetics.DefaultHalfLifeMinutes
+		}
+
+		dosesPerWeek := len(s.Schedule.Doses)
+		if s.Schedule.PeriodMinutes == kinetics.MinutesPerDay {
+			dosesPerWeek *= 7
+		}
+
+		results = append(results, models.SupplementSteadyState{
+			Supplement:             s.Supplement,
+			DosesPerWeek:           dosesPerWeek,
+			HalfLifeHours:          RoundToDecimal(halfLife/60, 1),
+			AccumulationRatio:      RoundToDecimal(ss.AccumulationRatio, 2),
+			TimeToSteadyStateHours: RoundToDecimal(ss.TimeToSteadyStateMinutes/60, 1),
+			DosesToSteadyState:     ss.DosesToSteadyState,
+			SteadyStatePeak:        RoundToDecimal(ss.PeakPercent, 1),
+			SteadyStateTrough:      RoundToDecimal(ss.TroughPercent, 1),
+			SteadyStateAverage:     RoundToDecimal(ss.AveragePercent, 1),
+		})
+	}
+
+	return results
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/kinetics"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+)
+
+func TestResolveSlotMinutes(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeSlot string
+		slotTime string
+		want     int
+	}{
+		{"configured time", "morning", "07:30", 450},
+		{"missing time uses default", "evening", "", 1080},
+		{"malformed time uses default", "bedtime", "25:00", 1320},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveSlotMinutes(tt.timeSlot, tt.slotTime); got != tt.want {
+				t.Fatalf("resolveSlotMinutes() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildDosingSchedules_Daily(t *testing.T) {
+	magnesium := models.SupplementInfo{ID: "supp-mg", Name: "Magnesium"}
+	items := []protocolScheduleItem{
+		{Supplement: magnesium, DoseMg: 200, SlotMinutes: 480, Frequency: "daily"},
+		{Supplement: magnesium, DoseMg: 200, SlotMinutes: 1320, Frequency: "daily"},
+	}
+
+	schedules := buildDosingSchedules(items)
+
+	if len(schedules) != 1 {
+		t.Fatalf("expected 1 schedule, got %d", len(schedules))
+	}
+	if schedules[0].Schedule.PeriodMinutes != kinetics.MinutesPerDay {
+		t.Fatalf("expected daily period, got %v", schedules[0].Schedule.PeriodMinutes)
+	}
+	if len(schedules[0].Schedule.Doses) != 2 {
+		t.Fatalf("expected 2 doses per day, got %d", len(schedules[0].Schedule.Doses))
+	}
+}
+
+func TestBuildDosingSchedules_SpecificDaysMakesWeeklySchedule(t *testing.T) {
+	peptide := models.SupplementInfo{ID: "supp-peptide", Name: "Semaglutide"}
+	items := []protocolScheduleItem{
+		{Supplement: peptide, DoseMg: 0.25, SlotMinutes: 480, Frequency: "specific_days", DaysOfWeek: []string{"Monday", "thursday", "someday"}},
+		{Supplement: peptide, DoseMg: 0.1, SlotMinutes: 1320, Frequency: "daily"},
+	}
+
+	schedules := buildDosingSchedules(items)
+
+	if len(schedules) != 1 {
+		t.Fatalf("expected 1 schedule, got %d", len(schedules))
+	}
+	schedule := schedules[0].Schedule
+	if schedule.PeriodMinutes != kinetics.MinutesPerWeek {
+		t.Fatalf("expected weekly period, got %v", schedule.PeriodMinutes)
+	}
+	// 2 valid specific days + 7 expanded daily doses
+	if len(schedule.Doses) != 9 {
+		t.Fatalf("expected 9 doses per week, got %d", len(schedule.Doses))
+	}
+	if schedule.Doses[1].AtMinutes != 3*kinetics.MinutesPerDay+480 {
+		t.Fatalf("expected Thursday dose at %v, got %v", 3*kinetics.MinutesPerDay+480, schedule.Doses[1].AtMinutes)
+	}
+}
+
+func TestAnalyzeProtocolSteadyState(t *testing.T) {
+	vitaminD := models.SupplementInfo{ID: "supp-d3", Name: "Vitamin D3"}
+	items := []protocolScheduleItem{
+		{
+			Supplement:  vitaminD,
+			DoseMg:      0.125,
+			SlotMinutes: 480,
+			Frequency:   "daily",
+			PK:          kinetics.SupplementPK{PeakMinutes: 720, HalfLifeMinutes: 21600},
+		},
+	}
+
+	results := analyzeProtocolSteadyState(filterScheduleItems(items, []string{"supp-d3"}))
+
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	if results[0].DosesPerWeek != 7 {
+		t.Fatalf("expected 7 doses per week, got %d", results[0].DosesPerWeek)
+	}
+	if results[0].AccumulationRatio < 20 {
+		t.Fatalf("expected vitamin D3 to accumulate >20x, got %v", results[0].AccumulationRatio)
+	}
+	if results[0].HalfLifeHours != 360 {
+		t.Fatalf("expected 360h half-life, got %v", results[0].HalfLifeHours)
+	}
+
+	if filtered := filterScheduleItems(items, []string{"supp-other"}); len(filtered) != 0 {
+		t.Fatalf("expected filter to exclude other supplements, got %d items", len(filtered))
+	}
+}
@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/auth"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/bioavailability"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/calibration"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/kinetics"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+)
+
+const (
+	defaultTimelineIntervalMinutes = 15
+	defaultTimelineLookback        = 24 * time.Hour
+	defaultTimelineProjection      = 4 * time.Hour
+	// Doses logged this long before the window still contribute to its first samples
+	timelineCarryOver = 24 * time.Hour
+	// Upper bound on samples per curve to keep responses bounded
+	maxTimelineSamples = 2881
+	// Combined concentration cap, matching the web timeline
+	maxTimelineConcentration = 150
+	// Fixed default seed so repeated requests return the same uncertainty bands
+	defaultUncertaintySeed = 1
+	maxUncertaintySamples  = 1000
+)
+
+// timelineDose is a logged dose with the PK parameters of its supplement.
+type timelineDose struct {
+	Supplement models.SupplementInfo
+	LoggedAt   time.Time
+	DoseMg     float64
+	Route      kinetics.Route
+	Meal       kinetics.MealEffect
+	PK         kinetics.SupplementPK
+}
+
+// Timeline handles the concentration timeline endpoint
+func (h *Handler) Timeline(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.TimelineRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	userID, ok := auth.GetUserID(ctx)
+	if !ok || userID == "" {
+		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	windowStart, windowEnd, interval, ok := resolveTimelineWindow(req, time.Now())
+	if !ok {
+		http.Error(w, `{"error":"invalid timeline window"}`, http.StatusBadRequest)
+		return
+	}
+
+	uncertainty, ok := resolveUncertaintyOptions(req)
+	if !ok {
+		http.Error(w, `{"error":"invalid uncertainty samples"}`, http.StatusBadRequest)
+		return
+	}
+
+	if req.BodyWeightKg < 0 {
+		http.Error(w, `{"error":"invalid bodyWeightKg"}`, http.StatusBadRequest)
+		return
+	}
+
+	calibrations, err := h.getLatestCalibrations(ctx, userID)
+	if err != nil {
+		http.Error(w, `{"error":"timeline failed"}`, http.StatusInternalServerError)
+		return
+	}
+
+	doses, err := h.getTimelineDoses(ctx, userID, calibrations, windowStart.Add(-timelineCarryOver), windowEnd)
+	if err != nil {
+		http.Error(w, `{"error":"timeline failed"}`, http.StatusInternalServerError)
+		return
+	}
+
+	response := models.TimelineResponse{
+		WindowStart:     windowStart,
+		WindowEnd:       windowEnd,
+		IntervalMinutes: int(interval / time.Minute),
+		Curves:          buildConcentrationCurves(doses, windowStart, windowEnd, interval, uncertainty, req.BodyWeightKg),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// resolveTimelineWindow applies defaults to the requested window and validates it.
+func resolveTimelineWindow(req models.TimelineRequest, now time.Time) (time.Time, time.Time, time.Duration, bool) {
+	windowStart := now.Add(-defaultTimelineLookback)
+	if req.WindowStart != nil {
+		windowStart = *req.WindowStart
+	}
+
+	windowEnd := now.Add(defaultTimelineProjection)
+	if req.WindowEnd != nil {
+		windowEnd = *req.WindowEnd
+	}
+
+	intervalMinutes := req.IntervalMinutes
+	if intervalMinutes == 0 {
+		intervalMinutes = defaultTimelineIntervalMinutes
+	}
+
+	if intervalMinutes < 0 || !windowEnd.After(windowStart) {
+		return time.Time{}, time.Time{}, 0, false
+	}
+
+	interval := time.Duration(intervalMinutes) * time.Minute
+	if windowEnd.Sub(windowStart)/interval >= maxTimelineSamples {
+		return time.Time{}, time.Time{}, 0, false
+	}
+
+	return windowStart, windowEnd, interval, true
+}
+
+// resolveUncertaintyOptions returns the Monte Carlo options for the request,
+// or nil when uncertainty bands were not requested.
+func resolveUncertaintyOptions(req models.TimelineRequest) (*kinetics.MonteCarloOptions, bool) {
+	if !req.Uncertainty {
+		return nil, true
+	}
+	if req.Samples < 0 || req.Samples > maxUncertaintySamples {
+		return nil, false
+	}
+
+	options := &kinetics.MonteCarloOptions{Samples: req.Samples, Seed: defaultUncertaintySeed}
+	if req.Seed != nil {
+		options.Seed = *req.Seed
+	}
+	return options, true
+}
+
+func (h *Handler) getTimelineDoses(ctx context.Context, userID string, calibrations []calibration.Record, from time.Time, to time.Time) ([]timelineDose, error) {
+	query := `
+		SELECT l.supplement_id, l.dosage, l.unit, l.route, l.meal_context, l.logged_at,
+		       s.name, s.form, s.safety_category,` + supplementUnitColumns + `,` + supplementPKColumns + `
+		FROM log l
+		JOIN supplement s ON l.supplement_id = s.id
+		WHERE l.user_id = $1
+		  AND l.logged_at >= $2
+		  AND l.logged_at <= $3
+		ORDER BY l.logged_at
+	`
+
+	rows, err := h.pool.Query(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var doses []timelineDose
+	for rows.Next() {
+		var (
+			dose           timelineDose
+			dosage         float32
+			unit           models.DosageUnit
+			route          *string
+			mealContext    *string
+			safetyCategory *string
+			unitRecord     supplementUnitRecord
+			pkRecord       supplementPKRecord
+		)
+
+		targets := []any{
+			&dose.Supplement.ID, &dosage, &unit, &route, &mealContext, &dose.LoggedAt,
+			&dose.Supplement.Name, &dose.Supplement.Form, &safetyCategory,
+		}
+		targets = append(targets, unitRecord.scanTargets()...)
+		if err := rows.Scan(append(targets, pkRecord.scanTargets()...)...); err != nil {
+			return nil, err
+		}
+
+		dose.DoseMg = kineticsDoseMg(realToFloat64(dosage), unit, unitRecord.toConversion())
+		dose.PK = pkRecord.toPK()
+		dose.PK.Calibration = calibration.ForSupplement(calibrations, dose.Supplement.Name, stringOrEmpty(safetyCategory))
+		if route != nil {
+			dose.Route = kinetics.Route(*route)
+		}
+		if mealContext != nil {
+			dose.Meal = bioavailability.MealEffect(dose.Supplement.Name, stringOrEmpty(safetyCategory), models.MealContext(*mealContext))
+		}
+		doses = append(doses, dose)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	pathways, err := h.getCYP450Pathways(ctx, timelineSupplementIDs(doses))
+	if err != nil {
+		return nil, err
+	}
+
+	return applyMetabolicInteractions(doses, pathways), nil
+}
+
+// buildConcentrationCurves samples the combined concentration of each supplement
+// across the window. Overlapping doses of the same supplement are summed.
+// When uncertainty is set, each sample also carries P5/P50/P95 bands. Samples include
+// mg/L concentrations when every dose's supplement has a volume of distribution.
+func buildConcentrationCurves(doses []timelineDose, windowStart time.Time, windowEnd time.Time, interval time.Duration, uncertainty *kinetics.MonteCarloOptions, bodyWeightKg float64) []models.ConcentrationCurve {
+	order, bySupplement := groupDosesBySupplement(doses, windowStart)
+
+	curves := make([]models.ConcentrationCurve, 0, len(order))
+	for _, supplementID := range order {
+		entry := bySupplement[supplementID]
+		curve := models.ConcentrationCurve{
+			Supplement: entry.info,
+			DoseCount:  len(entry.events),
+			Samples:    make([]models.ConcentrationSample, 0),
+		}
+
+		timestamps, times := sampleTimes(windowStart, windowEnd, interval)
+		concentrations := kinetics.CalculateMultiDoseConcentrationCurve(entry.events, times)
+		mgPerL, plasmaErr := kinetics.CalculateMultiDosePlasmaConcentrationCurve(entry.events, times, bodyWeightKg)
+
+		for i, minutes := range times {
+			sample := models.ConcentrationSample{
+				MinutesFromStart: minutes,
+				Timestamp:        timestamps[i],
+				Concentration:    math.Min(concentrations[i], maxTimelineConcentration),
+			}
+			if plasmaErr == nil {
+				sample.ConcentrationMgPerL = &mgPerL[i]
+			}
+			curve.Samples = append(curve.Samples, sample)
+		}
+
+		if uncertainty != nil {
+			for i, band := range kinetics.CalculateConcentrationBands(entry.events, times, *uncertainty) {
+				p5 := math.Min(band.P5, maxTimelineConcentration)
+				p50 := math.Min(band.P50, maxTimelineConcentration)
+				p95 := math.Min(band.P95, maxTimelineConcentration)
+				curve.Samples[i].P5, curve.Samples[i].P50, curve.Samples[i].P95 = &p5, &p50, &p95
+			}
+		}
+
+		curves = append(curves, curve)
+	}
+
+	return curves
+}
+
+// supplementDoses is the dose history of one supplement on a window's time axis.
+type supplementDoses struct {
+	info   models.SupplementInfo
+	events []kinetics.DoseEvent
+}
+
+// groupDosesBySupplement groups doses by supplement, with dose times in minutes from
+// windowStart. Supplement IDs are returned in sorted order.
+func groupDosesBySupplement(doses []timelineDose, windowStart time.Time) ([]string, map[string]*supplementDoses) {
+	bySupplement := make(map[string]*supplementDoses)
+	order := make([]string, 0)
+	for _, d := range doses {
+		entry, exists := bySupplement[d.Supplement.ID]
+		if !exists {
+			entry = &supplementDoses{info: d.Supplement}
+			bySupplement[d.Supplement.ID] = entry
+			order = append(order, d.Supplement.ID)
+		}
+		entry.events = append(entry.events, kinetics.DoseEvent{
+			Dose:      d.DoseMg,
+			AtMinutes: d.LoggedAt.Sub(windowStart).Minutes(),
+			PK:        d.PK,
+			Route:     d.Route,
+			Meal:      d.Meal,
+		})
+	}
+	sort.Strings(order)
+
+	return order, bySupplement
+}
+
+// sampleTimes returns the sample timestamps across the window and their minutes from windowStart.
+func sampleTimes(windowStart time.Time, windowEnd time.Time, interval time.Duration) ([]time.Time, []float64) {
+	var (
+		timestamps []time.Time
+		minutes    []float64
+	)
+	for at := windowStart; !at.After(windowEnd); at = at.Add(interval) {
+		timestamps = append(timestamps, at)
+		minutes = append(minutes, at.Sub(windowStart).Minutes())
+	}
+	return timestamps, minutes
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/kinetics"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+)
+
+func TestBuildConcentrationCurves_SumsOverlappingDoses(t *testing.T) {
+	windowStart := time.Date(2026, 2, 28, 8, 0, 0, 0, time.UTC)
+	windowEnd := windowStart.Add(6 * time.Hour)
+	pk := kinetics.SupplementPK{
+		KineticsType:    kinetics.FirstOrder,
+		PeakMinutes:     60,
+		HalfLifeMinutes: 240,
+	}
+	caffeine := models.SupplementInfo{ID: "supp-caffeine", Name: "Caffeine"}
+
+	doses := []timelineDose{
+		{Supplement: caffeine, LoggedAt: windowStart, DoseMg: 100, PK: pk},
+		{Supplement: caffeine, LoggedAt: windowStart.Add(2 * time.Hour), DoseMg: 100, PK: pk},
+	}
+
+	curves := buildConcentrationCurves(doses, windowStart, windowEnd, 15*time.Minute, nil, 0)
+
+	if len(curves) != 1 {
+		t.Fatalf("expected 1 curve, got %d", len(curves))
+	}
+	if curves[0].DoseCount != 2 {
+		t.Fatalf("expected 2 doses, got %d", curves[0].DoseCount)
+	}
+	if len(curves[0].Samples) != 25 {
+		t.Fatalf("expected 25 samples, got %d", len(curves[0].Samples))
+	}
+
+	// At 4h the first dose is 180 min past peak and the second 60 min past peak
+	sample := curves[0].Samples[16]
+	expected := 100*math.Pow(2, -180.0/240.0) + 100*math.Pow(2, -60.0/240.0)
+	if sample.MinutesFromStart != 240 {
+		t.Fatalf("expected sample at 240 minutes, got %v", sample.MinutesFromStart)
+	}
+	if !almostEqual(sample.Concentration, expected, 0.01) {
+		t.Fatalf("expected summed concentration %v, got %v", expected, sample.Concentration)
+	}
+}
+
+func TestBuildConcentrationCurves_CapsCombinedConcentration(t *testing.T) {
+	windowStart := time.Date(2026, 2, 28, 8, 0, 0, 0, time.UTC)
+	pk := kinetics.SupplementPK{PeakMinutes: 60, HalfLifeMinutes: 600}
+	zinc := models.SupplementInfo{ID: "supp-zinc", Name: "Zinc"}
+
+	doses := []timelineDose{
+		{Supplement: zinc, LoggedAt: windowStart, DoseMg: 15, PK: pk},
+		{Supplement: zinc, LoggedAt: windowStart, DoseMg: 15, PK: pk},
+	}
+
+	curves := buildConcentrationCurves(doses, windowStart, windowStart.Add(time.Hour), time.Hour, nil, 0)
+
+	peak := curves[0].Samples[1].Concentration
+	if peak != maxTimelineConcentration {
+		t.Fatalf("expected concentration capped at %v, got %v", maxTimelineConcentration, peak)
+	}
+}
+
+func TestResolveTimelineWindow_Defaults(t *testing.T) {
+	now := time.Date(2026, 2, 28, 12, 0, 0, 0, time.UTC)
+
+	windowStart, windowEnd, interval, ok := resolveTimelineWindow(models.TimelineRequest{}, now)
+
+	if !ok {
+		t.Fatalf("expected default window to be valid")
+	}
+	if !windowStart.Equal(now.Add(-24 * time.Hour)) {
+		t.Fatalf("expected window start 24h before now, got %v", windowStart)
+	}
+	if !windowEnd.Equal(now.Add(4 * time.Hour)) {
+		t.Fatalf("expected window end 4h after now, got %v", windowEnd)
+	}
+	if interval != 15*time.Minute {
+		t.Fatalf("expected 15 minute interval, got %v", interval)
+	}
+}
+
+func TestResolveTimelineWindow_RejectsInvalidWindows(t *testing.T) {
+	now := time.Date(2026, 2, 28, 12, 0, 0, 0, time.UTC)
+	earlier := now.Add(-time.Hour)
+	muchEarlier := now.Add(-30 * 24 * time.Hour)
+
+	tests := []struct {
+		name string
+		req  models.TimelineRequest
+	}{
+		{"end before start", models.TimelineRequest{WindowStart: &now, WindowEnd: &earlier}},
+		{"negative interval", models.TimelineRequest{IntervalMinutes: -5}},
+		{"too many samples", models.TimelineRequest{WindowStart: &muchEarlier, WindowEnd: &now, IntervalMinutes: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, _, ok := resolveTimelineWindow(tt.req, now); ok {
+				t.Fatalf("expected window to be rejected")
+			}
+		})
+	}
+}
+
+func TestBuildConcentrationCurves_UncertaintyBands(t *testing.T) {
+	windowStart := time.Date(2026, 2, 28, 8, 0, 0, 0, time.UTC)
+	pk := kinetics.SupplementPK{
+		KineticsType:    kinetics.FirstOrder,
+		PeakMinutes:     60,
+		HalfLifeMinutes: 240,
+		Variability:     kinetics.Variability{HalfLifeCV: 0.3, PeakCV: 0.3, BioavailabilityCV: 0.3},
+	}
+	theanine := models.SupplementInfo{ID: "supp-theanine", Name: "L-Theanine"}
+	doses := []timelineDose{{Supplement: theanine, LoggedAt: windowStart, DoseMg: 200, PK: pk}}
+
+	options, ok := resolveUncertaintyOptions(models.TimelineRequest{Uncertainty: true, Samples: 200})
+	if !ok || options.Seed != defaultUncertaintySeed {
+		t.Fatalf("expected default seed, got %+v", options)
+	}
+
+	curves := buildConcentrationCurves(doses, windowStart, windowStart.Add(4*time.Hour), time.Hour, options, 0)
+	repeated := buildConcentrationCurves(doses, windowStart, windowStart.Add(4*time.Hour), time.Hour, options, 0)
+
+	for i, sample := range curves[0].Samples {
+		if sample.P5 == nil || sample.P50 == nil || sample.P95 == nil {
+			t.Fatalf("sample %d missing bands", i)
+		}
+		if *sample.P5 > *sample.P50 || *sample.P50 > *sample.P95 {
+			t.Fatalf("sample %d bands out of order: %v, %v, %v", i, *sample.P5, *sample.P50, *sample.P95)
+		}
+		if *sample.P50 != *repeated[0].Samples[i].P50 {
+			t.Fatalf("sample %d not reproducible: %v vs %v", i, *sample.P50, *repeated[0].Samples[i].P50)
+		}
+	}
+}
+
+func TestResolveUncertaintyOptions(t *testing.T) {
+	if options, ok := resolveUncertaintyOptions(models.TimelineRequest{}); !ok || options != nil {
+		t.Fatalf("expected no bands by default, got %+v", options)
+	}
+
+	seed := int64(99)
+	options, ok := resolveUncertaintyOptions(models.TimelineRequest{Uncertainty: true, Seed: &seed})
+	if !ok || options.Seed != 99 {
+		t.Fatalf("expected seed 99, got %+v", options)
+	}
+
+	if _, ok := resolveUncertaintyOptions(models.TimelineRequest{Uncertainty: true, Samples: maxUncertaintySamples + 1}); ok {
+		t.Fatalf("expected too many samples to be rejected")
+	}
+}
+
+func TestBuildConcentrationCurves_AbsoluteConcentration(t *testing.T) {
+	windowStart := time.Date(2026, 2, 28, 8, 0, 0, 0, time.UTC)
+	pk := kinetics.SupplementPK{
+		KineticsType:         kinetics.FirstOrder,
+		PeakMinutes:          60,
+		HalfLifeMinutes:      300,
+		VolumeOfDistribution: 0.6,
+	}
+	caffeine := models.SupplementInfo{ID: "supp-caffeine", Name: "Caffeine"}
+	zinc := models.SupplementInfo{ID: "supp-zinc", Name: "Zinc"}
+
+	doses := []timelineDose{
+		{Supplement: caffeine, LoggedAt: windowStart, DoseMg: 100, PK: pk},
+		{Supplement: zinc, LoggedAt: windowStart, DoseMg: 15, PK: kinetics.SupplementPK{PeakMinutes: 120}},
+	}
+
+	curves := buildConcentrationCurves(doses, windowStart, windowStart.Add(2*time.Hour), time.Hour, nil, 60)
+
+	peak := curves[0].Samples[1]
+	expected, _ := kinetics.PeakPlasmaConcentration(kinetics.ConcentrationParams{Dose: 100, PK: pk, BodyWeightKg: 60})
+	if peak.ConcentrationMgPerL == nil || !almostEqual(*peak.ConcentrationMgPerL, expected, 0.0001) {
+		t.Fatalf("expected %v mg/L at the peak, got %v", expected, peak.ConcentrationMgPerL)
+	}
+	if curves[1].Samples[1].ConcentrationMgPerL != nil {
+		t.Fatalf("expected no mg/L without a volume of distribution")
+	}
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"github.com/nikitalbnv/stochi/apps/engine/internal/kinetics"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+)
+
+// applyAbsorptionLosses sets the estimated absorption loss on competition warnings.
+// The analyzed supplements are taken together, so the source competes with the target
+// for the part of the target's absorption window it shares. Warnings without dosages
+// or transporter parameters for both supplements are left without an estimate.
+func applyAbsorptionLosses(warnings []models.InteractionWarning, dosages []models.DosageInput, supplements map[string]models.Supplement, servings productServings, pks map[string]kinetics.SupplementPK) {
+	elementalMg := make(map[string]float64)
+	for _, d := range dosages {
+		s, ok := supplements[d.SupplementID]
+		if !ok {
+			continue
+		}
+		conversion, _ := dosageConversion(d, s, servings)
+		amount, err := NormalizeDosage(d.Amount, d.Unit, getElementalWeight(s), conversion)
+		if err != nil {
+			continue
+		}
+		elementalMg[d.SupplementID] += amount
+	}
+
+	for i, w := range warnings {
+		if w.Type != models.InteractionTypeCompetition {
+			continue
+		}
+		sourceDose, hasSource := elementalMg[w.Source.ID]
+		targetDose, hasTarget := elementalMg[w.Target.ID]
+		if !hasSource || !hasTarget {
+			continue
+		}
+
+		loss, err := kinetics.CompetitiveAbsorptionLoss(
+			kinetics.TransporterDose{Dose: targetDose, PK: pks[w.Target.ID]},
+			[]kinetics.TransporterDose{{Dose: sourceDose, PK: pks[w.Source.ID]}},
+		)
+		if err != nil {
+			continue
+		}
+
+		percent := RoundToDecimal(loss*100, 1)
+		warnings[i].AbsorptionLossPercent = &percent
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/kinetics"
+	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
+)
+
+func TestApplyAbsorptionLosses(t *testing.T) {
+	calciumWeight := float64(25)
+	supplements := map[string]models.Supplement{
+		"supp-ca": {ID: "supp-ca", Name: "Calcium", ElementalWeight: &calciumWeight},
+		"supp-fe": {ID: "supp-fe", Name: "Iron"},
+		"supp-d3": {ID: "supp-d3", Name: "Vitamin D3"},
+	}
+	pks := map[string]kinetics.SupplementPK{
+		"supp-ca": {AbsorptionSaturationDose: 1000, AbsorptionWindowMinutes: 240},
+		"supp-fe": {Km: 30, AbsorptionWindowMinutes: 180},
+	}
+	warnings := []models.InteractionWarning{
+		{Type: models.InteractionTypeCompetition, Source: models.SupplementInfo{ID: "supp-ca"}, Target: models.SupplementInfo{ID: "supp-fe"}},
+		{Type: models.InteractionTypeInhibition, Source: models.SupplementInfo{ID: "supp-ca"}, Target: models.SupplementInfo{ID: "supp-fe"}},
+		{Type: models.InteractionTypeCompetition, Source: models.SupplementInfo{ID: "supp-ca"}, Target: models.SupplementInfo{ID: "supp-d3"}},
+	}
+	dosages := []models.DosageInput{
+		{SupplementID: "supp-ca", Amount: 1, Unit: models.DosageUnitG},
+		{SupplementID: "supp-ca", Amount: 1000, Unit: models.DosageUnitMg},
+		{SupplementID: "supp-fe", Amount: 18, Unit: models.DosageUnitMg},
+		{SupplementID: "supp-d3", Amount: 50, Unit: models.DosageUnitMcg},
+	}
+
+	applyAbsorptionLosses(warnings, dosages, supplements, nil, pks)
+
+	// 2g calcium citrate at 25% = 500mg elemental: loss = 30*0.5 / (30*1.5 + 18) = 23.8%
+	if warnings[0].AbsorptionLossPercent == nil || *warnings[0].AbsorptionLossPercent != 23.8 {
+		t.Fatalf("expected 23.8%% absorption loss, got %v", warnings[0].AbsorptionLossPercent)
+	}
+	if warnings[1].AbsorptionLossPercent != nil {
+		t.Fatalf("expected no estimate for non-competition interactions")
+	}
+	if warnings[2].AbsorptionLossPercent != nil {
+		t.Fatalf("expected no estimate without transporter parameters")
+	}
+}
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"math"
+	"strconv"
 
 	"github.com/nikitalbnv/stochi/apps/engine/internal/models"
 )
@@ -17,8 +19,10 @@ import (
 //   - 1 g = 1,000,000 mcg
 //   - 1 mg = 1,000 mcg
 //   - 1 mcg = 1 mcg (base)
-//   - 1 IU varies by vitamin (handled separately)
-func ToMicrograms(amount float32, unit models.DosageUnit) (float32, error) {
+//   - 1 IU varies by vitamin form (see IUToMicrograms)
+//   - ml and drops depend on the liquid's concentration (see LiquidToMilligrams)
+//   - Serving units depend on the product (see DosageConversion)
+func ToMicrograms(amount float64, unit models.DosageUnit) (float64, error) {
 	switch unit {
 	case models.DosageUnitG:
 		return amount * 1_000_000, nil
@@ -27,51 +31,231 @@ func ToMicrograms(amount float32, unit models.DosageUnit) (float32, error) {
 	case models.DosageUnitMcg:
 		return amount, nil
 	case models.DosageUnitIU:
-		// IU cannot be converted without knowing the specific vitamin
-		// This should be handled by ToMicrogramsWithContext
-		return 0, fmt.Errorf("IU requires vitamin context for conversion")
-	case models.DosageUnitMl:
-		return 0, fmt.Errorf("ml cannot be converted to mcg without density")
+		// IU cannot be converted without knowing the specific vitamin form
+		// This should be handled by IUToMicrograms
+		return 0, ErrNoIUConversion
+	case models.DosageUnitMl, models.DosageUnitDrops:
+		// Volumes cannot be converted without the liquid's concentration
+		// This should be handled by LiquidToMilligrams
+		return 0, ErrNoConcentration
+	case models.DosageUnitCapsule, models.DosageUnitTablet, models.DosageUnitScoop, models.DosageUnitServing:
+		return 0, ErrNoServingSize
 	default:
 		return 0, fmt.Errorf("unknown unit: %s", unit)
 	}
 }
 
-// ToMilligrams converts any dosage unit to milligrams.
-func ToMilligrams(amount float32, unit models.DosageUnit) (float32, error) {
-	mcg, err := ToMicrograms(amount, unit)
-	if err != nil {
+// ToMilligrams converts any dosage unit to milligrams. It scales directly rather than
+// through micrograms, so milligram amounts pass through unchanged.
+func ToMilligrams(amount float64, unit models.DosageUnit) (float64, error) {
+	switch unit {
+	case models.DosageUnitG:
+		return amount * 1_000, nil
+	case models.DosageUnitMg:
+		return amount, nil
+	case models.DosageUnitMcg:
+		return amount / 1_000, nil
+	default:
+		// Units without a fixed mass return ToMicrograms' error
+		_, err := ToMicrograms(amount, unit)
 		return 0, err
 	}
-	return mcg / 1_000, nil
 }
 
-// IUConversionFactor represents the mcg per IU for fat-soluble vitamins.
-// These are standardized conversion factors.
-type IUConversionFactor struct {
-	VitaminD3 float32 // 1 IU = 0.025 mcg cholecalciferol
-	VitaminA  float32 // 1 IU = 0.3 mcg retinol
-	VitaminE  float32 // 1 IU = 0.67 mg d-alpha-tocopherol (natural)
+// FromMilligrams converts milligrams to a fixed mass unit, the inverse of ToMilligrams.
+func FromMilligrams(mg float64, unit models.DosageUnit) (float64, error) {
+	switch unit {
+	case models.DosageUnitG:
+		return mg / 1_000, nil
+	case models.DosageUnitMg:
+		return mg, nil
+	case models.DosageUnitMcg:
+		return mg * 1_000, nil
+	default:
+		_, err := ToMicrograms(mg, unit)
+		return 0, err
+	}
+}
+
+// ErrNoIUConversion is returned for IU dosages of a supplement without an mcg per IU factor.
+var ErrNoIUConversion = errors.New("IU requires the supplement's mcg per IU factor")
+
+// IUToMicrograms converts IU to mcg using the mcg per IU of the supplement's form.
+// IU is defined by biological activity, so the factor depends on the compound:
+//   - Vitamin D2 and D3: 0.025 mcg (40 IU = 1 mcg)
+//   - Vitamin A as retinol: 0.3 mcg; as supplemental beta-carotene: 0.6 mcg
+//   - Vitamin E as natural d-alpha-tocopherol: 670 mcg; as synthetic dl-alpha-tocopherol: 450 mcg
+//
+// Vitamin K has no IU definition and is dosed by mass.
+func IUToMicrograms(amount float64, mcgPerIU float64) (float64, error) {
+	if mcgPerIU <= 0 {
+		return 0, ErrNoIUConversion
+	}
+	return amount * mcgPerIU, nil
+}
+
+// DefaultDropsPerMl is the metric dropper convention of 0.05 ml per drop, used when a
+// supplement does not define its own.
+const DefaultDropsPerMl = 20
+
+// ErrNoConcentration is returned for ml and drops dosages of a supplement without a
+// concentration in mg/ml.
+var ErrNoConcentration = errors.New("ml and drops require the supplement's concentration in mg/ml")
+
+// LiquidToMilligrams converts a volume in ml or drops to mg of the compound using the
+// liquid's concentration. dropsPerMl defaults to DefaultDropsPerMl.
+//
+// Example: 4 drops of a 0.125 mg/ml vitamin D3 liquid at 20 drops/ml
+//
+//	= 4 / 20 * 0.125 = 0.025 mg (1000 IU)
+func LiquidToMilligrams(amount float64, unit models.DosageUnit, concentrationMgPerMl float64, dropsPerMl float64) (float64, error) {
+	if concentrationMgPerMl <= 0 {
+		return 0, ErrNoConcentration
+	}
+	if dropsPerMl <= 0 {
+		dropsPerMl = DefaultDropsPerMl
+	}
+
+	switch unit {
+	case models.DosageUnitMl:
+		return amount * concentrationMgPerMl, nil
+	case models.DosageUnitDrops:
+		return amount / dropsPerMl * concentrationMgPerMl, nil
+	default:
+		return 0, fmt.Errorf("%s is not a volume unit", unit)
+	}
+}
+
+// ErrNoServingSize is returned for serving-unit dosages without a product serving size.
+var ErrNoServingSize = errors.New("serving units require a product serving size")
+
+// Serving is the amount of a supplement in one serving of a product, e.g. 5000 IU per
+// capsule or 5 g per scoop.
+type Serving struct {
+	Amount float64           `json:"amount"`
+	Unit   models.DosageUnit `json:"unit"`
+}
+
+// DosageConversion holds the supplement-specific factors for units without a fixed mass.
+// Zero values mean the factor is unknown.
+type DosageConversion struct {
+	McgPerIU             float64  `json:"mcgPerIu,omitempty"`             // For IU dosages
+	ConcentrationMgPerMl float64  `json:"concentrationMgPerMl,omitempty"` // For ml and drops dosages
+	DropsPerMl           float64  `json:"dropsPerMl,omitempty"`           // Defaults to DefaultDropsPerMl
+	Serving              *Serving `json:"serving,omitempty"`              // For serving units
+}
+
+// supplementUnitColumns selects the unit conversion columns of the supplement table.
+// It expects the supplement table to be aliased as "s" and must be scanned with
+// supplementUnitRecord.scanTargets.
+const supplementUnitColumns = `
+		s.mcg_per_iu, s.concentration_mg_per_ml, s.drops_per_ml`
+
+// supplementUnitRecord holds the nullable unit conversion columns of a supplement row.
+type supplementUnitRecord struct {
+	McgPerIU             *float32
+	ConcentrationMgPerMl *float32
+	DropsPerMl           *float32
+}
+
+// scanTargets returns the scan destinations matching supplementUnitColumns.
+func (r *supplementUnitRecord) scanTargets() []any {
+	return []any{&r.McgPerIU, &r.ConcentrationMgPerMl, &r.DropsPerMl}
+}
+
+// toConversion converts the record to conversion factors, leaving missing ones at zero.
+func (r supplementUnitRecord) toConversion() DosageConversion {
+	return DosageConversion{
+		McgPerIU:             valueOrZero(widenReal(r.McgPerIU)),
+		ConcentrationMgPerMl: valueOrZero(widenReal(r.ConcentrationMgPerMl)),
+		DropsPerMl:           valueOrZero(widenReal(r.DropsPerMl)),
+	}
+}
+
+// realToFloat64 widens a value read from a real column through its shortest decimal
+// representation, so a stored 0.025 becomes 0.025 rather than 0.0250000003725290.
+// Plain conversion would carry float32 rounding error into the float64 arithmetic.
+func realToFloat64(value float32) float64 {
+	widened, _ := strconv.ParseFloat(strconv.FormatFloat(float64(value), 'g', -1, 32), 64)
+	return widened
+}
+
+// widenReal is realToFloat64 for nullable columns.
+func widenReal(value *float32) *float64 {
+	if value == nil {
+		return nil
+	}
+	widened := realToFloat64(*value)
+	return &widened
 }
 
-var IUFactors = IUConversionFactor{
-	VitaminD3: 0.025, // 40 IU = 1 mcg
-	VitaminA:  0.3,   // 3.33 IU = 1 mcg retinol
-	VitaminE:  670,   // 1 IU = 0.67 mg = 670 mcg (natural form)
+func valueOrZero(value *float64) float64 {
+	if value == nil {
+		return 0
+	}
+	return *value
 }
 
-// VitaminIUToMicrograms converts IU to mcg for specific vitamins.
-// vitaminType should be one of: "D3", "A", "E"
-func VitaminIUToMicrograms(amount float32, vitaminType string) (float32, error) {
-	switch vitaminType {
-	case "D3", "d3", "vitamin_d3":
-		return amount * IUFactors.VitaminD3, nil
-	case "A", "a", "vitamin_a":
-		return amount * IUFactors.VitaminA, nil
-	case "E", "e", "vitamin_e":
-		return amount * IUFactors.VitaminE, nil
+// ToMilligrams converts a dosage in any unit to mg of the compound, using the
+// supplement-specific factors for IU, ml and drops. Serving units are first scaled by the
+// serving size, which may itself be in IU, ml or drops.
+func (c DosageConversion) ToMilligrams(amount float64, unit models.DosageUnit) (float64, error) {
+	if unit.IsServing() {
+		if c.Serving == nil || c.Serving.Amount <= 0 || c.Serving.Unit.IsServing() {
+			return 0, ErrNoServingSize
+		}
+		return c.ToMilligrams(amount*c.Serving.Amount, c.Serving.Unit)
+	}
+
+	switch unit {
+	case models.DosageUnitIU:
+		mcg, err := IUToMicrograms(amount, c.McgPerIU)
+		if err != nil {
+			return 0, err
+		}
+		return mcg / 1_000, nil
+	case models.DosageUnitMl, models.DosageUnitDrops:
+		return LiquidToMilligrams(amount, unit, c.ConcentrationMgPerMl, c.DropsPerMl)
+	default:
+		return ToMilligrams(amount, unit)
+	}
+}
+
+// FromMilligrams converts mg of the compound to an amount in the given unit, the inverse
+// of ToMilligrams.
+func (c DosageConversion) FromMilligrams(mg float64, unit models.DosageUnit) (float64, error) {
+	if unit.IsServing() {
+		if c.Serving == nil || c.Serving.Amount <= 0 || c.Serving.Unit.IsServing() {
+			return 0, ErrNoServingSize
+		}
+		perServing, err := c.FromMilligrams(mg, c.Serving.Unit)
+		if err != nil {
+			return 0, err
+		}
+		return perServing / c.Serving.Amount, nil
+	}
+
+	switch unit {
+	case models.DosageUnitIU:
+		if c.McgPerIU <= 0 {
+			return 0, ErrNoIUConversion
+		}
+		return mg * 1_000 / c.McgPerIU, nil
+	case models.DosageUnitMl, models.DosageUnitDrops:
+		if c.ConcentrationMgPerMl <= 0 {
+			return 0, ErrNoConcentration
+		}
+		ml := mg / c.ConcentrationMgPerMl
+		if unit == models.DosageUnitMl {
+			return ml, nil
+		}
+		dropsPerMl := c.DropsPerMl
+		if dropsPerMl <= 0 {
+			dropsPerMl = DefaultDropsPerMl
+		}
+		return ml * dropsPerMl, nil
 	default:
-		return 0, fmt.Errorf("unknown vitamin type for IU conversion: %s", vitaminType)
+		return FromMilligrams(mg, unit)
 	}
 }
 
@@ -87,7 +271,7 @@ func VitaminIUToMicrograms(amount float32, vitaminType string) (float32, error)
 //   - elementalWeightPercent: The percentage of elemental content (e.g., 21.0 for 21%)
 //
 // Returns the elemental amount in mg.
-func CalculateElementalAmount(compoundDosageMg float32, elementalWeightPercent float32) float32 {
+func CalculateElementalAmount(compoundDosageMg float64, elementalWeightPercent float64) float64 {
 	if elementalWeightPercent <= 0 || elementalWeightPercent > 100 {
 		// Invalid percentage, return 0 or the original amount for 100%
 		if elementalWeightPercent == 100 {
@@ -105,28 +289,13 @@ func CalculateElementalAmount(compoundDosageMg float32, elementalWeightPercent f
 //   - amount: The raw dosage amount
 //   - unit: The unit of the dosage
 //   - elementalWeightPercent: The elemental weight percentage from the supplement record
-//   - vitaminType: Optional, required only for IU conversions (e.g., "D3")
+//   - conversion: The supplement's factors, required only for IU, ml and drops dosages
 //
 // Returns the elemental amount in mg.
-func NormalizeDosage(amount float32, unit models.DosageUnit, elementalWeightPercent float32, vitaminType string) (float32, error) {
-	var amountMg float32
-
-	switch unit {
-	case models.DosageUnitIU:
-		if vitaminType == "" {
-			return 0, fmt.Errorf("vitamin type required for IU conversion")
-		}
-		mcg, err := VitaminIUToMicrograms(amount, vitaminType)
-		if err != nil {
-			return 0, err
-		}
-		amountMg = mcg / 1_000
-	default:
-		mg, err := ToMilligrams(amount, unit)
-		if err != nil {
-			return 0, err
-		}
-		amountMg = mg
+func NormalizeDosage(amount float64, unit models.DosageUnit, elementalWeightPercent float64, conversion DosageConversion) (float64, error) {
+	amountMg, err := conversion.ToMilligrams(amount, unit)
+	if err != nil {
+		return 0, err
 	}
 
 	return CalculateElementalAmount(amountMg, elementalWeightPercent), nil
@@ -135,10 +304,10 @@ func NormalizeDosage(amount float32, unit models.DosageUnit, elementalWeightPerc
 // DosageInput represents a single supplement dosage for ratio calculations.
 type DosageInput struct {
 	SupplementID           string            `json:"supplementId"`
-	Amount                 float32           `json:"amount"`
+	Amount                 float64           `json:"amount"`
 	Unit                   models.DosageUnit `json:"unit"`
-	ElementalWeightPercent float32           `json:"elementalWeightPercent"`
-	VitaminType            string            `json:"vitaminType,omitempty"` // For IU conversions
+	ElementalWeightPercent float64           `json:"elementalWeightPercent"`
+	DosageConversion                         // For IU, ml and drops conversions
 }
 
 // CalculateRatio computes the elemental ratio between two supplements.
@@ -148,13 +317,13 @@ type DosageInput struct {
 //   - 30mg Zinc Picolinate (21% elemental) = 6.3mg Zn
 //   - 2mg Copper Bisglycinate (30% elemental) = 0.6mg Cu
 //   - Ratio = 6.3 / 0.6 = 10.5:1
-func CalculateRatio(source, target DosageInput) (float32, error) {
-	sourceElemental, err := NormalizeDosage(source.Amount, source.Unit, source.ElementalWeightPercent, source.VitaminType)
+func CalculateRatio(source, target DosageInput) (float64, error) {
+	sourceElemental, err := NormalizeDosage(source.Amount, source.Unit, source.ElementalWeightPercent, source.DosageConversion)
 	if err != nil {
 		return 0, fmt.Errorf("failed to normalize source dosage: %w", err)
 	}
 
-	targetElemental, err := NormalizeDosage(target.Amount, target.Unit, target.ElementalWeightPercent, target.VitaminType)
+	targetElemental, err := NormalizeDosage(target.Amount, target.Unit, target.ElementalWeightPercent, target.DosageConversion)
 	if err != nil {
 		return 0, fmt.Errorf("failed to normalize target dosage: %w", err)
 	}
@@ -166,13 +335,19 @@ func CalculateRatio(source, target DosageInput) (float32, error) {
 	return sourceElemental / targetElemental, nil
 }
 
-// CheckRatioCompliance checks if a ratio falls within the acceptable range.
+// ratioBoundEpsilon is the relative difference below which a ratio counts as on a rule
+// bound. Decimal dosages are not exact in binary, so 0.3 mg against 20 mcg of the same
+// form comes out as 15.000000000000002 rather than 15.
+const ratioBoundEpsilon = 1e-9
+
+// CheckRatioCompliance checks if a ratio falls within the acceptable range. Ratios within
+// ratioBoundEpsilon of a bound are compliant.
 // Returns:
 //   - isCompliant: true if within range
 //   - deviation: how far from optimal (negative = below min, positive = above max, 0 = within range)
-func CheckRatioCompliance(currentRatio float32, rule models.RatioRule) (isCompliant bool, deviation float32) {
-	minRatio := float32(0)
-	maxRatio := float32(math.MaxFloat32)
+func CheckRatioCompliance(currentRatio float64, rule models.RatioRule) (isCompliant bool, deviation float64) {
+	minRatio := 0.0
+	maxRatio := math.MaxFloat64
 
 	if rule.MinRatio != nil {
 		minRatio = *rule.MinRatio
@@ -181,10 +356,10 @@ func CheckRatioCompliance(currentRatio float32, rule models.RatioRule) (isCompli
 		maxRatio = *rule.MaxRatio
 	}
 
-	if currentRatio < minRatio {
+	if currentRatio < minRatio*(1-ratioBoundEpsilon) {
 		return false, currentRatio - minRatio // Negative deviation
 	}
-	if currentRatio > maxRatio {
+	if currentRatio > maxRatio*(1+ratioBoundEpsilon) {
 		return false, currentRatio - maxRatio // Positive deviation
 	}
 
@@ -192,7 +367,7 @@ func CheckRatioCompliance(currentRatio float32, rule models.RatioRule) (isCompli
 }
 
 // RoundToDecimal rounds a float to the specified number of decimal places.
-func RoundToDecimal(value float32, decimals int) float32 {
+func RoundToDecimal(value float64, decimals int) float64 {
 	multiplier := math.Pow(10, float64(decimals))
-	return float32(math.Round(float64(value)*multiplier) / multiplier)
+	return math.Round(value*multiplier) / multiplier
 }
@@ -0,0 +1,39 @@
+package kinetics
+
+import (
+	"math"
+)
+
+// Calibration holds a user's personal PK factors, fitted from blood test results.
+// A zero value leaves the population parameters unchanged.
+type Calibration struct {
+	BioavailabilityFactor float64 // F multiplier (0 or 1 = no change)
+	ClearanceFactor       float64 // CL multiplier (0 or 1 = no change)
+}
+
+// ApplyCalibration returns pk adjusted by pk.Calibration, along with the exposure factor
+// used to scale percent-of-Cmax output. The returned parameters have no calibration set.
+//
+// With volume unchanged, ke = CL / V, so a clearance factor c scales the half-life by 1/c.
+// Like routes and meals, the bioavailability factor scales exposure and F is capped at 100%
+// when it is known.
+func ApplyCalibration(pk SupplementPK) (SupplementPK, float64) {
+	calibration := pk.Calibration
+	adjusted := pk
+	adjusted.Calibration = Calibration{}
+
+	if calibration.ClearanceFactor > 0 && calibration.ClearanceFactor != 1 {
+		adjusted.HalfLifeMinutes = halfLifeOrDefault(pk) / calibration.ClearanceFactor
+	}
+
+	exposure := 1.0
+	if calibration.BioavailabilityFactor > 0 {
+		exposure = calibration.BioavailabilityFactor
+	}
+	if pk.BioavailabilityPercent > 0 {
+		adjusted.BioavailabilityPercent = math.Min(pk.BioavailabilityPercent*exposure, 100)
+		exposure = adjusted.BioavailabilityPercent / pk.BioavailabilityPercent
+	}
+
+	return adjusted, exposure
+}
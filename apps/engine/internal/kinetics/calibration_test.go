@@ -0,0 +1,76 @@
+package kinetics
+
+import (
+	"testing"
+)
+
+func TestApplyCalibration_ZeroCalibrationUnchanged(t *testing.T) {
+	pk := SupplementPK{PeakMinutes: 60, HalfLifeMinutes: 240, BioavailabilityPercent: 50}
+
+	adjusted, exposure := ApplyCalibration(pk)
+	if adjusted != pk || exposure != 1 {
+		t.Errorf("Zero calibration should leave PK unchanged, got %+v (exposure %v)", adjusted, exposure)
+	}
+}
+
+func TestApplyCalibration_ScalesHalfLifeAndExposure(t *testing.T) {
+	pk := SupplementPK{
+		PeakMinutes:            60,
+		HalfLifeMinutes:        240,
+		BioavailabilityPercent: 80,
+		Calibration:            Calibration{BioavailabilityFactor: 0.5, ClearanceFactor: 0.8},
+	}
+
+	adjusted, exposure := ApplyCalibration(pk)
+	if adjusted.HalfLifeMinutes != 300 {
+		t.Errorf("Lower clearance should extend t½ to 300, got %v", adjusted.HalfLifeMinutes)
+	}
+	if adjusted.BioavailabilityPercent != 40 {
+		t.Errorf("F should be 40%%, got %v", adjusted.BioavailabilityPercent)
+	}
+	if exposure != 0.5 {
+		t.Errorf("Exposure should be 0.5, got %v", exposure)
+	}
+	if adjusted.Calibration != (Calibration{}) {
+		t.Errorf("Adjusted PK should not carry the calibration, got %+v", adjusted.Calibration)
+	}
+}
+
+func TestCalculateConcentration_UsesCalibration(t *testing.T) {
+	pk := SupplementPK{KineticsType: FirstOrder, PeakMinutes: 60, HalfLifeMinutes: 240}
+
+	// Poor absorber: the peak is scaled down
+	pk.Calibration = Calibration{BioavailabilityFactor: 0.6}
+	result := CalculateConcentration(ConcentrationParams{Dose: 100, MinutesSinceIngestion: 60, PK: pk})
+	if !approxEqual(result, 60, epsilon) {
+		t.Errorf("Expected 60%% at peak, got %v", result)
+	}
+
+	// Slow clearer: one population half-life after the peak, more than half remains
+	pk.Calibration = Calibration{ClearanceFactor: 0.5}
+	result = CalculateConcentration(ConcentrationParams{Dose: 100, MinutesSinceIngestion: 300, PK: pk})
+	if !approxEqual(result, 100*0.7071067811865476, 1e-9) {
+		t.Errorf("Expected ~70.7%% with doubled t½, got %v", result)
+	}
+}
+
+func TestAnalyzeSteadyState_UsesCalibratedHalfLife(t *testing.T) {
+	schedule := DosingSchedule{
+		PeriodMinutes: MinutesPerDay,
+		Doses:         []ScheduledDose{{Dose: 100, AtMinutes: 480}},
+		PK:            SupplementPK{KineticsType: FirstOrder, PeakMinutes: 60, HalfLifeMinutes: 720},
+	}
+	baseline := AnalyzeSteadyState(schedule)
+
+	schedule.PK.Calibration = Calibration{ClearanceFactor: 0.5}
+	calibrated := AnalyzeSteadyState(schedule)
+
+	if !approxEqual(calibrated.TimeToSteadyStateMinutes, 2*baseline.TimeToSteadyStateMinutes, 1e-6) {
+		t.Errorf("Halved clearance should double time to steady state: %v vs %v",
+			calibrated.TimeToSteadyStateMinutes, baseline.TimeToSteadyStateMinutes)
+	}
+	if calibrated.AccumulationRatio <= baseline.AccumulationRatio {
+		t.Errorf("Halved clearance should increase accumulation: %v vs %v",
+			calibrated.AccumulationRatio, baseline.AccumulationRatio)
+	}
+}
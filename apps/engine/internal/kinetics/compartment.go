@@ -0,0 +1,280 @@
+package kinetics
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// compartmentPeakSamples is the grid resolution used to locate the Cmax of a
+	// numerically integrated curve
+	compartmentPeakSamples = 240
+	// compartmentPeakHorizonHalfLives bounds the Cmax search to Tmax plus this many half-lives
+	compartmentPeakHorizonHalfLives = 4
+)
+
+// CompartmentModel is a pharmacokinetic model expressed as a system of ODEs over the
+// drug amount (mg) in each compartment. Models are integrated with IntegrateRK45.
+type CompartmentModel interface {
+	// Compartments returns the number of state variables.
+	Compartments() int
+	// Administer adds a dose (mg) to the state.
+	Administer(state []float64, dose float64)
+	// Derivatives writes dA/dt for every compartment.
+	Derivatives(t float64, state []float64, dadt []float64)
+	// Plasma returns the amount in the observed (central) compartment.
+	Plasma(state []float64) float64
+}
+
+// terminalDecayModel is implemented by compartment models whose plasma curve ends in a
+// single exponential decline.
+type terminalDecayModel interface {
+	// terminalRate returns the rate constant (1/min) of the terminal decline.
+	terminalRate() float64
+}
+
+// CompartmentParams holds optional rate constants for compartment models, stored per
+// supplement so compounds can be tuned without code changes. Zero values use defaults.
+type CompartmentParams struct {
+	K12             float64 `json:"k12,omitempty"`             // Central → peripheral (1/min)
+	K21             float64 `json:"k21,omitempty"`             // Peripheral → central (1/min)
+	BiliaryFraction float64 `json:"biliaryFraction,omitempty"` // Fraction of elimination excreted into bile (0-1)
+	BileReleaseRate float64 `json:"bileReleaseRate,omitempty"` // Gallbladder → gut (1/min)
+}
+
+// CompartmentModelFactory builds a model from a supplement's PK parameters.
+type CompartmentModelFactory func(pk SupplementPK) CompartmentModel
+
+// compartmentModels maps the names stored in supplement.compartment_model to factories.
+var compartmentModels = map[string]CompartmentModelFactory{
+	"one_compartment":             newOneCompartmentModel,
+	"two_compartment":             newTwoCompartmentModel,
+	"enterohepatic":               newEnterohepaticModel,
+	"michaelis_menten_absorption": newMichaelisMentenAbsorptionModel,
+}
+
+// RegisterCompartmentModel makes a model available under name, replacing any existing one.
+// It is not safe for concurrent use and should be called during initialization.
+func RegisterCompartmentModel(name string, factory CompartmentModelFactory) {
+	compartmentModels[name] = factory
+}
+
+// LookupCompartmentModel returns the factory registered under name.
+func LookupCompartmentModel(name string) (CompartmentModelFactory, bool) {
+	factory, ok := compartmentModels[name]
+	return factory, ok
+}
+
+// CompartmentModelNames returns the registered model names in sorted order.
+func CompartmentModelNames() []string {
+	names := make([]string, 0, len(compartmentModels))
+	for name := range compartmentModels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SimulateCompartmentModel integrates a single dose given at t = 0 and returns the
+// plasma amount (mg) at each of the ascending times.
+func SimulateCompartmentModel(model CompartmentModel, dose float64, times []float64) ([]float64, error) {
+	state := make([]float64, model.Compartments())
+	model.Administer(state, dose)
+
+	states, err := IntegrateRK45(model.Derivatives, 0, state, times, RK45Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	plasma := make([]float64, len(states))
+	for i, s := range states {
+		plasma[i] = model.Plasma(s)
+	}
+	return plasma, nil
+}
+
+// calculateCompartmentConcentration integrates the supplement's compartment model and
+// returns the plasma amount at t as a percentage of the curve's Cmax.
+//
+// It is the single-point form of fillCompartmentCurve, which integrates the model over
+// the Cmax search grid as well; callers evaluating many times should use
+// CalculateConcentrationCurve so the model is integrated once.
+func calculateCompartmentConcentration(params ConcentrationParams, factory CompartmentModelFactory) float64 {
+	var out [1]float64
+	fillCompartmentCurve(params, factory, []float64{params.MinutesSinceIngestion}, out[:])
+	return out[0]
+}
+
+// halfLifeOrDefault returns the stored t½, or DefaultHalfLifeMinutes when missing.
+func halfLifeOrDefault(pk SupplementPK) float64 {
+	if pk.HalfLifeMinutes > 0 {
+		return pk.HalfLifeMinutes
+	}
+	return DefaultHalfLifeMinutes
+}
+
+// absorptionRates returns ka and ke (1/min) for pk, deriving ka from Tmax when not stored.
+func absorptionRates(pk SupplementPK) (float64, float64) {
+	halfLife := halfLifeOrDefault(pk)
+	ke := math.Log(2) / halfLife
+	ka := pk.AbsorptionRateConstant
+	if ka <= 0 {
+		ka = DeriveAbsorptionRateConstant(peakMinutesOrDefault(pk), halfLife)
+	}
+	return ka, ke
+}
+
+// oneCompartmentModel is the ODE form of the Bateman model:
+//
+//	dGut/dt     = -ka*Gut
+//	dCentral/dt =  ka*Gut - ke*Central
+type oneCompartmentModel struct {
+	ka, ke float64
+}
+
+func newOneCompartmentModel(pk SupplementPK) CompartmentModel {
+	ka, ke := absorptionRates(pk)
+	return oneCompartmentModel{ka: ka, ke: ke}
+}
+
+func (m oneCompartmentModel) Compartments() int { return 2 }
+
+func (m oneCompartmentModel) Administer(state []float64, dose float64) { state[0] += dose }
+
+func (m oneCompartmentModel) Derivatives(_ float64, a []float64, dadt []float64) {
+	absorbed := m.ka * a[0]
+	dadt[0] = -absorbed
+	dadt[1] = absorbed - m.ke*a[1]
+}
+
+func (m oneCompartmentModel) Plasma(state []float64) float64 { return state[1] }
+
+// terminalRate is the slower of absorption and elimination; with ka < ke (flip-flop
+// kinetics) the decline is governed by absorption.
+func (m oneCompartmentModel) terminalRate() float64 { return math.Min(m.ka, m.ke) }
+
+// twoCompartmentModel adds a peripheral tissue compartment, giving the biphasic
+// (distribution then terminal) decline seen for compounds that distribute into tissue:
+//
+//	dGut/dt        = -ka*Gut
+//	dCentral/dt    =  ka*Gut - (ke + k12)*Central + k21*Peripheral
+//	dPeripheral/dt =  k12*Central - k21*Peripheral
+//
+// Without stored rates, k12 = 2*ke and k21 = ke.
+type twoCompartmentModel struct {
+	ka, ke, k12, k21 float64
+}
+
+func newTwoCompartmentModel(pk SupplementPK) CompartmentModel {
+	ka, ke := absorptionRates(pk)
+	m := twoCompartmentModel{ka: ka, ke: ke, k12: pk.Compartment.K12, k21: pk.Compartment.K21}
+	if m.k12 <= 0 {
+		m.k12 = 2 * ke
+	}
+	if m.k21 <= 0 {
+		m.k21 = ke
+	}
+	return m
+}
+
+func (m twoCompartmentModel) Compartments() int { return 3 }
+
+func (m twoCompartmentModel) Administer(state []float64, dose float64) { state[0] += dose }
+
+func (m twoCompartmentModel) Derivatives(_ float64, a []float64, dadt []float64) {
+	absorbed := m.ka * a[0]
+	distributed := m.k12*a[1] - m.k21*a[2]
+	dadt[0] = -absorbed
+	dadt[1] = absorbed - m.ke*a[1] - distributed
+	dadt[2] = distributed
+}
+
+func (m twoCompartmentModel) Plasma(state []float64) float64 { return state[1] }
+
+// terminalRate is the slower of absorption and β, the smaller eigenvalue of the
+// central-peripheral exchange:
+//
+//	β = (s - sqrt(s² - 4*ke*k21)) / 2, s = ke + k12 + k21
+func (m twoCompartmentModel) terminalRate() float64 {
+	s := m.ke + m.k12 + m.k21
+	beta := (s - math.Sqrt(s*s-4*m.ke*m.k21)) / 2
+	return math.Min(m.ka, beta)
+}
+
+// enterohepaticModel recirculates part of the eliminated drug through bile, producing
+// secondary peaks and a prolonged tail:
+//
+//	dGut/dt     = -ka*Gut + kb*Bile
+//	dCentral/dt =  ka*Gut - ke*Central
+//	dBile/dt    =  fb*ke*Central - kb*Bile
+//
+// Without stored rates, fb = 0.3 and kb = ln(2)/120 (gallbladder emptying over ~2 hours).
+type enterohepaticModel struct {
+	ka, ke, fb, kb float64
+}
+
+func newEnterohepaticModel(pk SupplementPK) CompartmentModel {
+	ka, ke := absorptionRates(pk)
+	m := enterohepaticModel{ka: ka, ke: ke, fb: pk.Compartment.BiliaryFraction, kb: pk.Compartment.BileReleaseRate}
+	if m.fb <= 0 || m.fb >= 1 {
+		m.fb = 0.3
+	}
+	if m.kb <= 0 {
+		m.kb = math.Log(2) / 120
+	}
+	return m
+}
+
+func (m enterohepaticModel) Compartments() int { return 3 }
+
+func (m enterohepaticModel) Administer(state []float64, dose float64) { state[0] += dose }
+
+func (m enterohepaticModel) Derivatives(_ float64, a []float64, dadt []float64) {
+	absorbed := m.ka * a[0]
+	eliminated := m.ke * a[1]
+	released := m.kb * a[2]
+	dadt[0] = released - absorbed
+	dadt[1] = absorbed - eliminated
+	dadt[2] = m.fb*eliminated - released
+}
+
+func (m enterohepaticModel) Plasma(state []float64) float64 { return state[1] }
+
+// michaelisMentenAbsorptionModel absorbs through a saturable transporter and eliminates
+// linearly, integrating the same absorption equation as calculateMichaelisMentenConcentration:
+//
+//	dGut/dt     = -Vmax*Gut / (Km + Gut)
+//	dCentral/dt =  Vmax*Gut / (Km + Gut) - ke*Central
+//
+// Unlike the closed form, the peak emerges from the dynamics instead of the stored Tmax.
+// Without Vmax and Km it reduces to the one-compartment model.
+type michaelisMentenAbsorptionModel struct {
+	vmax, km, ke float64
+}
+
+func newMichaelisMentenAbsorptionModel(pk SupplementPK) CompartmentModel {
+	if pk.Vmax <= 0 || pk.Km <= 0 {
+		return newOneCompartmentModel(pk)
+	}
+	_, ke := absorptionRates(pk)
+	return michaelisMentenAbsorptionModel{vmax: pk.Vmax, km: pk.Km, ke: ke}
+}
+
+func (m michaelisMentenAbsorptionModel) Compartments() int { return 2 }
+
+func (m michaelisMentenAbsorptionModel) Administer(state []float64, dose float64) { state[0] += dose }
+
+func (m michaelisMentenAbsorptionModel) Derivatives(_ float64, a []float64, dadt []float64) {
+	gut := math.Max(a[0], 0)
+	absorbed := m.vmax * gut / (m.km + gut)
+	dadt[0] = -absorbed
+	dadt[1] = absorbed - m.ke*a[1]
+}
+
+func (m michaelisMentenAbsorptionModel) Plasma(state []float64) float64 { return state[1] }
+
+// terminalRate is the slower of elimination and absorption once the gut amount is well
+// below Km, where absorption is first-order at Vmax/Km.
+func (m michaelisMentenAbsorptionModel) terminalRate() float64 {
+	return math.Min(m.vmax/m.km, m.ke)
+}
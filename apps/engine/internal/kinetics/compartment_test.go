@@ -0,0 +1,150 @@
+package kinetics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMMAbsorbedAmount_MatchesNumericalIntegration(t *testing.T) {
+	// The Lambert W closed form must solve dA/dt = -(Vmax * A) / (Km + A)
+	tests := []struct {
+		name string
+		dose float64
+		vmax float64
+		km   float64
+	}{
+		{"below_km", 50, 1.5, 200},
+		{"vitamin_c", 500, 2, 100},
+		{"saturated", 1000, 0.5, 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mm := func(_ float64, a []float64, dadt []float64) {
+				dadt[0] = -tt.vmax * a[0] / (tt.km + a[0])
+			}
+
+			times := []float64{5, 30, 60, 120, 240, 480}
+			states, err := IntegrateRK45(mm, 0, []float64{tt.dose}, times, RK45Options{})
+			if err != nil {
+				t.Fatalf("IntegrateRK45 returned error: %v", err)
+			}
+
+			for i, at := range times {
+				closedForm := calculateMMAbsorbedAmount(tt.dose, tt.vmax, tt.km, at)
+				if !approxEqual(closedForm, states[i][0], 1e-6*tt.dose) {
+					t.Errorf("A(%v) = %v (Lambert W), want %v (RK45)", at, closedForm, states[i][0])
+				}
+			}
+		})
+	}
+}
+
+func TestOneCompartmentModel_MatchesBateman(t *testing.T) {
+	for _, minutes := range []float64{15, 45, 90, 200, 600, 1200} {
+		closedForm := CalculateConcentration(ConcentrationParams{
+			Dose:                  100,
+			MinutesSinceIngestion: minutes,
+			PK:                    SupplementPK{KineticsType: OneCompartment, PeakMinutes: 90, HalfLifeMinutes: 360},
+		})
+		numerical := CalculateConcentration(ConcentrationParams{
+			Dose:                  100,
+			MinutesSinceIngestion: minutes,
+			PK:                    SupplementPK{PeakMinutes: 90, HalfLifeMinutes: 360, CompartmentModel: "one_compartment"},
+		})
+
+		// The numerical peak is located on a grid, so allow a small normalization error
+		if !approxEqual(numerical, closedForm, 0.05) {
+			t.Errorf("t=%v: ODE = %v, Bateman = %v", minutes, numerical, closedForm)
+		}
+	}
+}
+
+func TestTwoCompartmentModel_BiphasicDecline(t *testing.T) {
+	pk := SupplementPK{
+		PeakMinutes:     30,
+		HalfLifeMinutes: 240,
+		Compartment:     CompartmentParams{K12: 0.02, K21: 0.002},
+	}
+	model, ok := LookupCompartmentModel("two_compartment")
+	if !ok {
+		t.Fatal("two_compartment model not registered")
+	}
+
+	times := []float64{120, 180, 1200, 1260}
+	plasma, err := SimulateCompartmentModel(model(pk), 100, times)
+	if err != nil {
+		t.Fatalf("SimulateCompartmentModel returned error: %v", err)
+	}
+
+	// Distribution phase declines faster than the terminal phase
+	early := math.Log(plasma[0]/plasma[1]) / 60
+	late := math.Log(plasma[2]/plasma[3]) / 60
+	if early <= 2*late {
+		t.Errorf("early slope %v should be much steeper than terminal slope %v", early, late)
+	}
+}
+
+func TestEnterohepaticModel_ProlongsExposure(t *testing.T) {
+	pk := SupplementPK{PeakMinutes: 60, HalfLifeMinutes: 120}
+	base, _ := LookupCompartmentModel("one_compartment")
+	recirculating, _ := LookupCompartmentModel("enterohepatic")
+
+	times := []float64{720}
+	withoutBile, err := SimulateCompartmentModel(base(pk), 100, times)
+	if err != nil {
+		t.Fatalf("SimulateCompartmentModel returned error: %v", err)
+	}
+	withBile, err := SimulateCompartmentModel(recirculating(pk), 100, times)
+	if err != nil {
+		t.Fatalf("SimulateCompartmentModel returned error: %v", err)
+	}
+
+	if withBile[0] <= withoutBile[0] {
+		t.Errorf("enterohepatic plasma at 12h = %v, want more than %v", withBile[0], withoutBile[0])
+	}
+}
+
+func TestCompartmentModel_MassBalance(t *testing.T) {
+	// Elimination only removes drug, so the total never exceeds the dose
+	pk := SupplementPK{PeakMinutes: 60, HalfLifeMinutes: 240, Vmax: 2, Km: 100}
+
+	for _, name := range CompartmentModelNames() {
+		t.Run(name, func(t *testing.T) {
+			factory, _ := LookupCompartmentModel(name)
+			model := factory(pk)
+
+			state := make([]float64, model.Compartments())
+			model.Administer(state, 100)
+			states, err := IntegrateRK45(model.Derivatives, 0, state, []float64{60, 600}, RK45Options{})
+			if err != nil {
+				t.Fatalf("IntegrateRK45 returned error: %v", err)
+			}
+
+			for _, s := range states {
+				total := 0.0
+				for _, amount := range s {
+					if amount < -1e-9 {
+						t.Errorf("negative compartment amount %v", amount)
+					}
+					total += amount
+				}
+				if total > 100+1e-6 {
+					t.Errorf("total amount %v exceeds dose", total)
+				}
+			}
+		})
+	}
+}
+
+func TestCalculateConcentration_UnknownCompartmentModel(t *testing.T) {
+	// Unregistered names fall through to KineticsType
+	params := ConcentrationParams{
+		Dose:                  100,
+		MinutesSinceIngestion: 30,
+		PK:                    SupplementPK{PeakMinutes: 60, HalfLifeMinutes: 240, CompartmentModel: "unknown"},
+	}
+	if result := CalculateConcentration(params); !approxEqual(result, 50, epsilon) {
+		t.Errorf("concentration = %v, want 50 (first-order)", result)
+	}
+}
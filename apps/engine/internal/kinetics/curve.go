@@ -0,0 +1,223 @@
+package kinetics
+
+import (
+	"math"
+	"sort"
+)
+
+// CalculateConcentrationCurve returns CalculateConcentration at each of times (minutes
+// since ingestion), evaluating the whole curve in one pass. params.MinutesSinceIngestion
+// is ignored.
+//
+// Route, meal and calibration adjustments and model constants (k, ka, the normalizing
+// Cmax) are computed once per curve instead of once per point. Michaelis-Menten samples
+// warm-start each Lambert W iteration from the previous sample's solution, and compartment
+// models are integrated once over the whole grid.
+func CalculateConcentrationCurve(params ConcentrationParams, times []float64) []float64 {
+	out := make([]float64, len(times))
+	fillConcentrationCurve(params, times, out)
+	return out
+}
+
+// CalculateMultiDoseConcentrationCurve returns CalculateMultiDoseConcentration at each of
+// times, superimposing the curve of every dose.
+func CalculateMultiDoseConcentrationCurve(doses []DoseEvent, times []float64) []float64 {
+	total := make([]float64, len(times))
+	shifted := make([]float64, len(times))
+	curve := make([]float64, len(times))
+
+	for _, d := range doses {
+		for i, t := range times {
+			shifted[i] = t - d.AtMinutes
+		}
+		fillConcentrationCurve(ConcentrationParams{
+			Dose:  d.Dose,
+			PK:    d.PK,
+			Route: d.Route,
+			Meal:  d.Meal,
+		}, shifted, curve)
+		for i, c := range curve {
+			total[i] += c
+		}
+	}
+	return total
+}
+
+// fillConcentrationCurve writes CalculateConcentration at each of times into out.
+func fillConcentrationCurve(params ConcentrationParams, times []float64, out []float64) {
+	params, exposure := adjustParams(params)
+
+	if factory, ok := LookupCompartmentModel(params.PK.CompartmentModel); ok {
+		fillCompartmentCurve(params, factory, times, out)
+	} else {
+		switch params.PK.KineticsType {
+		case MichaelisMenten:
+			fillMichaelisMentenCurve(params, times, out)
+		case OneCompartment:
+			fillOneCompartmentCurve(params, times, out)
+		default:
+			fillFirstOrderCurve(params, times, out)
+		}
+	}
+
+	if exposure != 1 {
+		for i := range out {
+			out[i] *= exposure
+		}
+	}
+}
+
+// fillFirstOrderCurve is the curve form of calculateFirstOrderConcentration.
+func fillFirstOrderCurve(params ConcentrationParams, times []float64, out []float64) {
+	tmax := peakMinutesOrDefault(params.PK)
+	k := math.Log(2) / halfLifeOrDefault(params.PK)
+
+	for i, t := range times {
+		switch {
+		case t < 0:
+			out[i] = 0
+		case t < tmax:
+			out[i] = (t / tmax) * 100
+		case t == tmax:
+			out[i] = 100
+		default:
+			concentration := 100 * math.Exp(-k*(t-tmax))
+			if concentration < 1 {
+				concentration = 0
+			}
+			out[i] = concentration
+		}
+	}
+}
+
+// fillOneCompartmentCurve is the curve form of calculateOneCompartmentConcentration.
+func fillOneCompartmentCurve(params ConcentrationParams, times []float64, out []float64) {
+	tmax := peakMinutesOrDefault(params.PK)
+	halfLife := halfLifeOrDefault(params.PK)
+	ke := math.Log(2) / halfLife
+	ka := params.PK.AbsorptionRateConstant
+	if ka <= 0 {
+		ka = DeriveAbsorptionRateConstant(tmax, halfLife)
+	}
+	peak := batemanShape(ka, ke, batemanPeakTime(ka, ke))
+
+	for i, t := range times {
+		if t < 0 {
+			out[i] = 0
+			continue
+		}
+		concentration := 100 * batemanShape(ka, ke, t) / peak
+		if concentration < 1 && t > tmax {
+			concentration = 0
+		}
+		out[i] = concentration
+	}
+}
+
+// fillMichaelisMentenCurve is the curve form of calculateMichaelisMentenConcentration.
+// The absorption-phase Lambert W solve starts from the previous sample's W.
+func fillMichaelisMentenCurve(params ConcentrationParams, times []float64, out []float64) {
+	pk := params.PK
+	if pk.Vmax <= 0 || pk.Km <= 0 {
+		fillFirstOrderCurve(params, times, out)
+		return
+	}
+
+	tmax := peakMinutesOrDefault(pk)
+	k := math.Log(2) / halfLifeOrDefault(pk)
+	maxConcentration := calculateMMAbsorbedAmount(params.Dose, pk.Vmax, pk.Km, tmax)
+
+	w := math.NaN()
+	for i, t := range times {
+		switch {
+		case t < 0 || maxConcentration <= 0:
+			out[i] = 0
+		case t < tmax:
+			var amount float64
+			amount, w = mmAbsorbedAmount(params.Dose, pk.Vmax, pk.Km, t, w)
+			out[i] = (amount / maxConcentration) * 100
+		default:
+			concentration := 100 * math.Exp(-k*(t-tmax))
+			if concentration < 1 {
+				concentration = 0
+			}
+			out[i] = concentration
+		}
+	}
+}
+
+// fillCompartmentCurve writes calculateCompartmentConcentration at each of times into
+// out. The model is integrated once over the sample times and a uniform Cmax search grid
+// over [0, Tmax + 4 t½]; Cmax is the largest plasma amount on either.
+// Falls back to first-order kinetics if integration fails.
+func fillCompartmentCurve(params ConcentrationParams, factory CompartmentModelFactory, times []float64, out []float64) {
+	tmax := peakMinutesOrDefault(params.PK)
+	horizon := tmax + compartmentPeakHorizonHalfLives*halfLifeOrDefault(params.PK)
+
+	grid := make([]float64, 0, compartmentPeakSamples+len(times))
+	for i := 1; i <= compartmentPeakSamples; i++ {
+		grid = append(grid, horizon*float64(i)/compartmentPeakSamples)
+	}
+	for _, t := range times {
+		if t >= 0 {
+			grid = append(grid, t)
+		}
+	}
+	grid = mergeOutputTimes(grid)
+
+	dose := params.Dose
+	if dose <= 0 {
+		// The percentage is dose-independent for linear models; use a unit dose
+		dose = 1
+	}
+
+	plasma, err := SimulateCompartmentModel(factory(params.PK), dose, grid)
+	if err != nil {
+		fillFirstOrderCurve(params, times, out)
+		return
+	}
+
+	peak := 0.0
+	for _, p := range plasma {
+		peak = math.Max(peak, p)
+	}
+
+	for i, t := range times {
+		if t < 0 || peak <= 0 {
+			out[i] = 0
+			continue
+		}
+		concentration := 100 * plasma[nearestOutputTime(grid, t)] / peak
+		if concentration < 1 && t > tmax {
+			concentration = 0
+		}
+		out[i] = concentration
+	}
+}
+
+// outputTimeResolution is the relative spacing below which output times are merged,
+// keeping the integrator from stepping between times that differ only by rounding.
+const outputTimeResolution = 1e-9
+
+// mergeOutputTimes sorts times in place and drops those within outputTimeResolution of
+// the previous one.
+func mergeOutputTimes(times []float64) []float64 {
+	sort.Float64s(times)
+	merged := times[:0]
+	for _, t := range times {
+		if n := len(merged); n > 0 && t-merged[n-1] <= outputTimeResolution*math.Max(t, 1) {
+			continue
+		}
+		merged = append(merged, t)
+	}
+	return merged
+}
+
+// nearestOutputTime returns the index of the time in the sorted grid closest to t.
+func nearestOutputTime(grid []float64, t float64) int {
+	at := sort.SearchFloat64s(grid, t)
+	if at == len(grid) || (at > 0 && t-grid[at-1] < grid[at]-t) {
+		return at - 1
+	}
+	return at
+}
@@ -0,0 +1,195 @@
+package kinetics
+
+import (
+	"math"
+	"testing"
+)
+
+// curveTestCases cover every model and adjustment path of CalculateConcentration.
+var curveTestCases = []struct {
+	name   string
+	params ConcentrationParams
+}{
+	{"first order", ConcentrationParams{Dose: 100, PK: SupplementPK{KineticsType: FirstOrder, PeakMinutes: 60, HalfLifeMinutes: 240}}},
+	{"first order with RDA dampening", ConcentrationParams{Dose: 2000, PK: SupplementPK{KineticsType: FirstOrder, PeakMinutes: 60, HalfLifeMinutes: 240, RDAAmount: 90}}},
+	{"one compartment", ConcentrationParams{Dose: 200, PK: SupplementPK{KineticsType: OneCompartment, PeakMinutes: 45, HalfLifeMinutes: 300}}},
+	{"michaelis-menten", ConcentrationParams{Dose: 1000, PK: SupplementPK{KineticsType: MichaelisMenten, PeakMinutes: 180, HalfLifeMinutes: 120, Vmax: 2.5, Km: 200}}},
+	{"michaelis-menten saturated", ConcentrationParams{Dose: 20000, PK: SupplementPK{KineticsType: MichaelisMenten, PeakMinutes: 180, HalfLifeMinutes: 120, Vmax: 2.5, Km: 200}}},
+	{"route, meal and calibration", ConcentrationParams{
+		Dose:  100,
+		Route: RouteSublingual,
+		Meal:  MealEffect{BioavailabilityFactor: 1.5, PeakFactor: 1.2},
+		PK: SupplementPK{
+			KineticsType: OneCompartment, PeakMinutes: 60, HalfLifeMinutes: 240, BioavailabilityPercent: 40,
+			Calibration: Calibration{BioavailabilityFactor: 0.8, ClearanceFactor: 1.25},
+		},
+	}},
+}
+
+func minuteGrid(from, to float64) []float64 {
+	times := make([]float64, 0, int(to-from)+1)
+	for t := from; t <= to; t++ {
+		times = append(times, t)
+	}
+	return times
+}
+
+func TestCalculateConcentrationCurve_MatchesPointwise(t *testing.T) {
+	times := minuteGrid(-30, 1440)
+
+	for _, tc := range curveTestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			curve := CalculateConcentrationCurve(tc.params, times)
+			for i, minutes := range times {
+				params := tc.params
+				params.MinutesSinceIngestion = minutes
+				want := CalculateConcentration(params)
+				if !approxEqual(curve[i], want, 1e-9) {
+					t.Fatalf("t=%v: curve %v, pointwise %v", minutes, curve[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestCalculateConcentrationCurve_UnorderedTimes(t *testing.T) {
+	params := curveTestCases[3].params
+	times := []float64{120, 10, 600, 45, 45, -5, 179}
+
+	curve := CalculateConcentrationCurve(params, times)
+	for i, minutes := range times {
+		params.MinutesSinceIngestion = minutes
+		if want := CalculateConcentration(params); !approxEqual(curve[i], want, 1e-9) {
+			t.Errorf("t=%v: curve %v, pointwise %v", minutes, curve[i], want)
+		}
+	}
+}
+
+func TestCalculateConcentrationCurve_CompartmentModel(t *testing.T) {
+	params := ConcentrationParams{Dose: 100, PK: SupplementPK{CompartmentModel: "two_compartment", PeakMinutes: 60, HalfLifeMinutes: 240}}
+	times := minuteGrid(0, 1440)
+
+	curve := CalculateConcentrationCurve(params, times)
+	for i := 0; i < len(times); i += 60 {
+		params.MinutesSinceIngestion = times[i]
+		want := CalculateConcentration(params)
+		// Cmax comes from a denser grid, so allow for the grid's peak resolution
+		if math.Abs(curve[i]-want) > 0.5 {
+			t.Errorf("t=%v: curve %v, pointwise %v", times[i], curve[i], want)
+		}
+	}
+}
+
+func TestCalculateConcentrationCurve_CompartmentNearlyEqualTimes(t *testing.T) {
+	// Times differing only by rounding must not make the integrator underflow and fall
+	// back to first-order kinetics
+	pk := SupplementPK{PeakMinutes: 60, HalfLifeMinutes: 240}
+	closedForm := pk
+	closedForm.KineticsType = OneCompartment
+	compartment := pk
+	compartment.CompartmentModel = "one_compartment"
+
+	times := []float64{30, 100, 100 + 1e-13, 100 - 1e-13}
+	curve := CalculateConcentrationCurve(ConcentrationParams{Dose: 100, PK: compartment}, times)
+	for i, minutes := range times {
+		want := CalculateConcentration(ConcentrationParams{Dose: 100, MinutesSinceIngestion: minutes, PK: closedForm})
+		if math.Abs(curve[i]-want) > 0.5 {
+			t.Errorf("t=%v: compartment curve %v, Bateman %v", minutes, curve[i], want)
+		}
+	}
+}
+
+func TestCalculateMultiDoseConcentrationCurve_MatchesPointwise(t *testing.T) {
+	doses := []DoseEvent{
+		{Dose: 100, AtMinutes: -120, PK: SupplementPK{KineticsType: OneCompartment, PeakMinutes: 45, HalfLifeMinutes: 300}},
+		{Dose: 500, AtMinutes: 300, PK: SupplementPK{KineticsType: MichaelisMenten, PeakMinutes: 180, HalfLifeMinutes: 120, Vmax: 2.5, Km: 200}},
+		{Dose: 100, AtMinutes: 600, PK: SupplementPK{PeakMinutes: 60, HalfLifeMinutes: 240}, Meal: MealEffect{BioavailabilityFactor: 0.7}},
+	}
+	times := minuteGrid(0, 1440)
+
+	curve := CalculateMultiDoseConcentrationCurve(doses, times)
+	for i, minutes := range times {
+		if want := CalculateMultiDoseConcentration(doses, minutes); !approxEqual(curve[i], want, 1e-9) {
+			t.Fatalf("t=%v: curve %v, pointwise %v", minutes, curve[i], want)
+		}
+	}
+}
+
+func TestCalculateMultiDosePlasmaConcentrationCurve(t *testing.T) {
+	doses := []DoseEvent{
+		{Dose: 100, PK: SupplementPK{KineticsType: OneCompartment, PeakMinutes: 45, HalfLifeMinutes: 300, VolumeOfDistribution: 0.6}},
+		{Dose: 100, AtMinutes: 240, PK: SupplementPK{KineticsType: OneCompartment, PeakMinutes: 45, HalfLifeMinutes: 300, VolumeOfDistribution: 0.6}},
+	}
+	times := minuteGrid(0, 720)
+
+	curve, err := CalculateMultiDosePlasmaConcentrationCurve(doses, times, 80)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i, minutes := range times {
+		want, _ := CalculateMultiDosePlasmaConcentration(doses, minutes, 80)
+		if !approxEqual(curve[i], want, 1e-9) {
+			t.Fatalf("t=%v: curve %v, pointwise %v", minutes, curve[i], want)
+		}
+	}
+
+	doses[1].PK.VolumeOfDistribution = 0
+	if _, err := CalculateMultiDosePlasmaConcentrationCurve(doses, times, 80); err != ErrMissingVolume {
+		t.Errorf("Expected ErrMissingVolume, got %v", err)
+	}
+}
+
+func TestLambertW0From_WarmStart(t *testing.T) {
+	for _, x := range []float64{1e-6, 0.3, 1, math.E, 50, 1e8} {
+		want := LambertW0(x)
+		for _, guess := range []float64{math.NaN(), -2, want * 0.9, want + 1} {
+			if got := lambertW0From(x, guess); !approxEqual(got, want, 1e-9) {
+				t.Errorf("lambertW0From(%v, %v) = %v, want %v", x, guess, got, want)
+			}
+		}
+	}
+}
+
+// benchmarkTimeline models a 24h timeline at one-minute resolution across 20 supplements
+// with three doses each.
+func benchmarkTimeline() ([][]DoseEvent, []float64) {
+	times := minuteGrid(0, 1440)
+	supplements := make([][]DoseEvent, 20)
+	for s := range supplements {
+		var pk SupplementPK
+		switch s % 3 {
+		case 0:
+			pk = SupplementPK{KineticsType: FirstOrder, PeakMinutes: 60 + float64(s), HalfLifeMinutes: 240, RDAAmount: 100}
+		case 1:
+			pk = SupplementPK{KineticsType: OneCompartment, PeakMinutes: 45 + float64(s), HalfLifeMinutes: 300}
+		default:
+			pk = SupplementPK{KineticsType: MichaelisMenten, PeakMinutes: 180, HalfLifeMinutes: 120, Vmax: 2.5, Km: 200}
+		}
+		for d := 0; d < 3; d++ {
+			supplements[s] = append(supplements[s], DoseEvent{Dose: 500, AtMinutes: float64(d * 480), PK: pk, Meal: MealEffect{PeakFactor: 1.2}})
+		}
+	}
+	return supplements, times
+}
+
+func BenchmarkTimeline24hPointwise(b *testing.B) {
+	supplements, times := benchmarkTimeline()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for _, doses := range supplements {
+			for _, t := range times {
+				CalculateMultiDoseConcentration(doses, t)
+			}
+		}
+	}
+}
+
+func BenchmarkTimeline24hCurve(b *testing.B) {
+	supplements, times := benchmarkTimeline()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for _, doses := range supplements {
+			CalculateMultiDoseConcentrationCurve(doses, times)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package kinetics
+
+import (
+	"math"
+)
+
+// EnzymeEffect is a supplement's role on a CYP450 enzyme.
+type EnzymeEffect string
+
+const (
+	EnzymeSubstrate EnzymeEffect = "substrate"
+	EnzymeInhibitor EnzymeEffect = "inhibitor"
+	EnzymeInducer   EnzymeEffect = "inducer"
+)
+
+// DefaultEnzymeConfidence matches the cyp450_pathway.confidence_score column default.
+const DefaultEnzymeConfidence = 0.5
+
+// Clearance multipliers of the enzyme pathway at full confidence, by strength.
+// They follow the FDA classification by the perpetrator's effect on substrate AUC:
+// strong inhibitors raise it ≥5x, moderate 2-5x and weak 1.25-2x; strong inducers
+// lower it by ≥80%, moderate by 50-80% and weak by 20-50%. Unknown strengths are
+// treated as weak.
+var (
+	inhibitorClearanceFactors = map[string]float64{"strong": 0.2, "moderate": 0.5, "weak": 0.8}
+	inducerClearanceFactors   = map[string]float64{"strong": 5, "moderate": 2.5, "weak": 1.5}
+)
+
+// EnzymeModulation is a CYP450 inhibitor or inducer acting on one enzyme.
+type EnzymeModulation struct {
+	Enzyme     string       // e.g. "CYP1A2"
+	Effect     EnzymeEffect // EnzymeInhibitor or EnzymeInducer
+	Strength   string       // "strong", "moderate" or "weak"
+	Confidence float64      // Evidence confidence (0-1)
+}
+
+// ClearanceFactor returns the multiplier the modulation applies to clearance through its
+// enzyme. The full-strength factor is scaled geometrically by confidence, so a factor f
+// with confidence c becomes f^c: no evidence leaves clearance unchanged.
+func (m EnzymeModulation) ClearanceFactor() float64 {
+	var factors map[string]float64
+	switch m.Effect {
+	case EnzymeInhibitor:
+		factors = inhibitorClearanceFactors
+	case EnzymeInducer:
+		factors = inducerClearanceFactors
+	default:
+		return 1
+	}
+
+	factor, ok := factors[m.Strength]
+	if !ok {
+		factor = factors["weak"]
+	}
+	confidence := math.Max(0, math.Min(m.Confidence, 1))
+	return math.Pow(factor, confidence)
+}
+
+// MetabolicClearanceFactor returns the multiplier on a substrate's total clearance when the
+// given modulations are present.
+//
+// Without fraction-metabolized data, clearance is assumed to split equally across the
+// substrate's enzymes. Modulations of the same enzyme multiply, and enzymes the substrate
+// does not use are ignored:
+//
+//	CL'/CL = Σ_e (1/n) * Π_m f_m(e)
+func MetabolicClearanceFactor(substrateEnzymes []string, modulations []EnzymeModulation) float64 {
+	if len(substrateEnzymes) == 0 {
+		return 1
+	}
+
+	total := 0.0
+	for _, enzyme := range substrateEnzymes {
+		factor := 1.0
+		for _, m := range modulations {
+			if m.Enzyme == enzyme {
+				factor *= m.ClearanceFactor()
+			}
+		}
+		total += factor
+	}
+	return total / float64(len(substrateEnzymes))
+}
+
+// ApplyClearanceFactor returns pk with its half-life scaled for a clearance change.
+// As with calibration, ke = CL / V, so a clearance factor c scales the half-life by 1/c.
+func ApplyClearanceFactor(pk SupplementPK, factor float64) SupplementPK {
+	if factor <= 0 || factor == 1 {
+		return pk
+	}
+	adjusted := pk
+	adjusted.HalfLifeMinutes = halfLifeOrDefault(pk) / factor
+	return adjusted
+}
@@ -0,0 +1,64 @@
+package kinetics
+
+import (
+	"testing"
+)
+
+func TestEnzymeModulation_ClearanceFactor(t *testing.T) {
+	tests := []struct {
+		name       string
+		modulation EnzymeModulation
+		want       float64
+	}{
+		{"strong inhibitor, full confidence", EnzymeModulation{Effect: EnzymeInhibitor, Strength: "strong", Confidence: 1}, 0.2},
+		{"moderate inducer, full confidence", EnzymeModulation{Effect: EnzymeInducer, Strength: "moderate", Confidence: 1}, 2.5},
+		{"strong inducer, half confidence", EnzymeModulation{Effect: EnzymeInducer, Strength: "strong", Confidence: 0.5}, 2.2360679775},
+		{"unknown strength is weak", EnzymeModulation{Effect: EnzymeInhibitor, Confidence: 1}, 0.8},
+		{"no evidence", EnzymeModulation{Effect: EnzymeInhibitor, Strength: "strong", Confidence: 0}, 1},
+		{"substrate has no effect", EnzymeModulation{Effect: EnzymeSubstrate, Strength: "strong", Confidence: 1}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.modulation.ClearanceFactor(); !approxEqual(got, tt.want, 1e-9) {
+				t.Errorf("ClearanceFactor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetabolicClearanceFactor(t *testing.T) {
+	strongInhibitor := EnzymeModulation{Enzyme: "CYP1A2", Effect: EnzymeInhibitor, Strength: "strong", Confidence: 1}
+	weakInhibitor := EnzymeModulation{Enzyme: "CYP1A2", Effect: EnzymeInhibitor, Strength: "weak", Confidence: 1}
+	inducer := EnzymeModulation{Enzyme: "CYP3A4", Effect: EnzymeInducer, Strength: "moderate", Confidence: 1}
+
+	if got := MetabolicClearanceFactor([]string{"CYP1A2"}, []EnzymeModulation{strongInhibitor}); !approxEqual(got, 0.2, epsilon) {
+		t.Errorf("Single-enzyme substrate should take the full factor, got %v", got)
+	}
+	if got := MetabolicClearanceFactor([]string{"CYP1A2"}, []EnzymeModulation{strongInhibitor, weakInhibitor}); !approxEqual(got, 0.16, epsilon) {
+		t.Errorf("Inhibitors of the same enzyme should multiply, got %v", got)
+	}
+	if got := MetabolicClearanceFactor([]string{"CYP1A2", "CYP3A4"}, []EnzymeModulation{strongInhibitor}); !approxEqual(got, 0.6, epsilon) {
+		t.Errorf("Unaffected enzyme should keep half the clearance, got %v", got)
+	}
+	if got := MetabolicClearanceFactor([]string{"CYP2D6"}, []EnzymeModulation{strongInhibitor, inducer}); got != 1 {
+		t.Errorf("Modulations of other enzymes should be ignored, got %v", got)
+	}
+	if got := MetabolicClearanceFactor(nil, []EnzymeModulation{strongInhibitor}); got != 1 {
+		t.Errorf("Non-substrates should be unaffected, got %v", got)
+	}
+}
+
+func TestApplyClearanceFactor(t *testing.T) {
+	pk := SupplementPK{PeakMinutes: 60, HalfLifeMinutes: 300}
+
+	if adjusted := ApplyClearanceFactor(pk, 0.2); adjusted.HalfLifeMinutes != 1500 {
+		t.Errorf("Inhibited clearance should extend t½ to 1500, got %v", adjusted.HalfLifeMinutes)
+	}
+	if adjusted := ApplyClearanceFactor(pk, 1); adjusted != pk {
+		t.Errorf("Unit factor should leave PK unchanged, got %+v", adjusted)
+	}
+	if adjusted := ApplyClearanceFactor(SupplementPK{}, 2); adjusted.HalfLifeMinutes != DefaultHalfLifeMinutes/2 {
+		t.Errorf("Missing t½ should scale the default, got %v", adjusted.HalfLifeMinutes)
+	}
+}
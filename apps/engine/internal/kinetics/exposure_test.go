@@ -0,0 +1,93 @@
+package kinetics
+
+import (
+	"testing"
+)
+
+func TestCalculateExposureMetrics_SingleDose(t *testing.T) {
+	pk := SupplementPK{KineticsType: FirstOrder, PeakMinutes: 60, HalfLifeMinutes: 300}
+	doses := []DoseEvent{{Dose: 100, AtMinutes: 120, PK: pk}}
+
+	metrics := CalculateExposureMetrics(doses, 0, 1440, 1, 50)
+
+	if !approxEqual(metrics.Cmax, 100, 0.01) {
+		t.Errorf("Cmax = %v, want 100", metrics.Cmax)
+	}
+	if metrics.TmaxMinutes != 180 {
+		t.Errorf("TmaxMinutes = %v, want 180", metrics.TmaxMinutes)
+	}
+
+	// Reference AUC and time above threshold from a 0.1-minute pointwise sum
+	var auc, above float64
+	for t := 0.0; t < 1440; t += 0.1 {
+		c := CalculateMultiDoseConcentration(doses, t+0.05)
+		auc += c * 0.1
+		if c >= 50 {
+			above += 0.1
+		}
+	}
+	if !approxEqual(metrics.AUC, auc, auc*0.001) {
+		t.Errorf("AUC = %v, want %v", metrics.AUC, auc)
+	}
+	if !approxEqual(metrics.MinutesAboveThreshold, above, 0.5) {
+		t.Errorf("MinutesAboveThreshold = %v, want %v", metrics.MinutesAboveThreshold, above)
+	}
+}
+
+func TestCalculateExposureMetrics_OverlappingDoses(t *testing.T) {
+	pk := SupplementPK{KineticsType: FirstOrder, PeakMinutes: 45, HalfLifeMinutes: 300}
+	single := CalculateExposureMetrics([]DoseEvent{{Dose: 100, AtMinutes: 0, PK: pk}}, 0, 1440, 1, 50)
+	double := CalculateExposureMetrics([]DoseEvent{
+		{Dose: 100, AtMinutes: 0, PK: pk},
+		{Dose: 100, AtMinutes: 240, PK: pk},
+	}, 0, 1440, 1, 50)
+
+	if double.Cmax <= single.Cmax {
+		t.Errorf("expected a second dose to raise the peak, got %v vs %v", double.Cmax, single.Cmax)
+	}
+	if double.MinutesAboveThreshold <= single.MinutesAboveThreshold {
+		t.Errorf("expected a second dose to extend time above threshold, got %v vs %v",
+			double.MinutesAboveThreshold, single.MinutesAboveThreshold)
+	}
+}
+
+func TestCalculateExposureMetrics_CarryOver(t *testing.T) {
+	pk := SupplementPK{KineticsType: FirstOrder, PeakMinutes: 60, HalfLifeMinutes: 600}
+
+	// A dose late the previous day still contributes to the next
+	carried := CalculateExposureMetrics([]DoseEvent{{Dose: 100, AtMinutes: -120, PK: pk}}, 0, 1440, 1, 50)
+	if carried.AUC <= 0 || carried.TmaxMinutes != 0 {
+		t.Errorf("expected carried-over exposure peaking at the start, got %+v", carried)
+	}
+
+	// A dose cleared long before the interval is skipped
+	cleared := CalculateExposureMetrics([]DoseEvent{{Dose: 100, AtMinutes: -60 * 1440, PK: pk}}, 0, 1440, 1, 50)
+	if cleared != (ExposureMetrics{TmaxMinutes: 0}) {
+		t.Errorf("expected no exposure from a cleared dose, got %+v", cleared)
+	}
+}
+
+func TestCalculateExposureMetrics_EmptyInterval(t *testing.T) {
+	doses := []DoseEvent{{Dose: 100, PK: SupplementPK{PeakMinutes: 60}}}
+	if metrics := CalculateExposureMetrics(doses, 100, 100, 1, 50); metrics != (ExposureMetrics{}) {
+		t.Errorf("expected zero metrics for an empty interval, got %+v", metrics)
+	}
+}
+
+func TestTimeAbove(t *testing.T) {
+	tests := []struct {
+		a, b, want float64
+	}{
+		{60, 80, 10},
+		{10, 20, 0},
+		{40, 60, 5},
+		{60, 40, 5},
+		{50, 50, 10},
+	}
+
+	for _, tt := range tests {
+		if got := timeAbove(tt.a, tt.b, 10, 50); !approxEqual(got, tt.want, epsilon) {
+			t.Errorf("timeAbove(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 // Package kinetics provides pharmacokinetic modeling functions for supplement absorption.
 //
-// This package implements both first-order (exponential decay) and Michaelis-Menten
-// (capacity-limited) kinetics for accurate plasma concentration modeling.
+// This package implements first-order (exponential decay), one-compartment oral
+// (Bateman) and Michaelis-Menten (capacity-limited) kinetics for accurate plasma
+// concentration modeling, plus an adaptive RK45 integrator for compartment models
+// without a closed-form solution.
 package kinetics
 
 import (
@@ -16,6 +18,15 @@ const (
 	FirstOrder KineticsType = "first_order"
 	// MichaelisMenten uses capacity-limited kinetics for saturable transporters
 	MichaelisMenten KineticsType = "michaelis_menten"
+	// OneCompartment uses the Bateman function for first-order absorption and elimination:
+	// C(t) ∝ e^(-ke*t) - e^(-ka*t)
+	OneCompartment KineticsType = "one_compartment"
+)
+
+// Conservative defaults when PK data is missing
+const (
+	DefaultPeakMinutes     = 60  // 1 hour
+	DefaultHalfLifeMinutes = 240 // 4 hours
 )
 
 // SupplementPK contains pharmacokinetic parameters for a supplement.
@@ -24,15 +35,35 @@ type SupplementPK struct {
 	KineticsType    KineticsType
 	PeakMinutes     float64 // Time to Cmax (Tmax)
 	HalfLifeMinutes float64 // Elimination half-life (t½)
+	Route           Route   // Route the parameters were measured for (oral when empty)
 
 	// First-order specific
 	BioavailabilityPercent float64 // F - fraction absorbed (0-100)
 
+	// Gut transit
+	AbsorptionWindowMinutes float64 // Active absorption period; 2×Tmax when zero
+
+	// Absolute concentrations
+	VolumeOfDistribution float64 // Vd (L/kg); required by CalculatePlasmaConcentration
+
+	// One-compartment specific
+	AbsorptionRateConstant float64 // ka (1/min); derived from PeakMinutes and HalfLifeMinutes when zero
+
 	// Michaelis-Menten specific
 	Vmax                     float64 // Maximum velocity (mg/min)
 	Km                       float64 // Michaelis constant (mg)
 	AbsorptionSaturationDose float64 // Dose above which absorption saturates
 	RDAAmount                float64 // RDA for heuristic dampening
+
+	// Personal factors fitted from the user's blood results
+	Calibration Calibration
+
+	// Between-subject variability used by CalculateConcentrationBands
+	Variability Variability
+
+	// Numerical compartment model; overrides KineticsType when registered
+	CompartmentModel string            // Name registered with RegisterCompartmentModel
+	Compartment      CompartmentParams // Optional model rate constants
 }
 
 // ConcentrationParams contains parameters for concentration calculation.
@@ -40,21 +71,113 @@ type ConcentrationParams struct {
 	Dose                  float64      // Administered dose (mg)
 	MinutesSinceIngestion float64      // Time since intake (minutes)
 	PK                    SupplementPK // Pharmacokinetic parameters
+	Route                 Route        // Route of this dose (PK.Route when empty)
+	Meal                  MealEffect   // Meal context effect on oral absorption
+	BodyWeightKg          float64      // Scales Vd for mg/L output (DefaultBodyWeightKg when zero)
 }
 
 // CalculateConcentration returns the estimated plasma concentration as a percentage of Cmax (0-100).
-// It dispatches to the appropriate kinetic model based on SupplementPK.KineticsType.
+// It dispatches to the appropriate kinetic model based on SupplementPK.KineticsType, or
+// integrates SupplementPK.CompartmentModel numerically when it names a registered model.
+//
+// When Route differs from PK.Route, the parameters are adjusted with AdjustForRoute and the
+// result is expressed relative to the Cmax of the reference route. A Meal effect is then
+// applied with ApplyMealEffect, and the user's PK.Calibration with ApplyCalibration, in the
+// same way.
 func CalculateConcentration(params ConcentrationParams) float64 {
 	if params.MinutesSinceIngestion < 0 {
 		return 0
 	}
 
+	params, exposure := adjustParams(params)
+
+	if factory, ok := LookupCompartmentModel(params.PK.CompartmentModel); ok {
+		return calculateCompartmentConcentration(params, factory) * exposure
+	}
+
 	switch params.PK.KineticsType {
 	case MichaelisMenten:
-		return calculateMichaelisMentenConcentration(params)
+		return calculateMichaelisMentenConcentration(params) * exposure
+	case OneCompartment:
+		return calculateOneCompartmentConcentration(params) * exposure
 	default:
-		return calculateFirstOrderConcentration(params)
+		return calculateFirstOrderConcentration(params) * exposure
+	}
+}
+
+// adjustParams applies the route, meal and calibration adjustments of params in that
+// order, returning parameters with none left to apply and the exposure factor that scales
+// the model's percent-of-Cmax output. It is shared by CalculateConcentration and
+// CalculateConcentrationCurve so both follow the same chain.
+func adjustParams(params ConcentrationParams) (ConcentrationParams, float64) {
+	exposure := 1.0
+	if params.Route != "" && params.Route != params.PK.Route {
+		adjusted, factor := AdjustForRoute(params.PK, params.Route)
+		params.PK, params.Route = adjusted, ""
+		exposure *= factor
+	}
+	if params.Meal != (MealEffect{}) {
+		adjusted, factor := ApplyMealEffect(params.PK, params.Meal)
+		params.PK, params.Meal = adjusted, MealEffect{}
+		exposure *= factor
 	}
+	if params.PK.Calibration != (Calibration{}) {
+		adjusted, factor := ApplyCalibration(params.PK)
+		params.PK = adjusted
+		exposure *= factor
+	}
+
+	// Supplements with an RDA but no MM parameters still saturate at high doses,
+	// so scale the first-order curve by the heuristic dampening factor
+	if usesRDADampening(params.PK) && params.Dose > 0 {
+		exposure *= ApplyAbsorptionDampening(params.Dose, params.PK.RDAAmount) / params.Dose
+	}
+
+	return params, exposure
+}
+
+// usesRDADampening reports whether pk is evaluated with first-order kinetics and has an
+// RDA to dampen by.
+func usesRDADampening(pk SupplementPK) bool {
+	if _, ok := LookupCompartmentModel(pk.CompartmentModel); ok {
+		return false
+	}
+	switch pk.KineticsType {
+	case MichaelisMenten, OneCompartment:
+		return false
+	}
+	return pk.RDAAmount > 0
+}
+
+// DoseEvent is a single administration placed on a shared time axis.
+type DoseEvent struct {
+	Dose      float64      // Administered dose (mg)
+	AtMinutes float64      // Time of intake on the caller's time axis (minutes)
+	PK        SupplementPK // Pharmacokinetic parameters
+	Route     Route        // Route of this dose (PK.Route when empty)
+	Meal      MealEffect   // Meal context effect on oral absorption
+}
+
+// CalculateMultiDoseConcentration returns the combined concentration of several doses
+// at time t (same axis as DoseEvent.AtMinutes), as a percentage of single-dose Cmax.
+//
+// Doses are assumed to act independently, so their curves are superimposed:
+//
+//	C(t) = Σ C_i(t - t_i)
+//
+// The result is not capped; repeated doses can exceed 100%.
+func CalculateMultiDoseConcentration(doses []DoseEvent, t float64) float64 {
+	total := 0.0
+	for _, d := range doses {
+		total += CalculateConcentration(ConcentrationParams{
+			Dose:                  d.Dose,
+			MinutesSinceIngestion: t - d.AtMinutes,
+			PK:                    d.PK,
+			Route:                 d.Route,
+			Meal:                  d.Meal,
+		})
+	}
+	return total
 }
 
 // calculateFirstOrderConcentration uses standard exponential decay kinetics.
@@ -69,10 +192,10 @@ func calculateFirstOrderConcentration(params ConcentrationParams) float64 {
 
 	// Use defaults if not specified
 	if tmax <= 0 {
-		tmax = 60 // Default 1 hour
+		tmax = DefaultPeakMinutes
 	}
 	if halfLife <= 0 {
-		halfLife = 240 // Default 4 hours
+		halfLife = DefaultHalfLifeMinutes
 	}
 
 	// Absorption phase (linear ramp to peak)
@@ -97,6 +220,113 @@ func calculateFirstOrderConcentration(params ConcentrationParams) float64 {
 	return concentration
 }
 
+// calculateOneCompartmentConcentration uses the one-compartment oral (Bateman) model.
+//
+//	C(t) = F*D*ka / (V*(ka - ke)) * (e^(-ke*t) - e^(-ka*t))
+//
+// where ke = ln(2) / t½. The curve is normalized to its value at
+// tmax = ln(ka/ke) / (ka - ke), so dose, F and V cancel out of the percentage.
+func calculateOneCompartmentConcentration(params ConcentrationParams) float64 {
+	t := params.MinutesSinceIngestion
+	tmax := params.PK.PeakMinutes
+	halfLife := params.PK.HalfLifeMinutes
+
+	if tmax <= 0 {
+		tmax = DefaultPeakMinutes
+	}
+	if halfLife <= 0 {
+		halfLife = DefaultHalfLifeMinutes
+	}
+
+	ke := math.Log(2) / halfLife
+	ka := params.PK.AbsorptionRateConstant
+	if ka <= 0 {
+		ka = DeriveAbsorptionRateConstant(tmax, halfLife)
+	}
+
+	concentration := 100 * batemanShape(ka, ke, t) / batemanShape(ka, ke, batemanPeakTime(ka, ke))
+
+	// Consider cleared when below 1% after the peak
+	if concentration < 1 && t > tmax {
+		return 0
+	}
+	return concentration
+}
+
+// batemanShape returns the unscaled Bateman curve e^(-ke*t) - e^(-ka*t), divided by
+// (ka - ke) so that it stays positive for flip-flop kinetics (ka < ke).
+// When ka == ke it uses the limiting form t * e^(-k*t).
+func batemanShape(ka, ke, t float64) float64 {
+	if t <= 0 {
+		return 0
+	}
+	if math.Abs(ka-ke) < 1e-12*ke {
+		return t * math.Exp(-ke*t)
+	}
+	return (math.Exp(-ke*t) - math.Exp(-ka*t)) / (ka - ke)
+}
+
+// batemanPeakTime returns tmax = ln(ka/ke) / (ka - ke), or 1/k when ka == ke.
+func batemanPeakTime(ka, ke float64) float64 {
+	if math.Abs(ka-ke) < 1e-12*ke {
+		return 1 / ke
+	}
+	return math.Log(ka/ke) / (ka - ke)
+}
+
+// DeriveAbsorptionRateConstant returns the absorption rate constant ka (1/min) that puts
+// the Bateman peak at peakMinutes for the given elimination half-life.
+//
+// It solves ln(ka/ke) / (ka - ke) = tmax. Writing r = ka/ke this becomes
+// ln(r) / (r - 1) = tmax * ke, which decreases monotonically in r and is solved by
+// bisection. Peaks earlier than 1/ke give r > 1; later peaks give flip-flop kinetics
+// (r < 1, absorption slower than elimination), common for slow-release forms.
+func DeriveAbsorptionRateConstant(peakMinutes, halfLifeMinutes float64) float64 {
+	if peakMinutes <= 0 || halfLifeMinutes <= 0 {
+		return 0
+	}
+
+	ke := math.Log(2) / halfLifeMinutes
+	target := peakMinutes * ke
+	if target == 1 {
+		return ke
+	}
+
+	// g(r) = ln(r)/(r-1) decreases from +∞ (r→0) through 1 (r→1) towards 0 (r→∞)
+	g := func(r float64) float64 { return math.Log(r) / (r - 1) }
+
+	var lo, hi float64
+	if target < 1 {
+		lo, hi = 1.0, 2.0
+		for g(hi) > target {
+			lo = hi
+			hi *= 2
+		}
+	} else {
+		// r ≈ e^-target for large targets; stop if it underflows (an unbounded Tmax)
+		lo, hi = 0.5, 1.0
+		for lo > 0 && g(lo) <= target {
+			hi = lo
+			lo /= 2
+		}
+	}
+
+	const maxIterations = 200
+	for i := 0; i < maxIterations; i++ {
+		mid := (lo + hi) / 2
+		if g(mid) > target {
+			lo = mid
+		} else {
+			hi = mid
+		}
+		if hi-lo < 1e-12*hi {
+			break
+		}
+	}
+
+	return ke * (lo + hi) / 2
+}
+
 // calculateMichaelisMentenConcentration uses capacity-limited kinetics.
 //
 // For supplements with saturable transporters (Vitamin C, Magnesium, Iron),
@@ -125,10 +355,10 @@ func calculateMichaelisMentenConcentration(params ConcentrationParams) float64 {
 
 	// Use defaults for peak/halflife if not specified
 	if tmax <= 0 {
-		tmax = 60
+		tmax = DefaultPeakMinutes
 	}
 	if halfLife <= 0 {
-		halfLife = 240
+		halfLife = DefaultHalfLifeMinutes
 	}
 
 	// Calculate effective absorbed amount using MM absorption
@@ -165,8 +395,16 @@ func calculateMichaelisMentenConcentration(params ConcentrationParams) float64 {
 //
 //	A(t) = Km * W((A0/Km) * e^((A0 - Vmax*t)/Km))
 func calculateMMAbsorbedAmount(initialDose, vmax, km, minutes float64) float64 {
+	amount, _ := mmAbsorbedAmount(initialDose, vmax, km, minutes, math.NaN())
+	return amount
+}
+
+// mmAbsorbedAmount is calculateMMAbsorbedAmount with an initial guess for W (NaN for the
+// default). It also returns the solved W, or NaN when none was needed, so curves can warm-start
+// the next sample.
+func mmAbsorbedAmount(initialDose, vmax, km, minutes, wGuess float64) (float64, float64) {
 	if initialDose <= 0 || minutes <= 0 {
-		return 0
+		return 0, math.NaN()
 	}
 
 	// Calculate the argument for Lambert W
@@ -177,25 +415,25 @@ func calculateMMAbsorbedAmount(initialDose, vmax, km, minutes float64) float64 {
 
 	// Handle edge cases
 	if x < 0 {
-		return 0 // All absorbed/cleared
+		return 0, math.NaN() // All absorbed/cleared
 	}
 	if math.IsInf(x, 1) {
-		return initialDose // Very early in absorption
+		return initialDose, math.NaN() // Very early in absorption
 	}
 
 	// Calculate W(x) and return Km * W(x)
-	w := LambertW0(x)
+	w := lambertW0From(x, wGuess)
 	result := km * w
 
 	// Can't absorb more than we started with
 	if result > initialDose {
-		return initialDose
+		return initialDose, w
 	}
 	if result < 0 {
-		return 0
+		return 0, w
 	}
 
-	return result
+	return result, w
 }
 
 // LambertW0 computes the principal branch (W₀) of the Lambert W function.
@@ -226,21 +464,37 @@ func LambertW0(x float64) float64 {
 		return -1
 	}
 
-	// Initial guess
-	var w float64
+	return halleyLambertW(x, initialLambertWGuess(x))
+}
+
+// lambertW0From computes LambertW0 starting the Halley iteration from guess, typically W at
+// a neighbouring x, which converges in fewer iterations than the default approximation.
+// Invalid guesses (NaN or not above -1) fall back to LambertW0.
+func lambertW0From(x, guess float64) float64 {
+	if math.IsNaN(guess) || guess <= -1 || math.IsNaN(x) || x <= 0 {
+		return LambertW0(x)
+	}
+	return halleyLambertW(x, guess)
+}
+
+// initialLambertWGuess approximates W0(x) for x > -1/e.
+func initialLambertWGuess(x float64) float64 {
 	if x < 1 {
 		// For small x, use linear approximation
-		w = x
-	} else if x < 10 {
+		return x
+	}
+	if x < 10 {
 		// For moderate x
-		w = math.Log(x)
-	} else {
-		// For large x, use asymptotic expansion
-		lnx := math.Log(x)
-		lnlnx := math.Log(lnx)
-		w = lnx - lnlnx + lnlnx/lnx
+		return math.Log(x)
 	}
+	// For large x, use asymptotic expansion
+	lnx := math.Log(x)
+	lnlnx := math.Log(lnx)
+	return lnx - lnlnx + lnlnx/lnx
+}
 
+// halleyLambertW refines w towards W0(x) with Halley's method.
+func halleyLambertW(x, w float64) float64 {
 	// Halley's method iteration
 	const maxIterations = 50
 	const tolerance = 1e-12
@@ -191,6 +191,159 @@ func TestFirstOrderConcentration_BeforeIngestion(t *testing.T) {
 	}
 }
 
+// ============================================================================
+// One-Compartment (Bateman) Kinetics Tests
+// ============================================================================
+
+func TestDeriveAbsorptionRateConstant_PeaksAtTmax(t *testing.T) {
+	tests := []struct {
+		name     string
+		tmax     float64
+		halfLife float64
+	}{
+		{"caffeine", 45, 300},
+		{"magnesium", 120, 720},
+		{"vitamin d3", 720, 21600},
+		{"fast absorption", 15, 60},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ke := math.Log(2) / tt.halfLife
+			ka := DeriveAbsorptionRateConstant(tt.tmax, tt.halfLife)
+			if ka <= ke {
+				t.Fatalf("ka (%v) should exceed ke (%v)", ka, ke)
+			}
+
+			peak := math.Log(ka/ke) / (ka - ke)
+			if !approxEqual(peak, tt.tmax, 1e-6) {
+				t.Errorf("Derived ka puts peak at %v, want %v", peak, tt.tmax)
+			}
+		})
+	}
+}
+
+func TestDeriveAbsorptionRateConstant_FlipFlop(t *testing.T) {
+	// tmax > 1/ke is reached with absorption slower than elimination (ka < ke)
+	halfLife := 60.0
+	ke := math.Log(2) / halfLife
+	ka := DeriveAbsorptionRateConstant(200, halfLife)
+	if ka <= 0 || ka >= ke {
+		t.Fatalf("Expected flip-flop ka in (0, %v), got %v", ke, ka)
+	}
+	if peak := batemanPeakTime(ka, ke); !approxEqual(peak, 200, 1e-6) {
+		t.Errorf("Derived curve should peak at Tmax 200, got %v", peak)
+	}
+
+	pk := SupplementPK{KineticsType: OneCompartment, PeakMinutes: 200, HalfLifeMinutes: halfLife}
+	maxConc, maxAt := 0.0, 0.0
+	for minute := 0.0; minute <= 600; minute++ {
+		c := CalculateConcentration(ConcentrationParams{Dose: 100, MinutesSinceIngestion: minute, PK: pk})
+		if c > maxConc {
+			maxConc, maxAt = c, minute
+		}
+	}
+	if maxAt != 200 || !approxEqual(maxConc, 100, 1e-6) {
+		t.Errorf("Curve maximum %v at %v minutes, want 100 at 200", maxConc, maxAt)
+	}
+
+	// tmax == 1/ke is the ka == ke limit
+	if ka := DeriveAbsorptionRateConstant(1/ke, halfLife); !approxEqual(ka, ke, epsilon) {
+		t.Errorf("Expected ka == ke (%v), got %v", ke, ka)
+	}
+
+	if DeriveAbsorptionRateConstant(0, 240) != 0 {
+		t.Error("Should return 0 for missing Tmax")
+	}
+}
+
+func TestOneCompartmentConcentration_PeaksAtStoredTmax(t *testing.T) {
+	pk := SupplementPK{
+		KineticsType:    OneCompartment,
+		PeakMinutes:     90,
+		HalfLifeMinutes: 300,
+	}
+
+	peak := CalculateConcentration(ConcentrationParams{Dose: 100, MinutesSinceIngestion: 90, PK: pk})
+	if !approxEqual(peak, 100, 1e-6) {
+		t.Errorf("Concentration at stored Tmax should be 100%%, got %v", peak)
+	}
+
+	// Scan the curve at one-minute resolution: the maximum must be at Tmax
+	maxConc, maxAt := 0.0, 0.0
+	for minute := 0.0; minute <= 600; minute++ {
+		c := CalculateConcentration(ConcentrationParams{Dose: 100, MinutesSinceIngestion: minute, PK: pk})
+		if c > maxConc {
+			maxConc, maxAt = c, minute
+		}
+	}
+	if maxAt != 90 {
+		t.Errorf("Curve maximum at %v minutes, want 90", maxAt)
+	}
+}
+
+func TestOneCompartmentConcentration_SmoothAtTmax(t *testing.T) {
+	pk := SupplementPK{
+		KineticsType:    OneCompartment,
+		PeakMinutes:     60,
+		HalfLifeMinutes: 240,
+	}
+	at := func(minute float64) float64 {
+		return CalculateConcentration(ConcentrationParams{Dose: 100, MinutesSinceIngestion: minute, PK: pk})
+	}
+
+	// Unlike the linear ramp, the slope changes continuously through the peak
+	slopeBefore := at(60) - at(59)
+	slopeAfter := at(61) - at(60)
+	if math.Abs(slopeBefore) > 0.01 || math.Abs(slopeAfter) > 0.01 {
+		t.Errorf("Slope around Tmax should be near zero, got %v and %v", slopeBefore, slopeAfter)
+	}
+
+	// Absorption phase is concave: above the linear ramp
+	if at(30) <= 50 {
+		t.Errorf("Bateman absorption at Tmax/2 should exceed the linear ramp (50%%), got %v", at(30))
+	}
+}
+
+func TestOneCompartmentConcentration_StoredKa(t *testing.T) {
+	halfLife := 240.0
+	ke := math.Log(2) / halfLife
+	ka := 0.05
+	pk := SupplementPK{
+		KineticsType:           OneCompartment,
+		PeakMinutes:            60,
+		HalfLifeMinutes:        halfLife,
+		AbsorptionRateConstant: ka,
+	}
+
+	// The stored ka takes precedence, so the peak moves to ln(ka/ke)/(ka-ke)
+	peakTime := math.Log(ka/ke) / (ka - ke)
+	result := CalculateConcentration(ConcentrationParams{Dose: 100, MinutesSinceIngestion: peakTime, PK: pk})
+	if !approxEqual(result, 100, 1e-6) {
+		t.Errorf("Concentration at ka-derived peak should be 100%%, got %v", result)
+	}
+}
+
+func TestOneCompartmentConcentration_Elimination(t *testing.T) {
+	pk := SupplementPK{
+		KineticsType:    OneCompartment,
+		PeakMinutes:     30,
+		HalfLifeMinutes: 120,
+	}
+
+	// Well past absorption, each half-life halves the concentration
+	c1 := CalculateConcentration(ConcentrationParams{Dose: 100, MinutesSinceIngestion: 480, PK: pk})
+	c2 := CalculateConcentration(ConcentrationParams{Dose: 100, MinutesSinceIngestion: 600, PK: pk})
+	if !approxEqual(c2/c1, 0.5, 0.01) {
+		t.Errorf("Terminal phase should follow t½. Ratio: %v, expected ~0.5", c2/c1)
+	}
+
+	cleared := CalculateConcentration(ConcentrationParams{Dose: 100, MinutesSinceIngestion: 2400, PK: pk})
+	if cleared != 0 {
+		t.Errorf("Concentration should be 0 after clearing, got %v", cleared)
+	}
+}
+
 // ============================================================================
 // Michaelis-Menten Kinetics Tests
 // ============================================================================
@@ -476,3 +629,56 @@ func TestIronScenario_HighDose(t *testing.T) {
 	t.Logf("Iron absorption efficiency - 18mg: %.1f%%, 45mg: %.1f%%, 100mg: %.1f%%",
 		eff18mg*100, eff45mg*100, eff100mg*100)
 }
+
+// ============================================================================
+// Multi-Dose Tests
+// ============================================================================
+
+func TestFirstOrderConcentration_RDADampening(t *testing.T) {
+	pk := SupplementPK{
+		KineticsType:    FirstOrder,
+		PeakMinutes:     60,
+		HalfLifeMinutes: 240,
+		RDAAmount:       100,
+	}
+
+	// Below 3x RDA the curve is unchanged
+	result := CalculateConcentration(ConcentrationParams{Dose: 200, MinutesSinceIngestion: 60, PK: pk})
+	if !approxEqual(result, 100, epsilon) {
+		t.Errorf("Dose below 3x RDA should peak at 100%%, got %v", result)
+	}
+
+	// Above 3x RDA the peak is scaled by the dampening factor
+	result = CalculateConcentration(ConcentrationParams{Dose: 500, MinutesSinceIngestion: 60, PK: pk})
+	expected := 100 * ApplyAbsorptionDampening(500, 100) / 500
+	if !approxEqual(result, expected, epsilon) {
+		t.Errorf("Dampened peak incorrect. Got %v, expected %v", result, expected)
+	}
+}
+
+func TestCalculateMultiDoseConcentration_Superposition(t *testing.T) {
+	pk := SupplementPK{
+		KineticsType:    FirstOrder,
+		PeakMinutes:     60,
+		HalfLifeMinutes: 240,
+	}
+	doses := []DoseEvent{
+		{Dose: 100, AtMinutes: 0, PK: pk},
+		{Dose: 100, AtMinutes: 240, PK: pk},
+	}
+
+	// Before the second dose only the first contributes
+	single := CalculateConcentration(ConcentrationParams{Dose: 100, MinutesSinceIngestion: 120, PK: pk})
+	if result := CalculateMultiDoseConcentration(doses, 120); !approxEqual(result, single, epsilon) {
+		t.Errorf("Expected single-dose concentration %v before second dose, got %v", single, result)
+	}
+
+	// At the second dose's peak: first dose is one half-life past peak (50%) + 100%
+	if result := CalculateMultiDoseConcentration(doses, 300); !approxEqual(result, 150, 0.01) {
+		t.Errorf("Expected combined concentration ~150%%, got %v", result)
+	}
+
+	if result := CalculateMultiDoseConcentration(nil, 300); result != 0 {
+		t.Errorf("Expected 0 with no doses, got %v", result)
+	}
+}
@@ -0,0 +1,45 @@
+package kinetics
+
+import (
+	"math"
+)
+
+// MealEffect describes how the meal context of an oral dose changes absorption.
+// A zero value leaves absorption unchanged.
+type MealEffect struct {
+	BioavailabilityFactor float64 // F multiplier (0 or 1 = no change)
+	PeakFactor            float64 // Tmax multiplier (0 or 1 = no change)
+}
+
+// ApplyMealEffect returns pk adjusted for the meal context of a dose, along with the
+// exposure factor used to scale percent-of-Cmax output.
+//
+// Tmax and F are scaled relative to the stored PK, whose baselines are defined by
+// whoever builds the MealEffect (see bioavailability.PeakFactors). Meals only affect
+// gastrointestinal absorption, so PK for non-oral routes is returned unchanged. As with
+// routes, F is capped at 100% when it is known.
+func ApplyMealEffect(pk SupplementPK, effect MealEffect) (SupplementPK, float64) {
+	if pk.Route != "" && pk.Route != RouteOral {
+		return pk, 1
+	}
+
+	adjusted := pk
+
+	if effect.PeakFactor > 0 && effect.PeakFactor != 1 {
+		adjusted.PeakMinutes = peakMinutesOrDefault(pk) * effect.PeakFactor
+		if pk.AbsorptionRateConstant > 0 {
+			adjusted.AbsorptionRateConstant = pk.AbsorptionRateConstant / effect.PeakFactor
+		}
+	}
+
+	exposure := 1.0
+	if effect.BioavailabilityFactor > 0 {
+		exposure = effect.BioavailabilityFactor
+	}
+	if pk.BioavailabilityPercent > 0 {
+		adjusted.BioavailabilityPercent = math.Min(pk.BioavailabilityPercent*exposure, 100)
+		exposure = adjusted.BioavailabilityPercent / pk.BioavailabilityPercent
+	}
+
+	return adjusted, exposure
+}
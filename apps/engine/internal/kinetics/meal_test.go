@@ -0,0 +1,62 @@
+package kinetics
+
+import (
+	"testing"
+)
+
+func TestApplyMealEffect_ZeroEffectUnchanged(t *testing.T) {
+	pk := SupplementPK{PeakMinutes: 60, HalfLifeMinutes: 240, BioavailabilityPercent: 50}
+
+	adjusted, exposure := ApplyMealEffect(pk, MealEffect{})
+	if adjusted != pk || exposure != 1 {
+		t.Errorf("Zero meal effect should leave PK unchanged, got %+v (exposure %v)", adjusted, exposure)
+	}
+}
+
+func TestApplyMealEffect_DelaysPeakAndScalesExposure(t *testing.T) {
+	pk := SupplementPK{PeakMinutes: 60, HalfLifeMinutes: 240, BioavailabilityPercent: 50}
+
+	adjusted, exposure := ApplyMealEffect(pk, MealEffect{BioavailabilityFactor: 1.47, PeakFactor: 1.75})
+	if adjusted.PeakMinutes != 105 {
+		t.Errorf("Tmax should be delayed to 105, got %v", adjusted.PeakMinutes)
+	}
+	if !approxEqual(adjusted.BioavailabilityPercent, 73.5, epsilon) {
+		t.Errorf("F should be 73.5%%, got %v", adjusted.BioavailabilityPercent)
+	}
+	if !approxEqual(exposure, 1.47, epsilon) {
+		t.Errorf("Exposure should be 1.47, got %v", exposure)
+	}
+
+	// Missing Tmax is scaled from the default
+	adjusted, _ = ApplyMealEffect(SupplementPK{}, MealEffect{PeakFactor: 1.5})
+	if adjusted.PeakMinutes != DefaultPeakMinutes*1.5 {
+		t.Errorf("Missing Tmax should scale the default, got %v", adjusted.PeakMinutes)
+	}
+}
+
+func TestApplyMealEffect_IgnoresNonOralRoutes(t *testing.T) {
+	pk := SupplementPK{PeakMinutes: 30, HalfLifeMinutes: 240, Route: RouteSubQInjection}
+
+	adjusted, exposure := ApplyMealEffect(pk, MealEffect{BioavailabilityFactor: 2, PeakFactor: 1.5})
+	if adjusted != pk || exposure != 1 {
+		t.Errorf("Meal effect should not apply to injections, got %+v (exposure %v)", adjusted, exposure)
+	}
+}
+
+func TestCalculateConcentration_UsesMealEffect(t *testing.T) {
+	pk := SupplementPK{KineticsType: FirstOrder, PeakMinutes: 60, HalfLifeMinutes: 240}
+	meal := MealEffect{BioavailabilityFactor: 2, PeakFactor: 1.5}
+
+	// Peak moves to 90 minutes and doubles relative to the fasted Cmax
+	result := CalculateConcentration(ConcentrationParams{Dose: 100, MinutesSinceIngestion: 90, PK: pk, Meal: meal})
+	if !approxEqual(result, 200, 0.01) {
+		t.Errorf("Expected 200%% at the delayed peak, got %v", result)
+	}
+
+	// Sublingual doses bypass the gut, so the meal has no effect
+	sublingual := CalculateConcentration(ConcentrationParams{Dose: 100, MinutesSinceIngestion: 30, PK: pk, Route: RouteSublingual, Meal: meal})
+	baseline := CalculateConcentration(ConcentrationParams{Dose: 100, MinutesSinceIngestion: 30, PK: pk, Route: RouteSublingual})
+	if !approxEqual(sublingual, baseline, epsilon) {
+		t.Errorf("Meal should not affect sublingual dose. Got %v, want %v", sublingual, baseline)
+	}
+}
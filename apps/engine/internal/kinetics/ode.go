@@ -0,0 +1,160 @@
+package kinetics
+
+import (
+	"errors"
+	"math"
+)
+
+// ODESystem computes the derivatives dy/dt of a system of ordinary differential equations.
+// dydt has the same length as y and must be fully overwritten.
+type ODESystem func(t float64, y []float64, dydt []float64)
+
+// RK45Options controls the adaptive step size of IntegrateRK45.
+// Zero values fall back to the defaults.
+type RK45Options struct {
+	RelTol      float64 // Relative error tolerance (default 1e-8)
+	AbsTol      float64 // Absolute error tolerance (default 1e-10)
+	InitialStep float64 // First trial step (default 1% of the first output interval)
+	MaxStep     float64 // Largest allowed step (default unlimited)
+	MaxSteps    int     // Maximum number of attempted steps (default 100000)
+}
+
+var (
+	ErrMaxSteps        = errors.New("rk45: maximum number of steps exceeded")
+	ErrStepUnderflow   = errors.New("rk45: step size underflow")
+	ErrUnorderedOutput = errors.New("rk45: output times must be ascending and not before t0")
+)
+
+// Dormand–Prince 5(4) Butcher tableau
+var (
+	dpC = [7]float64{0, 1.0 / 5, 3.0 / 10, 4.0 / 5, 8.0 / 9, 1, 1}
+	dpA = [7][6]float64{
+		{},
+		{1.0 / 5},
+		{3.0 / 40, 9.0 / 40},
+		{44.0 / 45, -56.0 / 15, 32.0 / 9},
+		{19372.0 / 6561, -25360.0 / 2187, 64448.0 / 6561, -212.0 / 729},
+		{9017.0 / 3168, -355.0 / 33, 46732.0 / 5247, 49.0 / 176, -5103.0 / 18656},
+		{35.0 / 384, 0, 500.0 / 1113, 125.0 / 192, -2187.0 / 6784, 11.0 / 84},
+	}
+	// 5th-order weights are the last row of dpA; dpE = b5 - b4 estimates the local error
+	dpE = [7]float64{
+		71.0 / 57600, 0, -71.0 / 16695, 71.0 / 1920, -17253.0 / 339200, 22.0 / 525, -1.0 / 40,
+	}
+)
+
+// IntegrateRK45 integrates f from (t0, y0) and returns the state at each of the given times.
+//
+// It uses the Dormand–Prince embedded Runge–Kutta 5(4) pair with the standard
+// step size controller:
+//
+//	err = RMS(e_i / (AbsTol + RelTol * max(|y_i|, |y'_i|)))
+//	h'  = h * clamp(0.9 * err^(-1/5), 0.2, 5)
+//
+// Steps are shortened to land exactly on each output time.
+func IntegrateRK45(f ODESystem, t0 float64, y0 []float64, times []float64, opts RK45Options) ([][]float64, error) {
+	if opts.RelTol <= 0 {
+		opts.RelTol = 1e-8
+	}
+	if opts.AbsTol <= 0 {
+		opts.AbsTol = 1e-10
+	}
+	if opts.MaxSteps <= 0 {
+		opts.MaxSteps = 100000
+	}
+
+	n := len(y0)
+	y := append([]float64(nil), y0...)
+	yNew := make([]float64, n)
+	yStage := make([]float64, n)
+	var k [7][]float64
+	for i := range k {
+		k[i] = make([]float64, n)
+	}
+
+	t := t0
+	h := opts.InitialStep
+	steps := 0
+	fsal := false // k[0] holds f(t, y) from the previous accepted step
+
+	out := make([][]float64, 0, len(times))
+	for _, target := range times {
+		if target < t {
+			return nil, ErrUnorderedOutput
+		}
+		if h <= 0 {
+			h = (target - t) / 100
+		}
+
+		for t < target {
+			if steps >= opts.MaxSteps {
+				return nil, ErrMaxSteps
+			}
+			steps++
+
+			if opts.MaxStep > 0 && h > opts.MaxStep {
+				h = opts.MaxStep
+			}
+			last := false
+			if t+h >= target {
+				h = target - t
+				last = true
+			}
+			if h <= math.Abs(t)*1e-15 {
+				return nil, ErrStepUnderflow
+			}
+
+			if !fsal {
+				f(t, y, k[0])
+			}
+			for s := 1; s < 7; s++ {
+				for i := 0; i < n; i++ {
+					sum := 0.0
+					for j := 0; j < s; j++ {
+						sum += dpA[s][j] * k[j][i]
+					}
+					yStage[i] = y[i] + h*sum
+				}
+				f(t+dpC[s]*h, yStage, k[s])
+			}
+			// The last stage is evaluated at the 5th-order solution
+			copy(yNew, yStage)
+
+			errNorm := 0.0
+			for i := 0; i < n; i++ {
+				e := 0.0
+				for s := 0; s < 7; s++ {
+					e += dpE[s] * k[s][i]
+				}
+				scale := opts.AbsTol + opts.RelTol*math.Max(math.Abs(y[i]), math.Abs(yNew[i]))
+				errNorm += (h * e / scale) * (h * e / scale)
+			}
+			errNorm = math.Sqrt(errNorm / float64(n))
+
+			factor := 5.0
+			if errNorm > 0 {
+				factor = math.Min(5, math.Max(0.2, 0.9*math.Pow(errNorm, -0.2)))
+			}
+
+			if errNorm <= 1 {
+				t += h
+				if last {
+					t = target
+				}
+				copy(y, yNew)
+				k[0], k[6] = k[6], k[0]
+				fsal = true
+				if !last {
+					h *= factor
+				}
+			} else {
+				// y is unchanged, so k[0] remains valid for the retry
+				h *= factor
+			}
+		}
+
+		out = append(out, append([]float64(nil), y...))
+	}
+
+	return out, nil
+}
@@ -0,0 +1,62 @@
+package kinetics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIntegrateRK45_ExponentialDecay(t *testing.T) {
+	// dy/dt = -k*y has the exact solution y0 * e^(-k*t)
+	k := math.Log(2) / 240
+	decay := func(_ float64, y []float64, dydt []float64) {
+		dydt[0] = -k * y[0]
+	}
+
+	times := []float64{0, 60, 240, 1000, 5000}
+	states, err := IntegrateRK45(decay, 0, []float64{100}, times, RK45Options{})
+	if err != nil {
+		t.Fatalf("IntegrateRK45 returned error: %v", err)
+	}
+
+	for i, at := range times {
+		expected := 100 * math.Exp(-k*at)
+		if !approxEqual(states[i][0], expected, 1e-6) {
+			t.Errorf("y(%v) = %v, want %v", at, states[i][0], expected)
+		}
+	}
+}
+
+func TestIntegrateRK45_HarmonicOscillator(t *testing.T) {
+	// y'' = -y with y(0) = 1, y'(0) = 0 gives y = cos(t)
+	oscillator := func(_ float64, y []float64, dydt []float64) {
+		dydt[0] = y[1]
+		dydt[1] = -y[0]
+	}
+
+	times := []float64{math.Pi / 2, math.Pi, 10 * math.Pi}
+	states, err := IntegrateRK45(oscillator, 0, []float64{1, 0}, times, RK45Options{})
+	if err != nil {
+		t.Fatalf("IntegrateRK45 returned error: %v", err)
+	}
+
+	for i, at := range times {
+		if !approxEqual(states[i][0], math.Cos(at), 1e-6) {
+			t.Errorf("y(%v) = %v, want %v", at, states[i][0], math.Cos(at))
+		}
+	}
+}
+
+func TestIntegrateRK45_Errors(t *testing.T) {
+	decay := func(_ float64, y []float64, dydt []float64) {
+		dydt[0] = -y[0]
+	}
+
+	if _, err := IntegrateRK45(decay, 10, []float64{1}, []float64{5}, RK45Options{}); err != ErrUnorderedOutput {
+		t.Errorf("output before t0: err = %v, want %v", err, ErrUnorderedOutput)
+	}
+
+	opts := RK45Options{MaxStep: 0.001, MaxSteps: 10}
+	if _, err := IntegrateRK45(decay, 0, []float64{1}, []float64{100}, opts); err != ErrMaxSteps {
+		t.Errorf("step limit: err = %v, want %v", err, ErrMaxSteps)
+	}
+}
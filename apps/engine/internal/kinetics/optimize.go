@@ -0,0 +1,308 @@
+package kinetics
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+const (
+	// optimizerSampleMinutes is the resolution at which the target window is checked
+	optimizerSampleMinutes = 15
+	// maxOptimizerSlots bounds the number of candidate slots (every subset is evaluated)
+	maxOptimizerSlots = 8
+	// maxOptimizerDays bounds how many previous days of a daily schedule are superimposed
+	maxOptimizerDays = 30
+	// optimizerTailHalfLives is how long a dose is followed before it is negligible
+	optimizerTailHalfLives = 7
+	// optimizerRefinements is the number of fixed-point passes for dose-dependent curve shapes
+	optimizerRefinements = 4
+)
+
+var (
+	ErrInvalidTarget      = errors.New("kinetics: target window must be non-empty with a positive level")
+	ErrInvalidConstraints = errors.New("kinetics: constraints need a positive max daily dose and 1-8 allowed slots")
+)
+
+// DosingTarget is a concentration window to maintain every day.
+// Minutes are after midnight; an end before the start wraps past midnight.
+type DosingTarget struct {
+	StartMinutes float64
+	EndMinutes   float64
+	MinPercent   float64 // Minimum level, as a percentage of the reference Cmax
+}
+
+// DosingConstraints limits the plans the optimizer may recommend.
+type DosingConstraints struct {
+	MaxDailyDose   float64   // Maximum total daily dose (mg)
+	AllowedSlots   []float64 // Candidate intake times (minutes after midnight)
+	MaxDosesPerDay int       // Maximum number of doses (len(AllowedSlots) when zero)
+	DoseStep       float64   // Per-dose rounding increment, e.g. capsule size (none when zero)
+	ReferenceDose  float64   // Dose whose single-dose Cmax is 100% (MaxDailyDose when zero)
+}
+
+// DosingPlan is a daily schedule of equal doses with its steady-state performance.
+type DosingPlan struct {
+	Doses                []ScheduledDose
+	TotalDailyDose       float64 // mg
+	MinPercentInWindow   float64 // Lowest steady-state level within the target window
+	AbsorptionEfficiency float64 // Absorbed / administered amount (1 without saturation)
+	MeetsTarget          bool
+}
+
+// DosingRecommendation is the optimizer's result. SingleDose is the best plan using one
+// daily dose, for comparison; SplitSavingsPercent is how much less total dose the
+// recommended split needs than the single dose (0 when splitting does not help).
+type DosingRecommendation struct {
+	Recommended         DosingPlan
+	SingleDose          DosingPlan
+	SplitSavingsPercent float64
+}
+
+// AbsorbedDose returns the amount of dose that reaches circulation relative to linear
+// absorption. Saturable compounds absorb dose * CalculateAbsorptionEfficiency(dose);
+// supplements with only an RDA use ApplyAbsorptionDampening.
+func AbsorbedDose(dose float64, pk SupplementPK) float64 {
+	if dose <= 0 {
+		return 0
+	}
+	if pk.KineticsType == MichaelisMenten && pk.Vmax > 0 && pk.Km > 0 {
+		return dose * CalculateAbsorptionEfficiency(dose, pk.Vmax, pk.Km)
+	}
+	if pk.RDAAmount > 0 {
+		return ApplyAbsorptionDampening(dose, pk.RDAAmount)
+	}
+	return dose
+}
+
+// OptimizeDosing recommends the smallest total daily dose, split equally across a subset
+// of the allowed slots, that keeps the steady-state level above the target throughout the
+// window. Levels are percentages of the Cmax of one ReferenceDose.
+//
+// Each dose contributes in proportion to its absorbed amount:
+//
+//	C(t) = Σ AbsorbedDose(d) / AbsorbedDose(ref) * shape(t - t_i)
+//
+// so for saturable compounds several small doses can need less in total than one large
+// dose. When no plan reaches the target, the plan with the highest level at the maximum
+// daily dose is returned with MeetsTarget false.
+func OptimizeDosing(pk SupplementPK, target DosingTarget, constraints DosingConstraints) (DosingRecommendation, error) {
+	if target.MinPercent <= 0 || target.StartMinutes == target.EndMinutes {
+		return DosingRecommendation{}, ErrInvalidTarget
+	}
+	if constraints.MaxDailyDose <= 0 || len(constraints.AllowedSlots) == 0 || len(constraints.AllowedSlots) > maxOptimizerSlots {
+		return DosingRecommendation{}, ErrInvalidConstraints
+	}
+
+	maxDoses := constraints.MaxDosesPerDay
+	if maxDoses <= 0 || maxDoses > len(constraints.AllowedSlots) {
+		maxDoses = len(constraints.AllowedSlots)
+	}
+	reference := constraints.ReferenceDose
+	if reference <= 0 {
+		reference = constraints.MaxDailyDose
+	}
+
+	optimizer := dosingOptimizer{
+		pk:              pk,
+		target:          target,
+		constraints:     constraints,
+		referenceAmount: AbsorbedDose(reference, pk),
+		windowTimes:     windowSampleTimes(target),
+	}
+
+	slots := append([]float64(nil), constraints.AllowedSlots...)
+	sort.Float64s(slots)
+
+	var recommendation DosingRecommendation
+	haveBest, haveSingle := false, false
+	for mask := 1; mask < 1<<len(slots); mask++ {
+		subset := make([]float64, 0, len(slots))
+		for i, slot := range slots {
+			if mask&(1<<i) != 0 {
+				subset = append(subset, slot)
+			}
+		}
+		if len(subset) > maxDoses {
+			continue
+		}
+
+		plan := optimizer.plan(subset)
+		if !haveBest || betterPlan(plan, recommendation.Recommended) {
+			recommendation.Recommended = plan
+			haveBest = true
+		}
+		if len(subset) == 1 && (!haveSingle || betterPlan(plan, recommendation.SingleDose)) {
+			recommendation.SingleDose = plan
+			haveSingle = true
+		}
+	}
+
+	single, best := recommendation.SingleDose, recommendation.Recommended
+	if single.MeetsTarget && best.MeetsTarget && best.TotalDailyDose < single.TotalDailyDose {
+		recommendation.SplitSavingsPercent = 100 * (single.TotalDailyDose - best.TotalDailyDose) / single.TotalDailyDose
+	}
+
+	return recommendation, nil
+}
+
+// betterPlan prefers plans meeting the target, then lower total dose, then fewer doses;
+// among plans missing the target it prefers the highest level.
+func betterPlan(a, b DosingPlan) bool {
+	if a.MeetsTarget != b.MeetsTarget {
+		return a.MeetsTarget
+	}
+	if !a.MeetsTarget {
+		return a.MinPercentInWindow > b.MinPercentInWindow
+	}
+	if a.TotalDailyDose != b.TotalDailyDose {
+		return a.TotalDailyDose < b.TotalDailyDose
+	}
+	return len(a.Doses) < len(b.Doses)
+}
+
+// dosingOptimizer holds the state shared by every candidate plan.
+type dosingOptimizer struct {
+	pk              SupplementPK
+	target          DosingTarget
+	constraints     DosingConstraints
+	referenceAmount float64   // AbsorbedDose of the reference dose
+	windowTimes     []float64 // Sample times within the target window
+}
+
+// plan finds the smallest equal per-dose amount at the given slots that meets the target.
+//
+// The window minimum is (AbsorbedDose(d) / AbsorbedDose(ref)) * S(d), where S is the
+// minimum of the summed curve shapes. S only depends on d through the Michaelis-Menten
+// absorption phase, so d is found by a few fixed-point passes of solving for the absorbed
+// amount with S held fixed.
+func (o dosingOptimizer) plan(slots []float64) DosingPlan {
+	n := float64(len(slots))
+	maxPerDose := o.constraints.MaxDailyDose / n
+
+	perDose := maxPerDose
+	for i := 0; i < optimizerRefinements; i++ {
+		shapeMin := o.minShape(slots, perDose)
+		if shapeMin <= 0 {
+			break
+		}
+		required := o.target.MinPercent * o.referenceAmount / shapeMin
+		next := invertAbsorbedDose(required, o.pk, maxPerDose)
+		converged := math.Abs(next-perDose) <= 1e-3*perDose
+		perDose = next
+		if converged {
+			break
+		}
+	}
+
+	if step := o.constraints.DoseStep; step > 0 {
+		perDose = math.Ceil(perDose/step-1e-9) * step
+		if perDose > maxPerDose {
+			perDose = math.Floor(maxPerDose/step) * step
+		}
+	}
+	if perDose <= 0 {
+		perDose = maxPerDose
+	}
+
+	plan := DosingPlan{
+		TotalDailyDose:       perDose * n,
+		MinPercentInWindow:   o.minLevel(slots, perDose),
+		AbsorptionEfficiency: AbsorbedDose(perDose, o.pk) / perDose,
+	}
+	// Allow for the tolerance of the fixed-point solution
+	plan.MeetsTarget = plan.MinPercentInWindow >= o.target.MinPercent*(1-1e-3)
+	for _, slot := range slots {
+		plan.Doses = append(plan.Doses, ScheduledDose{Dose: perDose, AtMinutes: slot})
+	}
+	return plan
+}
+
+// minLevel returns the lowest steady-state level in the window for equal doses at slots.
+func (o dosingOptimizer) minLevel(slots []float64, perDose float64) float64 {
+	if o.referenceAmount <= 0 {
+		return 0
+	}
+	return AbsorbedDose(perDose, o.pk) / o.referenceAmount * o.minShape(slots, perDose)
+}
+
+// minShape returns the minimum over the window of the summed, dose-normalized curves of
+// doses at slots, repeated daily until earlier days are negligible.
+func (o dosingOptimizer) minShape(slots []float64, perDose float64) float64 {
+	shapePK := o.pk
+	shapePK.RDAAmount = 0 // saturation is applied through AbsorbedDose
+
+	// Every contributing dose is evaluated on one curve; window[j] is the window sample
+	// that elapsed[j] contributes to
+	days := o.daysBack()
+	var elapsedTimes []float64
+	var window []int
+	for w, t := range o.windowTimes {
+		for _, slot := range slots {
+			// day -1 covers the next day's doses for windows past midnight
+			for day := -1; day <= days; day++ {
+				elapsed := t - slot + float64(day*MinutesPerDay)
+				if elapsed < 0 {
+					continue
+				}
+				elapsedTimes = append(elapsedTimes, elapsed)
+				window = append(window, w)
+			}
+		}
+	}
+
+	curve := CalculateConcentrationCurve(ConcentrationParams{Dose: perDose, PK: shapePK}, elapsedTimes)
+	totals := make([]float64, len(o.windowTimes))
+	for j, w := range window {
+		totals[w] += curve[j]
+	}
+
+	minimum := math.Inf(1)
+	for _, total := range totals {
+		minimum = math.Min(minimum, total)
+	}
+	return minimum
+}
+
+// daysBack returns how many previous days still contribute to today's levels.
+func (o dosingOptimizer) daysBack() int {
+	tail := peakMinutesOrDefault(o.pk) + optimizerTailHalfLives*halfLifeOrDefault(o.pk)
+	return int(math.Min(math.Ceil(tail/MinutesPerDay), maxOptimizerDays))
+}
+
+// windowSampleTimes returns sample times across the target window; windows crossing
+// midnight are sampled on the following day's axis.
+func windowSampleTimes(target DosingTarget) []float64 {
+	start, end := target.StartMinutes, target.EndMinutes
+	if end < start {
+		end += MinutesPerDay
+	}
+
+	times := make([]float64, 0)
+	for t := start; t < end; t += optimizerSampleMinutes {
+		times = append(times, t)
+	}
+	return append(times, end)
+}
+
+// invertAbsorbedDose returns the dose whose AbsorbedDose equals amount, capped at maxDose.
+// AbsorbedDose is increasing in dose, so bisection applies.
+func invertAbsorbedDose(amount float64, pk SupplementPK, maxDose float64) float64 {
+	if amount <= 0 {
+		return 0
+	}
+	if AbsorbedDose(maxDose, pk) <= amount {
+		return maxDose
+	}
+
+	lo, hi := 0.0, maxDose
+	for i := 0; i < 100 && hi-lo > 1e-9*maxDose; i++ {
+		mid := (lo + hi) / 2
+		if AbsorbedDose(mid, pk) < amount {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi
+}
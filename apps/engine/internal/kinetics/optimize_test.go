@@ -0,0 +1,137 @@
+package kinetics
+
+import (
+	"testing"
+)
+
+// Daytime window 08:00-20:00
+var daytimeTarget = DosingTarget{StartMinutes: 8 * 60, EndMinutes: 20 * 60, MinPercent: 40}
+
+// Slots lead the window so doses have time to absorb
+var dailySlots = []float64{6 * 60, 10 * 60, 14 * 60, 18 * 60}
+
+func TestAbsorbedDose(t *testing.T) {
+	linear := SupplementPK{KineticsType: FirstOrder}
+	if got := AbsorbedDose(500, linear); got != 500 {
+		t.Errorf("Linear absorption should be unchanged, got %v", got)
+	}
+
+	mm := SupplementPK{KineticsType: MichaelisMenten, Vmax: 2, Km: 200}
+	if got := AbsorbedDose(200, mm); !approxEqual(got, 100, epsilon) {
+		t.Errorf("At Km half the dose is absorbed, got %v", got)
+	}
+	if AbsorbedDose(2000, mm) >= 2*AbsorbedDose(1000, mm) {
+		t.Errorf("Saturable absorption should be sublinear")
+	}
+}
+
+func TestOptimizeDosing_SplitBeatsSingleForVitaminC(t *testing.T) {
+	vitaminC := SupplementPK{
+		KineticsType:    MichaelisMenten,
+		PeakMinutes:     180,
+		HalfLifeMinutes: 120,
+		Vmax:            2,
+		Km:              200,
+	}
+	constraints := DosingConstraints{MaxDailyDose: 3000, AllowedSlots: dailySlots, ReferenceDose: 500}
+
+	recommendation, err := OptimizeDosing(vitaminC, daytimeTarget, constraints)
+	if err != nil {
+		t.Fatalf("OptimizeDosing returned error: %v", err)
+	}
+
+	best := recommendation.Recommended
+	if !best.MeetsTarget {
+		t.Fatalf("Expected a plan meeting the target, got %+v", best)
+	}
+	if len(best.Doses) < 2 {
+		t.Errorf("Expected a split schedule for a short half-life saturable compound, got %d dose(s)", len(best.Doses))
+	}
+	if best.MinPercentInWindow < daytimeTarget.MinPercent*0.999 {
+		t.Errorf("Plan minimum %v below target", best.MinPercentInWindow)
+	}
+	if best.AbsorptionEfficiency <= recommendation.SingleDose.AbsorptionEfficiency {
+		t.Errorf("Smaller split doses should absorb more efficiently: %v vs %v",
+			best.AbsorptionEfficiency, recommendation.SingleDose.AbsorptionEfficiency)
+	}
+
+	// The same efficiency follows from CalculateAbsorptionEfficiency
+	perDose := best.Doses[0].Dose
+	if !approxEqual(best.AbsorptionEfficiency, CalculateAbsorptionEfficiency(perDose, 2, 200), 1e-9) {
+		t.Errorf("Efficiency %v should match CalculateAbsorptionEfficiency", best.AbsorptionEfficiency)
+	}
+
+	if recommendation.SingleDose.MeetsTarget && recommendation.SplitSavingsPercent <= 0 {
+		t.Errorf("Expected splitting to save dose, got %v%%", recommendation.SplitSavingsPercent)
+	}
+}
+
+func TestOptimizeDosing_LongHalfLifeNeedsOneDose(t *testing.T) {
+	// With a 3-day half-life levels are flat, so one dose is as good as several
+	pk := SupplementPK{KineticsType: FirstOrder, PeakMinutes: 120, HalfLifeMinutes: 3 * MinutesPerDay}
+	constraints := DosingConstraints{MaxDailyDose: 100, AllowedSlots: dailySlots, ReferenceDose: 100}
+
+	recommendation, err := OptimizeDosing(pk, daytimeTarget, constraints)
+	if err != nil {
+		t.Fatalf("OptimizeDosing returned error: %v", err)
+	}
+	if !recommendation.Recommended.MeetsTarget {
+		t.Fatalf("Expected target to be met, got %+v", recommendation.Recommended)
+	}
+	if recommendation.Recommended.TotalDailyDose >= 100 {
+		t.Errorf("Accumulation should need less than the reference dose daily, got %v", recommendation.Recommended.TotalDailyDose)
+	}
+}
+
+func TestOptimizeDosing_RoundsToDoseStep(t *testing.T) {
+	pk := SupplementPK{KineticsType: FirstOrder, PeakMinutes: 60, HalfLifeMinutes: 360}
+	constraints := DosingConstraints{MaxDailyDose: 1000, AllowedSlots: dailySlots, DoseStep: 50, ReferenceDose: 200}
+
+	recommendation, err := OptimizeDosing(pk, daytimeTarget, constraints)
+	if err != nil {
+		t.Fatalf("OptimizeDosing returned error: %v", err)
+	}
+	for _, dose := range recommendation.Recommended.Doses {
+		if rem := dose.Dose / 50; rem != float64(int(rem)) {
+			t.Errorf("Dose %v is not a multiple of the 50 mg step", dose.Dose)
+		}
+	}
+	if !recommendation.Recommended.MeetsTarget {
+		t.Errorf("Rounding up should still meet the target, got %+v", recommendation.Recommended)
+	}
+}
+
+func TestOptimizeDosing_UnreachableTarget(t *testing.T) {
+	pk := SupplementPK{KineticsType: FirstOrder, PeakMinutes: 60, HalfLifeMinutes: 60}
+	target := DosingTarget{StartMinutes: 0, EndMinutes: 23 * 60, MinPercent: 90}
+	constraints := DosingConstraints{MaxDailyDose: 100, AllowedSlots: []float64{8 * 60}}
+
+	recommendation, err := OptimizeDosing(pk, target, constraints)
+	if err != nil {
+		t.Fatalf("OptimizeDosing returned error: %v", err)
+	}
+	if recommendation.Recommended.MeetsTarget {
+		t.Errorf("A single dose with a 1h half-life cannot cover the day")
+	}
+	if recommendation.Recommended.TotalDailyDose != 100 {
+		t.Errorf("Unreachable targets should use the maximum dose, got %v", recommendation.Recommended.TotalDailyDose)
+	}
+}
+
+func TestOptimizeDosing_InvalidInput(t *testing.T) {
+	pk := SupplementPK{}
+
+	if _, err := OptimizeDosing(pk, DosingTarget{StartMinutes: 480, EndMinutes: 480, MinPercent: 40}, DosingConstraints{MaxDailyDose: 100, AllowedSlots: dailySlots}); err != ErrInvalidTarget {
+		t.Errorf("Empty window: err = %v, want %v", err, ErrInvalidTarget)
+	}
+	if _, err := OptimizeDosing(pk, daytimeTarget, DosingConstraints{MaxDailyDose: 100}); err != ErrInvalidConstraints {
+		t.Errorf("No slots: err = %v, want %v", err, ErrInvalidConstraints)
+	}
+}
+
+func TestWindowSampleTimes_WrapsMidnight(t *testing.T) {
+	times := windowSampleTimes(DosingTarget{StartMinutes: 22 * 60, EndMinutes: 2 * 60})
+	if times[0] != 22*60 || times[len(times)-1] != 26*60 {
+		t.Errorf("Expected samples from 1320 to 1560, got %v to %v", times[0], times[len(times)-1])
+	}
+}
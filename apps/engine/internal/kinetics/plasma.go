@@ -0,0 +1,116 @@
+package kinetics
+
+import (
+	"errors"
+)
+
+// DefaultBodyWeightKg is the reference adult body weight used when none is given.
+const DefaultBodyWeightKg = 70
+
+// ErrMissingVolume is returned when absolute concentrations are requested for a
+// supplement without a volume of distribution.
+var ErrMissingVolume = errors.New("kinetics: volume of distribution is required for mg/L concentrations")
+
+// CalculatePlasmaConcentration returns the estimated plasma concentration in mg/L.
+//
+// The percentage curve of CalculateConcentration is scaled by the absolute single-dose
+// Cmax, so the percentage remains a derived view of the same curve:
+//
+//	C(t) [mg/L] = PeakPlasmaConcentration * CalculateConcentration / 100
+func CalculatePlasmaConcentration(params ConcentrationParams) (float64, error) {
+	peak, err := PeakPlasmaConcentration(params)
+	if err != nil {
+		return 0, err
+	}
+	return peak * CalculateConcentration(params) / 100, nil
+}
+
+// PeakPlasmaConcentration returns the absolute Cmax (mg/L) of a single dose for the
+// supplement's stored route and an unadjusted meal context, i.e. the concentration that
+// CalculateConcentration reports as 100%.
+//
+// It uses the one-compartment model with first-order absorption:
+//
+//	Cmax = F * D_abs / (Vd * weight) * ka * (e^(-ke*tmax) - e^(-ka*tmax)) / (ka - ke)
+//
+// where the last factor is the fraction of the dose in the body at the peak. F defaults to
+// 100% when unknown. For Michaelis-Menten compounds D_abs is the dose scaled by
+// CalculateAbsorptionEfficiency; RDA dampening is already part of the percentage curve.
+func PeakPlasmaConcentration(params ConcentrationParams) (float64, error) {
+	pk := params.PK
+	if pk.VolumeOfDistribution <= 0 {
+		return 0, ErrMissingVolume
+	}
+
+	weight := params.BodyWeightKg
+	if weight <= 0 {
+		weight = DefaultBodyWeightKg
+	}
+
+	fraction := 1.0
+	if pk.BioavailabilityPercent > 0 {
+		fraction = pk.BioavailabilityPercent / 100
+	}
+
+	absorbed := params.Dose
+	if pk.KineticsType == MichaelisMenten && pk.Vmax > 0 && pk.Km > 0 {
+		absorbed *= CalculateAbsorptionEfficiency(params.Dose, pk.Vmax, pk.Km)
+	}
+
+	ka, ke := absorptionRates(pk)
+	inBodyAtPeak := ka * batemanShape(ka, ke, batemanPeakTime(ka, ke))
+
+	return fraction * absorbed * inBodyAtPeak / (pk.VolumeOfDistribution * weight), nil
+}
+
+// CalculateMultiDosePlasmaConcentration returns the combined concentration (mg/L) of
+// several doses at time t, superimposed like CalculateMultiDoseConcentration.
+func CalculateMultiDosePlasmaConcentration(doses []DoseEvent, t float64, bodyWeightKg float64) (float64, error) {
+	total := 0.0
+	for _, d := range doses {
+		concentration, err := CalculatePlasmaConcentration(ConcentrationParams{
+			Dose:                  d.Dose,
+			MinutesSinceIngestion: t - d.AtMinutes,
+			PK:                    d.PK,
+			Route:                 d.Route,
+			Meal:                  d.Meal,
+			BodyWeightKg:          bodyWeightKg,
+		})
+		if err != nil {
+			return 0, err
+		}
+		total += concentration
+	}
+	return total, nil
+}
+
+// CalculateMultiDosePlasmaConcentrationCurve returns CalculateMultiDosePlasmaConcentration
+// at each of times, evaluating each dose's curve in one pass.
+func CalculateMultiDosePlasmaConcentrationCurve(doses []DoseEvent, times []float64, bodyWeightKg float64) ([]float64, error) {
+	total := make([]float64, len(times))
+	shifted := make([]float64, len(times))
+	curve := make([]float64, len(times))
+
+	for _, d := range doses {
+		params := ConcentrationParams{
+			Dose:         d.Dose,
+			PK:           d.PK,
+			Route:        d.Route,
+			Meal:         d.Meal,
+			BodyWeightKg: bodyWeightKg,
+		}
+		peak, err := PeakPlasmaConcentration(params)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, t := range times {
+			shifted[i] = t - d.AtMinutes
+		}
+		fillConcentrationCurve(params, shifted, curve)
+		for i, c := range curve {
+			total[i] += peak * c / 100
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,98 @@
+package kinetics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalculatePlasmaConcentration_MatchesBateman(t *testing.T) {
+	// Caffeine-like: 100 mg, F = 99%, Vd = 0.6 L/kg, ka = 0.05/min, t½ = 5h, 70 kg
+	pk := SupplementPK{
+		KineticsType:           OneCompartment,
+		HalfLifeMinutes:        300,
+		BioavailabilityPercent: 99,
+		AbsorptionRateConstant: 0.05,
+		VolumeOfDistribution:   0.6,
+	}
+	ka, ke := 0.05, math.Log(2)/300
+
+	for _, minutes := range []float64{15, 45, 120, 600} {
+		got, err := CalculatePlasmaConcentration(ConcentrationParams{Dose: 100, MinutesSinceIngestion: minutes, PK: pk, BodyWeightKg: 70})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := 0.99 * 100 * ka / (0.6 * 70 * (ka - ke)) * (math.Exp(-ke*minutes) - math.Exp(-ka*minutes))
+		if !approxEqual(got, expected, 1e-9) {
+			t.Errorf("C(%v) = %v mg/L, want %v", minutes, got, expected)
+		}
+	}
+}
+
+func TestCalculatePlasmaConcentration_ScalesWithDoseAndWeight(t *testing.T) {
+	pk := SupplementPK{KineticsType: FirstOrder, PeakMinutes: 60, HalfLifeMinutes: 240, VolumeOfDistribution: 0.7}
+	params := ConcentrationParams{Dose: 200, MinutesSinceIngestion: 60, PK: pk, BodyWeightKg: 70}
+
+	base, _ := CalculatePlasmaConcentration(params)
+
+	params.Dose = 400
+	doubled, _ := CalculatePlasmaConcentration(params)
+	if !approxEqual(doubled, 2*base, 1e-9) {
+		t.Errorf("Doubling the dose should double the level: %v vs %v", doubled, base)
+	}
+
+	params.Dose = 200
+	params.BodyWeightKg = 140
+	heavier, _ := CalculatePlasmaConcentration(params)
+	if !approxEqual(heavier, base/2, 1e-9) {
+		t.Errorf("Doubling body weight should halve the level: %v vs %v", heavier, base)
+	}
+
+	params.BodyWeightKg = 0
+	defaultWeight, _ := CalculatePlasmaConcentration(params)
+	if !approxEqual(defaultWeight, base, 1e-9) {
+		t.Errorf("Missing weight should default to %v kg", DefaultBodyWeightKg)
+	}
+}
+
+func TestCalculatePlasmaConcentration_PercentIsDerivedView(t *testing.T) {
+	pk := SupplementPK{KineticsType: FirstOrder, PeakMinutes: 60, HalfLifeMinutes: 240, VolumeOfDistribution: 1}
+	params := ConcentrationParams{Dose: 100, PK: pk}
+
+	peak, err := PeakPlasmaConcentration(params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, minutes := range []float64{30, 60, 300} {
+		params.MinutesSinceIngestion = minutes
+		mgPerL, _ := CalculatePlasmaConcentration(params)
+		if percent := CalculateConcentration(params); !approxEqual(100*mgPerL/peak, percent, 1e-9) {
+			t.Errorf("t=%v: %v mg/L is not %v%% of Cmax %v", minutes, mgPerL, percent, peak)
+		}
+	}
+}
+
+func TestCalculatePlasmaConcentration_SaturableAbsorption(t *testing.T) {
+	pk := SupplementPK{KineticsType: MichaelisMenten, PeakMinutes: 180, HalfLifeMinutes: 120, Vmax: 2, Km: 200, VolumeOfDistribution: 0.7}
+
+	small, _ := PeakPlasmaConcentration(ConcentrationParams{Dose: 200, PK: pk})
+	large, _ := PeakPlasmaConcentration(ConcentrationParams{Dose: 2000, PK: pk})
+
+	// Efficiency drops from 1/2 to 1/11, so 10x the dose gives under 2x the peak
+	if ratio := large / small; !approxEqual(ratio, 10*(200.0/2200)/(200.0/400), 1e-9) {
+		t.Errorf("Peak ratio = %v, want %v", ratio, 10*(200.0/2200)/(200.0/400))
+	}
+}
+
+func TestCalculatePlasmaConcentration_MissingVolume(t *testing.T) {
+	_, err := CalculatePlasmaConcentration(ConcentrationParams{Dose: 100, MinutesSinceIngestion: 60, PK: SupplementPK{}})
+	if err != ErrMissingVolume {
+		t.Errorf("err = %v, want %v", err, ErrMissingVolume)
+	}
+
+	doses := []DoseEvent{{Dose: 100, PK: SupplementPK{VolumeOfDistribution: 1}}, {Dose: 100, PK: SupplementPK{}}}
+	if _, err := CalculateMultiDosePlasmaConcentration(doses, 60, 70); err != ErrMissingVolume {
+		t.Errorf("multi-dose err = %v, want %v", err, ErrMissingVolume)
+	}
+}
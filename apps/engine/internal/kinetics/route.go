@@ -0,0 +1,94 @@
+package kinetics
+
+import (
+	"math"
+)
+
+// Route represents the route of administration of a dose.
+// Values match the route_of_administration database enum.
+type Route string
+
+const (
+	RouteOral          Route = "oral"
+	RouteSublingual    Route = "sublingual"
+	RouteSubQInjection Route = "subq_injection"
+	RouteIMInjection   Route = "im_injection"
+	RouteIntranasal    Route = "intranasal"
+	RouteTransdermal   Route = "transdermal"
+	RouteTopical       Route = "topical"
+	RouteRectal        Route = "rectal"
+)
+
+// RouteProfile describes how a route of administration changes absorption relative to oral intake.
+type RouteProfile struct {
+	PeakFactor            float64      // Tmax multiplier relative to oral
+	BioavailabilityFactor float64      // F multiplier relative to oral (result capped at 100%)
+	Absorption            KineticsType // Absorption model for the route; empty keeps the supplement's model
+}
+
+// RouteProfiles holds the absorption profile of each route.
+//
+// Non-GI routes bypass saturable gut transporters and first-pass metabolism, so they
+// switch to one-compartment absorption. Transdermal and topical delivery release at a
+// roughly constant rate, which the first-order model's linear ramp approximates.
+var RouteProfiles = map[Route]RouteProfile{
+	RouteOral:          {PeakFactor: 1, BioavailabilityFactor: 1},
+	RouteSublingual:    {PeakFactor: 0.5, BioavailabilityFactor: 1.25, Absorption: OneCompartment},
+	RouteSubQInjection: {PeakFactor: 0.5, BioavailabilityFactor: 1.8, Absorption: OneCompartment},
+	RouteIMInjection:   {PeakFactor: 0.4, BioavailabilityFactor: 1.9, Absorption: OneCompartment},
+	RouteIntranasal:    {PeakFactor: 0.25, BioavailabilityFactor: 1.2, Absorption: OneCompartment},
+	RouteTransdermal:   {PeakFactor: 4, BioavailabilityFactor: 0.6, Absorption: FirstOrder},
+	RouteTopical:       {PeakFactor: 3, BioavailabilityFactor: 0.1, Absorption: FirstOrder},
+	RouteRectal:        {PeakFactor: 0.75, BioavailabilityFactor: 0.9, Absorption: OneCompartment},
+}
+
+// AdjustForRoute re-expresses pk, whose parameters were measured for pk.Route (oral when
+// empty), for a dose given by route.
+//
+// It returns the adjusted parameters and the exposure factor F_route / F_reference, which
+// scales percent-of-Cmax output so that routes are comparable on the same axis.
+// Unknown routes, or the reference route itself, return pk unchanged with a factor of 1.
+func AdjustForRoute(pk SupplementPK, route Route) (SupplementPK, float64) {
+	reference := pk.Route
+	if reference == "" {
+		reference = RouteOral
+	}
+	if route == "" || route == reference {
+		return pk, 1
+	}
+
+	from, okFrom := RouteProfiles[reference]
+	to, okTo := RouteProfiles[route]
+	if !okFrom || !okTo {
+		return pk, 1
+	}
+
+	adjusted := pk
+	adjusted.Route = route
+
+	peakRatio := to.PeakFactor / from.PeakFactor
+	adjusted.PeakMinutes = peakMinutesOrDefault(pk) * peakRatio
+	if pk.AbsorptionRateConstant > 0 {
+		// Faster absorption means a proportionally larger ka
+		adjusted.AbsorptionRateConstant = pk.AbsorptionRateConstant / peakRatio
+	}
+	if to.Absorption != "" {
+		adjusted.KineticsType = to.Absorption
+	}
+
+	exposure := to.BioavailabilityFactor / from.BioavailabilityFactor
+	if pk.BioavailabilityPercent > 0 {
+		adjusted.BioavailabilityPercent = math.Min(pk.BioavailabilityPercent*exposure, 100)
+		exposure = adjusted.BioavailabilityPercent / pk.BioavailabilityPercent
+	}
+
+	return adjusted, exposure
+}
+
+// peakMinutesOrDefault returns the stored Tmax, or DefaultPeakMinutes when missing.
+func peakMinutesOrDefault(pk SupplementPK) float64 {
+	if pk.PeakMinutes > 0 {
+		return pk.PeakMinutes
+	}
+	return DefaultPeakMinutes
+}
@@ -0,0 +1,108 @@
+package kinetics
+
+import (
+	"testing"
+)
+
+func TestAdjustForRoute_SameRouteUnchanged(t *testing.T) {
+	pk := SupplementPK{
+		KineticsType:           MichaelisMenten,
+		PeakMinutes:            120,
+		HalfLifeMinutes:        180,
+		BioavailabilityPercent: 50,
+	}
+
+	for _, route := range []Route{"", RouteOral} {
+		adjusted, exposure := AdjustForRoute(pk, route)
+		if adjusted != pk || exposure != 1 {
+			t.Errorf("AdjustForRoute(%q) should leave oral PK unchanged, got %+v (exposure %v)", route, adjusted, exposure)
+		}
+	}
+
+	adjusted, exposure := AdjustForRoute(pk, Route("inhaled"))
+	if adjusted != pk || exposure != 1 {
+		t.Errorf("Unknown route should leave PK unchanged, got %+v (exposure %v)", adjusted, exposure)
+	}
+}
+
+func TestAdjustForRoute_SublingualB12(t *testing.T) {
+	pk := SupplementPK{
+		KineticsType:           MichaelisMenten,
+		PeakMinutes:            240,
+		HalfLifeMinutes:        360,
+		BioavailabilityPercent: 50,
+		Vmax:                   0.01,
+		Km:                     2,
+	}
+
+	adjusted, exposure := AdjustForRoute(pk, RouteSublingual)
+
+	if adjusted.PeakMinutes != 120 {
+		t.Errorf("Sublingual Tmax should halve to 120, got %v", adjusted.PeakMinutes)
+	}
+	if adjusted.KineticsType != OneCompartment {
+		t.Errorf("Sublingual absorption should bypass saturable gut transport, got %v", adjusted.KineticsType)
+	}
+	if !approxEqual(adjusted.BioavailabilityPercent, 62.5, epsilon) {
+		t.Errorf("Sublingual F should be 62.5%%, got %v", adjusted.BioavailabilityPercent)
+	}
+	if !approxEqual(exposure, 1.25, epsilon) {
+		t.Errorf("Sublingual exposure factor should be 1.25, got %v", exposure)
+	}
+}
+
+func TestAdjustForRoute_CapsBioavailability(t *testing.T) {
+	pk := SupplementPK{PeakMinutes: 60, HalfLifeMinutes: 240, BioavailabilityPercent: 80}
+
+	adjusted, exposure := AdjustForRoute(pk, RouteSubQInjection)
+
+	if adjusted.BioavailabilityPercent != 100 {
+		t.Errorf("F should be capped at 100%%, got %v", adjusted.BioavailabilityPercent)
+	}
+	if !approxEqual(exposure, 1.25, epsilon) {
+		t.Errorf("Exposure should reflect the capped F (100/80), got %v", exposure)
+	}
+}
+
+func TestAdjustForRoute_RelativeToReferenceRoute(t *testing.T) {
+	// Peptide parameters measured for subcutaneous injection
+	pk := SupplementPK{
+		KineticsType:    OneCompartment,
+		PeakMinutes:     30,
+		HalfLifeMinutes: 240,
+		Route:           RouteSubQInjection,
+	}
+
+	adjusted, exposure := AdjustForRoute(pk, RouteSubQInjection)
+	if adjusted != pk || exposure != 1 {
+		t.Errorf("Dose by the reference route should be unchanged, got %+v (exposure %v)", adjusted, exposure)
+	}
+
+	adjusted, exposure = AdjustForRoute(pk, RouteIntranasal)
+	if !approxEqual(adjusted.PeakMinutes, 15, epsilon) {
+		t.Errorf("Intranasal Tmax should be half of subcutaneous, got %v", adjusted.PeakMinutes)
+	}
+	if !approxEqual(exposure, 1.2/1.8, epsilon) {
+		t.Errorf("Intranasal exposure relative to subcutaneous should be %v, got %v", 1.2/1.8, exposure)
+	}
+}
+
+func TestCalculateConcentration_UsesDoseRoute(t *testing.T) {
+	pk := SupplementPK{
+		KineticsType:           FirstOrder,
+		PeakMinutes:            60,
+		HalfLifeMinutes:        240,
+		BioavailabilityPercent: 40,
+	}
+
+	oral := CalculateConcentration(ConcentrationParams{Dose: 100, MinutesSinceIngestion: 30, PK: pk})
+	sublingual := CalculateConcentration(ConcentrationParams{Dose: 100, MinutesSinceIngestion: 30, PK: pk, Route: RouteSublingual})
+
+	if !approxEqual(oral, 50, 0.1) {
+		t.Errorf("Oral dose halfway to Tmax should be ~50%%, got %v", oral)
+	}
+	// Sublingual peaks at 30 minutes with 1.25x exposure
+	if !approxEqual(sublingual, 125, 0.1) {
+		t.Errorf("Sublingual dose at its Tmax should be ~125%% of oral Cmax, got %v", sublingual)
+	}
+}
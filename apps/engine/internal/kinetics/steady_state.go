@@ -0,0 +1,236 @@
+package kinetics
+
+import (
+	"math"
+)
+
+const (
+	MinutesPerDay  = 1440
+	MinutesPerWeek = 7 * MinutesPerDay
+
+	// steadyStateFraction is the fraction of steady state used for TimeToSteadyStateMinutes
+	steadyStateFraction = 0.9
+	// steadyStateSamples is the sampling resolution of one schedule period
+	steadyStateSamples = 1440
+	// tailHalfLives is how many terminal half-lives past Tmax contributions are evaluated
+	// explicitly before the remaining doses are summed as a geometric series (for a
+	// single-phase decline, 100% * 2^-4 stays above the 1% cutoff)
+	tailHalfLives = 4
+	// maxClearanceDoublings bounds the search for when curves without a terminal rate clear
+	maxClearanceDoublings = 8
+)
+
+// ScheduledDose is one dose within a repeating schedule period.
+type ScheduledDose struct {
+	Dose      float64 // Administered dose (mg)
+	AtMinutes float64 // Offset within the period (0 <= AtMinutes < PeriodMinutes)
+}
+
+// DosingSchedule is a dosing pattern that repeats every PeriodMinutes,
+// e.g. one dose at 08:00 daily or doses on Monday, Wednesday and Friday weekly.
+type DosingSchedule struct {
+	PeriodMinutes float64
+	Doses         []ScheduledDose
+	PK            SupplementPK
+	Route         Route      // Route of the doses (PK.Route when empty)
+	Meal          MealEffect // Meal context effect on oral absorption
+}
+
+// SteadyStateResult describes the long-run behaviour of a repeating schedule.
+// Concentrations are percentages of the single-dose Cmax.
+type SteadyStateResult struct {
+	AccumulationRatio        float64 // Steady-state peak / first-dose peak
+	TimeToSteadyStateMinutes float64 // Time to reach 90% of steady state
+	DosesToSteadyState       int     // Number of doses taken before reaching 90% of steady state
+	SingleDosePeak           float64 // Peak of an isolated dose
+	PeakPercent              float64 // Steady-state maximum over one period
+	PeakAtMinutes            float64 // Offset of the maximum within the period
+	TroughPercent            float64 // Steady-state minimum over one period
+	TroughAtMinutes          float64 // Offset of the minimum within the period
+	AveragePercent           float64 // Steady-state mean over one period
+}
+
+// AnalyzeSteadyState computes accumulation and steady-state levels of a repeating schedule.
+//
+// With linear elimination the steady-state curve is the superposition of all past doses:
+//
+//	Css(t) = Σ_i Σ_{n≥0} C_i(t - t_i + n*P)
+//
+// Recent doses are evaluated with CalculateConcentrationCurve. Most models end in a single
+// exponential decline at a terminal rate λ: ke for first-order elimination, min(ka, ke)
+// for Bateman absorption (ka governs flip-flop kinetics) and β for two compartments. Once
+// a dose is tailHalfLives terminal half-lives past its peak, the remaining periods form a
+// geometric series with ratio q = e^(-λ*P), which avoids the 1% clearance cutoff
+// truncating long half-life accumulation. Models without a closed-form terminal rate,
+// such as enterohepatic recirculation, are evaluated explicitly until the curve clears.
+//
+// The time to steady state depends only on the terminal decline: 1 - e^(-λ*t) = 0.9 gives
+// t = log2(10) * t½ ≈ 3.3 terminal half-lives. Without a terminal rate it is the time by
+// which a single dose has delivered 90% of its exposure, which is the same for an
+// exponential decline.
+func AnalyzeSteadyState(schedule DosingSchedule) SteadyStateResult {
+	if schedule.PeriodMinutes <= 0 || len(schedule.Doses) == 0 {
+		return SteadyStateResult{}
+	}
+
+	adjusted, _ := adjustParams(ConcentrationParams{PK: schedule.PK, Route: schedule.Route, Meal: schedule.Meal})
+	k, decays := terminalRate(adjusted.PK)
+	period := schedule.PeriodMinutes
+
+	var tail float64
+	if decays {
+		tail = peakMinutesOrDefault(adjusted.PK) + tailHalfLives*math.Log(2)/k
+	} else {
+		tail = clearanceMinutes(schedule, adjusted.PK)
+	}
+	q := math.Exp(-k * period)
+
+	// Single-dose reference peak and exposure over the explicit (pre-tail) part of the curve
+	var result SteadyStateResult
+	step := period / steadyStateSamples
+	var peakTimes []float64
+	for t := 0.0; t <= tail; t += step {
+		peakTimes = append(peakTimes, t)
+	}
+	singleDose := make([]float64, len(peakTimes))
+	for _, d := range schedule.Doses {
+		for j, c := range scheduledConcentrationCurve(schedule, d.Dose, peakTimes) {
+			result.SingleDosePeak = math.Max(result.SingleDosePeak, c)
+			singleDose[j] += c
+		}
+	}
+
+	if decays {
+		result.TimeToSteadyStateMinutes = -math.Log(1-steadyStateFraction) / k
+	} else {
+		result.TimeToSteadyStateMinutes = exposureFractionMinutes(peakTimes, singleDose, steadyStateFraction)
+	}
+	result.DosesToSteadyState = int(math.Ceil(result.TimeToSteadyStateMinutes / period * float64(len(schedule.Doses))))
+
+	// Each dose is evaluated over one curve holding every explicit period at every
+	// sample, with the anchor C(tail) last
+	levels := make([]float64, steadyStateSamples)
+	tailDecay := make([]float64, steadyStateSamples)
+	for _, d := range schedule.Doses {
+		var elapsedTimes []float64
+		var samples []int
+		for i := range levels {
+			elapsed := math.Mod(float64(i)*step-d.AtMinutes, period)
+			if elapsed < 0 {
+				elapsed += period
+			}
+
+			for ; elapsed < tail; elapsed += period {
+				elapsedTimes = append(elapsedTimes, elapsed)
+				samples = append(samples, i)
+			}
+
+			// Remaining doses: C(tail) * e^(-λ*(elapsed - tail)) * Σ q^n
+			if decays {
+				tailDecay[i] = math.Exp(-k*(elapsed-tail)) / (1 - q)
+			}
+		}
+
+		curve := scheduledConcentrationCurve(schedule, d.Dose, append(elapsedTimes, tail))
+		for j, i := range samples {
+			levels[i] += curve[j]
+		}
+		anchor := curve[len(samples)]
+		for i := range levels {
+			levels[i] += anchor * tailDecay[i]
+		}
+	}
+
+	result.TroughPercent = math.Inf(1)
+	sum := 0.0
+	for i, c := range levels {
+		t := float64(i) * step
+		sum += c
+		if c > result.PeakPercent {
+			result.PeakPercent = c
+			result.PeakAtMinutes = t
+		}
+		if c < result.TroughPercent {
+			result.TroughPercent = c
+			result.TroughAtMinutes = t
+		}
+	}
+	result.AveragePercent = sum / steadyStateSamples
+
+	if result.SingleDosePeak > 0 {
+		result.AccumulationRatio = result.PeakPercent / result.SingleDosePeak
+	}
+
+	return result
+}
+
+// scheduledConcentrationCurve evaluates a single dose of the schedule at each of the
+// given times since intake.
+func scheduledConcentrationCurve(schedule DosingSchedule, dose float64, times []float64) []float64 {
+	return CalculateConcentrationCurve(ConcentrationParams{
+		Dose:  dose,
+		PK:    schedule.PK,
+		Route: schedule.Route,
+		Meal:  schedule.Meal,
+	}, times)
+}
+
+// terminalRate returns the rate constant (1/min) of the single exponential decline that
+// ends a dose's curve under pk, which has its route, meal and calibration adjustments
+// applied. It reports false for compartment models without one.
+func terminalRate(pk SupplementPK) (float64, bool) {
+	if factory, ok := LookupCompartmentModel(pk.CompartmentModel); ok {
+		model, ok := factory(pk).(terminalDecayModel)
+		if !ok {
+			return 0, false
+		}
+		return model.terminalRate(), true
+	}
+
+	switch pk.KineticsType {
+	case OneCompartment:
+		return math.Min(absorptionRates(pk)), true
+	default:
+		// First-order and Michaelis-Menten curves decline at ke from Tmax
+		return math.Log(2) / halfLifeOrDefault(pk), true
+	}
+}
+
+// clearanceMinutes returns a time since intake by which every dose of the schedule has
+// fallen below the 1% clearance cutoff, doubling the horizon from Tmax + tailHalfLives t½.
+// Curves still above the cutoff after maxClearanceDoublings are truncated there.
+func clearanceMinutes(schedule DosingSchedule, pk SupplementPK) float64 {
+	horizon := peakMinutesOrDefault(pk) + tailHalfLives*halfLifeOrDefault(pk)
+	for i := 0; i < maxClearanceDoublings; i++ {
+		cleared := true
+		for _, d := range schedule.Doses {
+			if scheduledConcentrationCurve(schedule, d.Dose, []float64{horizon})[0] > 0 {
+				cleared = false
+				break
+			}
+		}
+		if cleared {
+			break
+		}
+		horizon *= 2
+	}
+	return horizon
+}
+
+// exposureFractionMinutes returns the first of the ascending times by which the curve
+// sampled at them has accumulated fraction of its total area.
+func exposureFractionMinutes(times []float64, curve []float64, fraction float64) float64 {
+	total := 0.0
+	for _, c := range curve {
+		total += c
+	}
+
+	cumulative := 0.0
+	for i, c := range curve {
+		cumulative += c
+		if cumulative >= fraction*total {
+			return times[i]
+		}
+	}
+	return 0
+}
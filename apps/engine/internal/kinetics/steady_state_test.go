@@ -0,0 +1,243 @@
+package kinetics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAnalyzeSteadyState_DailyMatchesAnalyticAccumulation(t *testing.T) {
+	tests := []struct {
+		name     string
+		halfLife float64
+		period   float64
+	}{
+		{"24h half-life daily", 1440, MinutesPerDay},
+		{"vitamin d3 daily", 21600, MinutesPerDay},
+		{"semaglutide weekly", 10080, MinutesPerWeek},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := AnalyzeSteadyState(DosingSchedule{
+				PeriodMinutes: tt.period,
+				Doses:         []ScheduledDose{{Dose: 100, AtMinutes: 480}},
+				PK:            SupplementPK{KineticsType: FirstOrder, PeakMinutes: 60, HalfLifeMinutes: tt.halfLife},
+			})
+
+			// R = 1 / (1 - e^(-k*tau))
+			k := math.Log(2) / tt.halfLife
+			expected := 1 / (1 - math.Exp(-k*tt.period))
+			if !approxEqual(result.AccumulationRatio, expected, expected*0.01) {
+				t.Errorf("Accumulation ratio = %v, want ~%v", result.AccumulationRatio, expected)
+			}
+
+			// Peak occurs at Tmax after the dose
+			if !approxEqual(result.PeakAtMinutes, 540, tt.period/steadyStateSamples) {
+				t.Errorf("Steady-state peak at %v, want 540", result.PeakAtMinutes)
+			}
+
+			// Trough is just before the next dose: peak * e^(-k*(tau - tmax))
+			expectedTrough := result.PeakPercent * math.Exp(-k*(tt.period-60))
+			if !approxEqual(result.TroughPercent, expectedTrough, expectedTrough*0.02) {
+				t.Errorf("Steady-state trough = %v, want ~%v", result.TroughPercent, expectedTrough)
+			}
+		})
+	}
+}
+
+func TestAnalyzeSteadyState_TimeToSteadyState(t *testing.T) {
+	result := AnalyzeSteadyState(DosingSchedule{
+		PeriodMinutes: MinutesPerDay,
+		Doses:         []ScheduledDose{{Dose: 5, AtMinutes: 480}},
+		PK:            SupplementPK{PeakMinutes: 720, HalfLifeMinutes: 21600},
+	})
+
+	// 90% of steady state after log2(10) ≈ 3.32 half-lives
+	expected := math.Log2(10) * 21600
+	if !approxEqual(result.TimeToSteadyStateMinutes, expected, 1e-6) {
+		t.Errorf("Time to steady state = %v, want %v", result.TimeToSteadyStateMinutes, expected)
+	}
+	if result.DosesToSteadyState != 50 {
+		t.Errorf("Doses to steady state = %v, want 50", result.DosesToSteadyState)
+	}
+}
+
+func TestAnalyzeSteadyState_ShortHalfLifeDoesNotAccumulate(t *testing.T) {
+	result := AnalyzeSteadyState(DosingSchedule{
+		PeriodMinutes: MinutesPerDay,
+		Doses:         []ScheduledDose{{Dose: 200, AtMinutes: 480}},
+		PK:            SupplementPK{KineticsType: FirstOrder, PeakMinutes: 45, HalfLifeMinutes: 120},
+	})
+
+	if !approxEqual(result.AccumulationRatio, 1, 0.01) {
+		t.Errorf("Short half-life should not accumulate, got ratio %v", result.AccumulationRatio)
+	}
+	if result.TroughPercent >= 1 {
+		t.Errorf("Short half-life should clear between doses, got trough %v", result.TroughPercent)
+	}
+}
+
+func TestAnalyzeSteadyState_SplitDosing(t *testing.T) {
+	pk := SupplementPK{KineticsType: FirstOrder, PeakMinutes: 60, HalfLifeMinutes: 720}
+
+	once := AnalyzeSteadyState(DosingSchedule{
+		PeriodMinutes: MinutesPerDay,
+		Doses:         []ScheduledDose{{Dose: 200, AtMinutes: 480}},
+		PK:            pk,
+	})
+	split := AnalyzeSteadyState(DosingSchedule{
+		PeriodMinutes: MinutesPerDay,
+		Doses:         []ScheduledDose{{Dose: 100, AtMinutes: 480}, {Dose: 100, AtMinutes: 1200}},
+		PK:            pk,
+	})
+
+	if split.TroughPercent <= once.TroughPercent {
+		t.Errorf("Split dosing should raise the trough. Once: %v, split: %v", once.TroughPercent, split.TroughPercent)
+	}
+	if split.DosesToSteadyState != 2*once.DosesToSteadyState {
+		t.Errorf("Split dosing should double doses to steady state. Once: %v, split: %v", once.DosesToSteadyState, split.DosesToSteadyState)
+	}
+}
+
+func TestAnalyzeSteadyState_CompartmentModelMatchesClosedForm(t *testing.T) {
+	pk := SupplementPK{PeakMinutes: 90, HalfLifeMinutes: 1440}
+	closedForm := pk
+	closedForm.KineticsType = OneCompartment
+	compartment := pk
+	compartment.CompartmentModel = "one_compartment"
+
+	schedule := DosingSchedule{
+		PeriodMinutes: MinutesPerDay,
+		Doses:         []ScheduledDose{{Dose: 100, AtMinutes: 480}, {Dose: 50, AtMinutes: 1200}},
+	}
+	schedule.PK = closedForm
+	want := AnalyzeSteadyState(schedule)
+	schedule.PK = compartment
+	got := AnalyzeSteadyState(schedule)
+
+	for _, c := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"peak", got.PeakPercent, want.PeakPercent},
+		{"trough", got.TroughPercent, want.TroughPercent},
+		{"average", got.AveragePercent, want.AveragePercent},
+		{"accumulation", got.AccumulationRatio, want.AccumulationRatio},
+	} {
+		if !approxEqual(c.got, c.want, c.want*0.01) {
+			t.Errorf("%s: compartment model %v, closed form %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+// superposedSteadyState returns the peak and trough over the last of periods repetitions
+// of the schedule, superimposing every dose with CalculateMultiDoseConcentrationCurve.
+func superposedSteadyState(schedule DosingSchedule, periods int) (float64, float64) {
+	var doses []DoseEvent
+	for n := 0; n < periods; n++ {
+		for _, d := range schedule.Doses {
+			doses = append(doses, DoseEvent{
+				Dose:      d.Dose,
+				AtMinutes: d.AtMinutes + float64(n)*schedule.PeriodMinutes,
+				PK:        schedule.PK,
+			})
+		}
+	}
+
+	step := schedule.PeriodMinutes / steadyStateSamples
+	times := make([]float64, steadyStateSamples)
+	for i := range times {
+		times[i] = float64(periods-1)*schedule.PeriodMinutes + float64(i)*step
+	}
+
+	peak, trough := 0.0, math.Inf(1)
+	for _, c := range CalculateMultiDoseConcentrationCurve(doses, times) {
+		peak = math.Max(peak, c)
+		trough = math.Min(trough, c)
+	}
+	return peak, trough
+}
+
+func TestAnalyzeSteadyState_TerminalRateMatchesSuperposition(t *testing.T) {
+	tests := []struct {
+		name string
+		pk   SupplementPK
+	}{
+		// ka < ke: the decline follows absorption, not the 3h half-life
+		{"flip-flop bateman", SupplementPK{KineticsType: OneCompartment, PeakMinutes: 480, HalfLifeMinutes: 180}},
+		// Terminal rate β ≈ 0.27 ke with the default k12 and k21
+		{"two compartment", SupplementPK{CompartmentModel: "two_compartment", PeakMinutes: 90, HalfLifeMinutes: 1440}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := DosingSchedule{
+				PeriodMinutes: MinutesPerDay,
+				Doses:         []ScheduledDose{{Dose: 100, AtMinutes: 480}},
+				PK:            tt.pk,
+			}
+			result := AnalyzeSteadyState(schedule)
+			peak, trough := superposedSteadyState(schedule, 80)
+
+			// Superposition drops doses below the 1% cutoff, which add up to at most
+			// 1 / (1 - q) percent at steady state
+			k, _ := terminalRate(tt.pk)
+			truncated := 1 / (1 - math.Exp(-k*MinutesPerDay))
+			for _, c := range []struct {
+				name      string
+				got, want float64
+			}{
+				{"peak", result.PeakPercent, peak},
+				{"trough", result.TroughPercent, trough},
+			} {
+				if c.got < c.want-0.1 || c.got > c.want+truncated {
+					t.Errorf("%s = %v, want %v to %v", c.name, c.got, c.want, c.want+truncated)
+				}
+			}
+
+			if want := math.Log2(10) * math.Ln2 / k; !approxEqual(result.TimeToSteadyStateMinutes, want, 1e-6) {
+				t.Errorf("Time to steady state = %v, want %v", result.TimeToSteadyStateMinutes, want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeSteadyState_CompartmentModelsMatchSuperposition(t *testing.T) {
+	for _, model := range []string{"two_compartment", "enterohepatic"} {
+		t.Run(model, func(t *testing.T) {
+			schedule := DosingSchedule{
+				PeriodMinutes: MinutesPerDay,
+				Doses:         []ScheduledDose{{Dose: 100, AtMinutes: 480}, {Dose: 50, AtMinutes: 1200}},
+				PK:            SupplementPK{CompartmentModel: model, PeakMinutes: 90, HalfLifeMinutes: 1440},
+			}
+			result := AnalyzeSteadyState(schedule)
+			peak, trough := superposedSteadyState(schedule, 80)
+
+			// Superposition drops doses below the 1% cutoff, which the geometric tail keeps;
+			// enterohepatic curves are evaluated explicitly up to the same cutoff
+			truncated := 0.0
+			if k, ok := terminalRate(schedule.PK); ok {
+				truncated = float64(len(schedule.Doses)) / (1 - math.Exp(-k*MinutesPerDay))
+			}
+			for _, c := range []struct {
+				name      string
+				got, want float64
+			}{
+				{"peak", result.PeakPercent, peak},
+				{"trough", result.TroughPercent, trough},
+			} {
+				// Each curve locates its Cmax on a grid that includes its own sample times
+				slack := c.want * 0.005
+				if c.got < c.want-slack || c.got > c.want+truncated+slack {
+					t.Errorf("%s = %v, want %v to %v", c.name, c.got, c.want, c.want+truncated)
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzeSteadyState_EmptySchedule(t *testing.T) {
+	if result := AnalyzeSteadyState(DosingSchedule{PeriodMinutes: MinutesPerDay}); result != (SteadyStateResult{}) {
+		t.Errorf("Empty schedule should return zero result, got %+v", result)
+	}
+}
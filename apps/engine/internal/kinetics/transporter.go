@@ -0,0 +1,92 @@
+package kinetics
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrMissingAffinity is returned when a compound has no parameter to estimate its
+// transporter affinity from.
+var ErrMissingAffinity = errors.New("kinetics: Km or saturation dose is required for transporter competition")
+
+// TransporterDose is a dose absorbed through an intestinal transporter shared with
+// other compounds (e.g. DMT1 for iron and zinc).
+type TransporterDose struct {
+	Dose      float64 // Absorbed compound (mg), elemental for minerals
+	AtMinutes float64 // Time of ingestion
+	PK        SupplementPK
+}
+
+// TransporterAffinity returns the amount (mg) at which the compound half-saturates its
+// transporter: Km when known, otherwise the absorption saturation dose. Intake references
+// such as the RDA say nothing about transporter capacity and are not used.
+func TransporterAffinity(pk SupplementPK) (float64, error) {
+	switch {
+	case pk.Km > 0:
+		return pk.Km, nil
+	case pk.AbsorptionSaturationDose > 0:
+		return pk.AbsorptionSaturationDose, nil
+	}
+	return 0, ErrMissingAffinity
+}
+
+// AbsorptionWindow returns the period (minutes) a dose spends at the absorption site,
+// defaulting to twice Tmax.
+func AbsorptionWindow(pk SupplementPK) float64 {
+	if pk.AbsorptionWindowMinutes > 0 {
+		return pk.AbsorptionWindowMinutes
+	}
+	return 2 * peakMinutesOrDefault(pk)
+}
+
+// GutOverlap returns the fraction (0-1) of target's absorption window during which
+// competitor is also in its absorption window.
+func GutOverlap(target, competitor TransporterDose) float64 {
+	targetEnd := target.AtMinutes + AbsorptionWindow(target.PK)
+	competitorEnd := competitor.AtMinutes + AbsorptionWindow(competitor.PK)
+
+	overlap := math.Min(targetEnd, competitorEnd) - math.Max(target.AtMinutes, competitor.AtMinutes)
+	if overlap <= 0 {
+		return 0
+	}
+	return overlap / AbsorptionWindow(target.PK)
+}
+
+// CompetitiveAbsorptionLoss returns the fraction (0-1) of target's absorbed dose lost to
+// competitors for the same transporter.
+//
+// Competitors act as competitive inhibitors of Michaelis-Menten uptake, raising the
+// target's apparent Km by their load on the transporter, weighted by how long they
+// share the gut window:
+//
+//	L    = Σ overlap_i * D_i / K_i
+//	v'/v = (K + D) / (K * (1 + L) + D)
+//	loss = 1 - v'/v = K * L / (K * (1 + L) + D)
+//
+// where K is the target's transporter affinity and D its dose. As uptake is rate-limited
+// over a fixed window, the absorbed fraction falls with the uptake rate.
+func CompetitiveAbsorptionLoss(target TransporterDose, competitors []TransporterDose) (float64, error) {
+	affinity, err := TransporterAffinity(target.PK)
+	if err != nil {
+		return 0, err
+	}
+
+	load := 0.0
+	for _, c := range competitors {
+		overlap := GutOverlap(target, c)
+		if overlap == 0 || c.Dose <= 0 {
+			continue
+		}
+		competitorAffinity, err := TransporterAffinity(c.PK)
+		if err != nil {
+			return 0, err
+		}
+		load += overlap * c.Dose / competitorAffinity
+	}
+	if load == 0 {
+		return 0, nil
+	}
+
+	dose := math.Max(target.Dose, 0)
+	return affinity * load / (affinity*(1+load) + dose), nil
+}
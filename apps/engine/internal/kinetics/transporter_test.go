@@ -0,0 +1,85 @@
+package kinetics
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTransporterAffinity_Fallbacks(t *testing.T) {
+	tests := []struct {
+		name string
+		pk   SupplementPK
+		want float64
+	}{
+		{"Km", SupplementPK{Km: 30, AbsorptionSaturationDose: 50, RDAAmount: 18}, 30},
+		{"saturation dose", SupplementPK{AbsorptionSaturationDose: 50, RDAAmount: 18}, 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TransporterAffinity(tt.pk)
+			if err != nil || got != tt.want {
+				t.Errorf("TransporterAffinity() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := TransporterAffinity(SupplementPK{}); !errors.Is(err, ErrMissingAffinity) {
+		t.Errorf("Expected ErrMissingAffinity, got %v", err)
+	}
+	if _, err := TransporterAffinity(SupplementPK{RDAAmount: 0.9}); !errors.Is(err, ErrMissingAffinity) {
+		t.Errorf("Expected ErrMissingAffinity with only an RDA, got %v", err)
+	}
+}
+
+func TestGutOverlap(t *testing.T) {
+	target := TransporterDose{PK: SupplementPK{AbsorptionWindowMinutes: 180}}
+
+	if got := GutOverlap(target, TransporterDose{PK: SupplementPK{AbsorptionWindowMinutes: 240}}); got != 1 {
+		t.Errorf("Co-ingested doses should fully overlap, got %v", got)
+	}
+	if got := GutOverlap(target, TransporterDose{AtMinutes: 120, PK: SupplementPK{AbsorptionWindowMinutes: 240}}); !approxEqual(got, 1.0/3, epsilon) {
+		t.Errorf("Dose 2h later should overlap 1/3 of the window, got %v", got)
+	}
+	if got := GutOverlap(target, TransporterDose{AtMinutes: 180, PK: SupplementPK{AbsorptionWindowMinutes: 240}}); got != 0 {
+		t.Errorf("Dose after the window should not overlap, got %v", got)
+	}
+	if got := AbsorptionWindow(SupplementPK{PeakMinutes: 90}); got != 180 {
+		t.Errorf("Window should default to 2×Tmax, got %v", got)
+	}
+}
+
+func TestCompetitiveAbsorptionLoss(t *testing.T) {
+	iron := TransporterDose{Dose: 18, PK: SupplementPK{Km: 30, AbsorptionWindowMinutes: 180}}
+	calcium := TransporterDose{Dose: 500, PK: SupplementPK{AbsorptionSaturationDose: 1000, AbsorptionWindowMinutes: 240}}
+
+	// L = 500/1000 = 0.5; loss = 30*0.5 / (30*1.5 + 18)
+	loss, err := CompetitiveAbsorptionLoss(iron, []TransporterDose{calcium})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !approxEqual(loss, 15.0/63, epsilon) {
+		t.Errorf("Expected loss of %v, got %v", 15.0/63, loss)
+	}
+
+	calcium.AtMinutes = 120
+	separated, _ := CompetitiveAbsorptionLoss(iron, []TransporterDose{calcium})
+	if separated >= loss || separated <= 0 {
+		t.Errorf("Partial overlap should reduce the loss below %v, got %v", loss, separated)
+	}
+
+	calcium.AtMinutes = 240
+	if apart, _ := CompetitiveAbsorptionLoss(iron, []TransporterDose{calcium}); apart != 0 {
+		t.Errorf("Separated doses should not compete, got %v", apart)
+	}
+
+	doubled := TransporterDose{Dose: 1000, PK: calcium.PK}
+	heavier, _ := CompetitiveAbsorptionLoss(iron, []TransporterDose{doubled})
+	if heavier <= loss || heavier >= 1 {
+		t.Errorf("Larger competitor dose should increase the loss within (%v, 1), got %v", loss, heavier)
+	}
+
+	if _, err := CompetitiveAbsorptionLoss(iron, []TransporterDose{{Dose: 100}}); !errors.Is(err, ErrMissingAffinity) {
+		t.Errorf("Expected ErrMissingAffinity for an uncharacterized competitor, got %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package kinetics
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+)
+
+// DefaultParameterCV is the between-subject coefficient of variation assumed for PK
+// parameters without curated variability; 30% is typical of oral absorption studies.
+const DefaultParameterCV = 0.3
+
+// DefaultMonteCarloSamples is the number of simulated subjects when not specified.
+const DefaultMonteCarloSamples = 500
+
+// Variability holds the between-subject coefficients of variation (SD / median) of PK
+// parameters. A zero CV keeps the parameter fixed.
+type Variability struct {
+	HalfLifeCV        float64
+	PeakCV            float64 // Tmax
+	BioavailabilityCV float64 // F
+	VmaxCV            float64
+	KmCV              float64
+}
+
+// MonteCarloOptions controls CalculateConcentrationBands.
+type MonteCarloOptions struct {
+	Samples int   // Simulated subjects (DefaultMonteCarloSamples when zero)
+	Seed    int64 // Random seed; equal seeds give identical bands
+}
+
+// ConcentrationBand is the spread of simulated concentrations at one time,
+// as percentages of the population single-dose Cmax.
+type ConcentrationBand struct {
+	Minutes float64
+	P5      float64
+	P50     float64
+	P95     float64
+}
+
+// parameterDeviation is one simulated subject's standard normal draw per parameter.
+type parameterDeviation struct {
+	halfLife, peak, bioavailability, vmax, km float64
+}
+
+// CalculateConcentrationBands simulates the combined concentration of doses for many
+// subjects and returns the 5th, 50th and 95th percentiles at each time.
+//
+// Each parameter is lognormal with the stored value as its median:
+//
+//	x = x_stored * e^(σ*z),  σ² = ln(1 + CV²),  z ~ N(0, 1)
+//
+// A subject draws z once and applies it to every dose, since variability is between
+// people rather than between doses. F deviations scale exposure (capped at F = 100% when
+// known), like routes and meals.
+func CalculateConcentrationBands(doses []DoseEvent, times []float64, opts MonteCarloOptions) []ConcentrationBand {
+	samples := opts.Samples
+	if samples <= 0 {
+		samples = DefaultMonteCarloSamples
+	}
+	rng := rand.New(rand.NewPCG(uint64(opts.Seed), 0))
+
+	values := make([][]float64, len(times))
+	for i := range values {
+		values[i] = make([]float64, 0, samples)
+	}
+
+	sampled := make([]DoseEvent, len(doses))
+	exposures := make([]float64, len(doses))
+	total := make([]float64, len(times))
+	shifted := make([]float64, len(times))
+	curve := make([]float64, len(times))
+	for s := 0; s < samples; s++ {
+		deviation := parameterDeviation{
+			halfLife:        rng.NormFloat64(),
+			peak:            rng.NormFloat64(),
+			bioavailability: rng.NormFloat64(),
+			vmax:            rng.NormFloat64(),
+			km:              rng.NormFloat64(),
+		}
+		for i, d := range doses {
+			sampled[i] = d
+			sampled[i].PK, exposures[i] = samplePK(d.PK, deviation)
+		}
+
+		for i := range total {
+			total[i] = 0
+		}
+		for j, d := range sampled {
+			for i, t := range times {
+				shifted[i] = t - d.AtMinutes
+			}
+			fillConcentrationCurve(ConcentrationParams{
+				Dose:  d.Dose,
+				PK:    d.PK,
+				Route: d.Route,
+				Meal:  d.Meal,
+			}, shifted, curve)
+			for i, c := range curve {
+				total[i] += exposures[j] * c
+			}
+		}
+		for i := range times {
+			values[i] = append(values[i], total[i])
+		}
+	}
+
+	bands := make([]ConcentrationBand, len(times))
+	for i, t := range times {
+		sort.Float64s(values[i])
+		bands[i] = ConcentrationBand{
+			Minutes: t,
+			P5:      percentile(values[i], 0.05),
+			P50:     percentile(values[i], 0.50),
+			P95:     percentile(values[i], 0.95),
+		}
+	}
+	return bands
+}
+
+// samplePK applies a subject's deviation to pk and returns the sampled parameters with
+// the exposure factor of the sampled F.
+func samplePK(pk SupplementPK, deviation parameterDeviation) (SupplementPK, float64) {
+	v := pk.Variability
+	sampled := pk
+
+	if v.HalfLifeCV > 0 {
+		sampled.HalfLifeMinutes = halfLifeOrDefault(pk) * lognormalFactor(v.HalfLifeCV, deviation.halfLife)
+	}
+	if v.PeakCV > 0 {
+		factor := lognormalFactor(v.PeakCV, deviation.peak)
+		sampled.PeakMinutes = peakMinutesOrDefault(pk) * factor
+		if pk.AbsorptionRateConstant > 0 {
+			sampled.AbsorptionRateConstant = pk.AbsorptionRateConstant / factor
+		}
+	}
+	if v.VmaxCV > 0 && pk.Vmax > 0 {
+		sampled.Vmax = pk.Vmax * lognormalFactor(v.VmaxCV, deviation.vmax)
+	}
+	if v.KmCV > 0 && pk.Km > 0 {
+		sampled.Km = pk.Km * lognormalFactor(v.KmCV, deviation.km)
+	}
+
+	exposure := 1.0
+	if v.BioavailabilityCV > 0 {
+		exposure = lognormalFactor(v.BioavailabilityCV, deviation.bioavailability)
+		if pk.BioavailabilityPercent > 0 {
+			sampled.BioavailabilityPercent = math.Min(pk.BioavailabilityPercent*exposure, 100)
+			exposure = sampled.BioavailabilityPercent / pk.BioavailabilityPercent
+		}
+	}
+
+	return sampled, exposure
+}
+
+// lognormalFactor returns e^(σ*z) for a lognormal with median 1 and the given CV.
+func lognormalFactor(cv float64, z float64) float64 {
+	sigma := math.Sqrt(math.Log(1 + cv*cv))
+	return math.Exp(sigma * z)
+}
+
+// percentile returns the p-quantile (0-1) of sorted values with linear interpolation.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	position := p * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	fraction := position - float64(lower)
+	return sorted[lower] + (sorted[upper]-sorted[lower])*fraction
+}
@@ -0,0 +1,99 @@
+package kinetics
+
+import (
+	"testing"
+)
+
+func bandTimes() []float64 {
+	times := make([]float64, 0, 25)
+	for t := 0.0; t <= 720; t += 30 {
+		times = append(times, t)
+	}
+	return times
+}
+
+func TestCalculateConcentrationBands_NoVariabilityMatchesCurve(t *testing.T) {
+	doses := []DoseEvent{
+		{Dose: 100, AtMinutes: 0, PK: SupplementPK{KineticsType: FirstOrder, PeakMinutes: 60, HalfLifeMinutes: 240}},
+	}
+
+	bands := CalculateConcentrationBands(doses, bandTimes(), MonteCarloOptions{Samples: 20, Seed: 1})
+
+	for _, band := range bands {
+		expected := CalculateMultiDoseConcentration(doses, band.Minutes)
+		if !approxEqual(band.P5, expected, epsilon) || !approxEqual(band.P95, expected, epsilon) {
+			t.Errorf("t=%v: band [%v, %v] should collapse to %v", band.Minutes, band.P5, band.P95, expected)
+		}
+	}
+}
+
+func TestCalculateConcentrationBands_OrderedAroundMedian(t *testing.T) {
+	pk := SupplementPK{
+		KineticsType:    OneCompartment,
+		PeakMinutes:     60,
+		HalfLifeMinutes: 240,
+		Variability:     Variability{HalfLifeCV: 0.3, PeakCV: 0.3, BioavailabilityCV: 0.3},
+	}
+	doses := []DoseEvent{{Dose: 100, AtMinutes: 0, PK: pk}}
+
+	bands := CalculateConcentrationBands(doses, bandTimes(), MonteCarloOptions{Samples: 1000, Seed: 42})
+
+	for _, band := range bands[1:] {
+		if band.P5 > band.P50 || band.P50 > band.P95 {
+			t.Errorf("t=%v: percentiles out of order: %v, %v, %v", band.Minutes, band.P5, band.P50, band.P95)
+		}
+		if band.P95-band.P5 <= 0 {
+			t.Errorf("t=%v: expected a non-zero band", band.Minutes)
+		}
+	}
+
+	// At the population peak the median subject stays close to the deterministic curve
+	peak := bands[2]
+	deterministic := CalculateMultiDoseConcentration(doses, peak.Minutes)
+	if !approxEqual(peak.P50, deterministic, 5) {
+		t.Errorf("P50 at Tmax = %v, want close to %v", peak.P50, deterministic)
+	}
+}
+
+func TestCalculateConcentrationBands_Seeded(t *testing.T) {
+	pk := SupplementPK{
+		KineticsType:    MichaelisMenten,
+		PeakMinutes:     120,
+		HalfLifeMinutes: 300,
+		Vmax:            2,
+		Km:              100,
+		Variability:     Variability{HalfLifeCV: 0.25, PeakCV: 0.2, VmaxCV: 0.4, KmCV: 0.4},
+	}
+	doses := []DoseEvent{{Dose: 500, AtMinutes: 0, PK: pk}, {Dose: 500, AtMinutes: 360, PK: pk}}
+
+	first := CalculateConcentrationBands(doses, bandTimes(), MonteCarloOptions{Samples: 200, Seed: 7})
+	second := CalculateConcentrationBands(doses, bandTimes(), MonteCarloOptions{Samples: 200, Seed: 7})
+	other := CalculateConcentrationBands(doses, bandTimes(), MonteCarloOptions{Samples: 200, Seed: 8})
+
+	differs := false
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("t=%v: same seed gave %+v and %+v", first[i].Minutes, first[i], second[i])
+		}
+		if first[i] != other[i] {
+			differs = true
+		}
+	}
+	if !differs {
+		t.Errorf("Different seeds should give different bands")
+	}
+}
+
+func TestPercentile_Interpolates(t *testing.T) {
+	values := []float64{0, 10, 20, 30, 40}
+
+	if got := percentile(values, 0.5); got != 20 {
+		t.Errorf("median = %v, want 20", got)
+	}
+	if got := percentile(values, 0.05); !approxEqual(got, 2, epsilon) {
+		t.Errorf("P5 = %v, want 2", got)
+	}
+	if got := percentile(nil, 0.5); got != 0 {
+		t.Errorf("empty percentile = %v, want 0", got)
+	}
+}
@@ -31,6 +31,34 @@ const (
 	DosageUnitG   DosageUnit = "g"
 	DosageUnitIU  DosageUnit = "IU"
 	DosageUnitMl  DosageUnit = "ml"
+	// Dropper drops; converted through the supplement's drops per ml
+	DosageUnitDrops DosageUnit = "drops"
+	// Serving units; converted through a product's serving size
+	DosageUnitCapsule DosageUnit = "capsule"
+	DosageUnitTablet  DosageUnit = "tablet"
+	DosageUnitScoop   DosageUnit = "scoop"
+	DosageUnitServing DosageUnit = "serving"
+)
+
+// IsServing reports whether the unit counts servings of a product rather than an amount
+// of the compound.
+func (u DosageUnit) IsServing() bool {
+	switch u {
+	case DosageUnitCapsule, DosageUnitTablet, DosageUnitScoop, DosageUnitServing:
+		return true
+	default:
+		return false
+	}
+}
+
+// MealContext represents what a supplement was taken with
+type MealContext string
+
+const (
+	MealContextFasted   MealContext = "fasted"
+	MealContextWithMeal MealContext = "with_meal"
+	MealContextWithFat  MealContext = "with_fat"
+	MealContextPostMeal MealContext = "post_meal"
 )
 
 // Supplement represents a supplement in the database
@@ -38,8 +66,13 @@ type Supplement struct {
 	ID              string     `json:"id"`
 	Name            string     `json:"name"`
 	Form            *string    `json:"form,omitempty"`
-	ElementalWeight *float32   `json:"elementalWeight,omitempty"`
+	ElementalWeight *float64   `json:"elementalWeight,omitempty"`
 	DefaultUnit     DosageUnit `json:"defaultUnit"`
+	SafetyCategory  *string    `json:"safetyCategory,omitempty"`
+	McgPerIU        *float64   `json:"mcgPerIu,omitempty"` // Set for supplements dosed in IU
+	// Set for liquids dosed in ml or drops
+	ConcentrationMgPerMl *float64 `json:"concentrationMgPerMl,omitempty"`
+	DropsPerMl           *float64 `json:"dropsPerMl,omitempty"` // Defaults to 20 when unset
 }
 
 // Interaction represents an interaction between two supplements
@@ -67,9 +100,9 @@ type RatioRule struct {
 	ID                 string   `json:"id"`
 	SourceSupplementID string   `json:"sourceSupplementId"`
 	TargetSupplementID string   `json:"targetSupplementId"`
-	MinRatio           *float32 `json:"minRatio,omitempty"`
-	MaxRatio           *float32 `json:"maxRatio,omitempty"`
-	OptimalRatio       *float32 `json:"optimalRatio,omitempty"`
+	MinRatio           *float64 `json:"minRatio,omitempty"`
+	MaxRatio           *float64 `json:"maxRatio,omitempty"`
+	OptimalRatio       *float64 `json:"optimalRatio,omitempty"`
 	WarningMessage     string   `json:"warningMessage"`
 	Severity           Severity `json:"severity"`
 }
@@ -87,8 +120,35 @@ type LogEntry struct {
 // DosageInput represents a supplement with its dosage for ratio calculations
 type DosageInput struct {
 	SupplementID string     `json:"supplementId"`
-	Amount       float32    `json:"amount"`
+	Amount       float64    `json:"amount"`
 	Unit         DosageUnit `json:"unit"`
+	// Optional: product whose serving size resolves serving units (defaults to the
+	// user's own product with that serving unit, then the catalog's)
+	ProductID *string `json:"productId,omitempty"`
+}
+
+// ProductInfo contains basic product info for responses
+type ProductInfo struct {
+	ID    string  `json:"id"`
+	Name  string  `json:"name"`
+	Brand *string `json:"brand,omitempty"`
+}
+
+// ResolvedDosage echoes how a requested dosage was interpreted
+type ResolvedDosage struct {
+	SupplementID string     `json:"supplementId"`
+	Amount       float64    `json:"amount"`
+	Unit         DosageUnit `json:"unit"`
+	// Product whose serving size was used, for serving units
+	Product *ProductInfo `json:"product,omitempty"`
+	// Mass of the compound, absent when the dosage could not be converted
+	AmountMg *float64 `json:"amountMg,omitempty"`
+}
+
+// ProductDosageInput is a dose of a product, such as a multivitamin, counted in servings
+type ProductDosageInput struct {
+	ProductID string  `json:"productId"`
+	Servings  float64 `json:"servings"`
 }
 
 // AnalyzeRequest is the request body for the analyze endpoint
@@ -96,10 +156,31 @@ type AnalyzeRequest struct {
 	SupplementIDs []string `json:"supplementIds"`
 	// Optional: dosages for ratio calculations (if not provided, ratio checks are skipped)
 	Dosages []DosageInput `json:"dosages,omitempty"`
+	// Optional: products, expanded into their ingredients' supplements and dosages
+	Products []ProductDosageInput `json:"products,omitempty"`
 	// Optional: include logs for timing analysis
 	IncludeTiming bool `json:"includeTiming,omitempty"`
+	// Optional: meal context of the intake (if not provided, meal advisories are skipped)
+	MealContext MealContext `json:"mealContext,omitempty"`
+	// Optional: what ratio rules are evaluated against (defaults to the dosages)
+	RatioIntake RatioIntake `json:"ratioIntake,omitempty"`
+	// Optional, logged intake only: rolling window in hours (defaults to the current day)
+	IntakeWindowHours float64 `json:"intakeWindowHours,omitempty"`
+	// Optional, logged intake only: IANA time zone the current day starts in (defaults to UTC)
+	TimeZone string `json:"timeZone,omitempty"`
 }
 
+// RatioIntake selects the intake that ratio rules are evaluated against
+type RatioIntake string
+
+const (
+	// The dosages in the request
+	RatioIntakeDosages RatioIntake = "dosages"
+	// The user's logged intake over the current day or rolling window, plus the dosages
+	// in the request, which are taken to be planned rather than logged
+	RatioIntakeLogged RatioIntake = "logged"
+)
+
 // TimingCheckRequest is the request body for the timing check endpoint
 type TimingCheckRequest struct {
 	SupplementID string    `json:"supplementId"`
@@ -111,6 +192,283 @@ type TimingCheckResponse struct {
 	Warnings []TimingWarning `json:"warnings"`
 }
 
+// TimelineRequest is the request body for the timeline endpoint
+type TimelineRequest struct {
+	// Optional: defaults to 24 hours before now
+	WindowStart *time.Time `json:"windowStart,omitempty"`
+	// Optional: defaults to 4 hours after now (projection)
+	WindowEnd *time.Time `json:"windowEnd,omitempty"`
+	// Optional: defaults to 15 minutes
+	IntervalMinutes int `json:"intervalMinutes,omitempty"`
+	// Optional: add P5/P50/P95 bands from simulated between-subject variability
+	Uncertainty bool `json:"uncertainty,omitempty"`
+	// Optional: Monte Carlo seed, defaults to a fixed seed so bands are reproducible
+	Seed *int64 `json:"seed,omitempty"`
+	// Optional: simulated subjects, defaults to 500
+	Samples int `json:"samples,omitempty"`
+	// Optional: scales mg/L concentrations, defaults to 70 kg
+	BodyWeightKg float64 `json:"bodyWeightKg,omitempty"`
+}
+
+// TimelineResponse is the response from the timeline endpoint
+type TimelineResponse struct {
+	WindowStart     time.Time            `json:"windowStart"`
+	WindowEnd       time.Time            `json:"windowEnd"`
+	IntervalMinutes int                  `json:"intervalMinutes"`
+	Curves          []ConcentrationCurve `json:"curves"`
+}
+
+// ConcentrationCurve is the sampled concentration of one supplement over the timeline window
+type ConcentrationCurve struct {
+	Supplement SupplementInfo        `json:"supplement"`
+	DoseCount  int                   `json:"doseCount"`
+	Samples    []ConcentrationSample `json:"samples"`
+}
+
+// ConcentrationSample is a single point of a concentration curve
+type ConcentrationSample struct {
+	MinutesFromStart float64   `json:"minutesFromStart"`
+	Timestamp        time.Time `json:"timestamp"`
+	Concentration    float64   `json:"concentration"` // Percentage of single-dose Cmax
+	// Absolute plasma concentration, present when the supplement has a volume of distribution
+	ConcentrationMgPerL *float64 `json:"concentrationMgPerL,omitempty"`
+	// Uncertainty bands, present when requested
+	P5  *float64 `json:"p5,omitempty"`
+	P50 *float64 `json:"p50,omitempty"`
+	P95 *float64 `json:"p95,omitempty"`
+}
+
+// EffectRequest is the request body for the effect endpoint
+type EffectRequest struct {
+	// Optional: restrict to these supplements (defaults to all logged supplements)
+	SupplementIDs []string `json:"supplementIds,omitempty"`
+	// Optional: defaults to 24 hours before now
+	WindowStart *time.Time `json:"windowStart,omitempty"`
+	// Optional: defaults to 4 hours after now (projection)
+	WindowEnd *time.Time `json:"windowEnd,omitempty"`
+	// Optional: defaults to 15 minutes
+	IntervalMinutes int `json:"intervalMinutes,omitempty"`
+	// Optional: scales mg/L concentrations, defaults to 70 kg
+	BodyWeightKg float64 `json:"bodyWeightKg,omitempty"`
+}
+
+// EffectResponse is the response from the effect endpoint
+type EffectResponse struct {
+	WindowStart     time.Time     `json:"windowStart"`
+	WindowEnd       time.Time     `json:"windowEnd"`
+	IntervalMinutes int           `json:"intervalMinutes"`
+	Curves          []EffectCurve `json:"curves"`
+	Unavailable     []EffectGap   `json:"unavailable,omitempty"`
+}
+
+// EffectCurve is the predicted effect intensity of one supplement over the window
+type EffectCurve struct {
+	Supplement      SupplementInfo `json:"supplement"`
+	EffectLabel     *string        `json:"effectLabel,omitempty"` // e.g. "alertness"
+	Emax            float64        `json:"emax"`
+	EC50            float64        `json:"ec50"` // mg/L
+	HillCoefficient float64        `json:"hillCoefficient"`
+	Samples         []EffectSample `json:"samples"`
+}
+
+// EffectSample is a single point of an effect curve
+type EffectSample struct {
+	MinutesFromStart    float64   `json:"minutesFromStart"`
+	Timestamp           time.Time `json:"timestamp"`
+	ConcentrationMgPerL float64   `json:"concentrationMgPerL"`
+	Effect              float64   `json:"effect"`       // Effect intensity in the supplement's effect units
+	PercentOfMax        float64   `json:"percentOfMax"` // Effect as a percentage of Emax
+}
+
+type EffectGapReason string
+
+const (
+	EffectGapMissingPDParameters EffectGapReason = "missing_pd_parameters"
+	EffectGapMissingVolume       EffectGapReason = "missing_volume_of_distribution"
+)
+
+// EffectGap explains why a logged supplement has no effect curve
+type EffectGap struct {
+	Supplement SupplementInfo  `json:"supplement"`
+	Reason     EffectGapReason `json:"reason"`
+}
+
+// ExposureRequest is the request body for the exposure endpoint
+type ExposureRequest struct {
+	// Optional: restrict to these supplements (defaults to all logged supplements)
+	SupplementIDs []string `json:"supplementIds,omitempty"`
+	// Optional: first day as YYYY-MM-DD, defaults to EndDate
+	StartDate string `json:"startDate,omitempty"`
+	// Optional: last day (inclusive) as YYYY-MM-DD, defaults to today
+	EndDate string `json:"endDate,omitempty"`
+	// Optional: IANA time zone for day boundaries, defaults to UTC
+	TimeZone string `json:"timeZone,omitempty"`
+	// Optional: percentage of single-dose Cmax counted by minutesAboveThreshold, defaults to 50
+	ThresholdPercent float64 `json:"thresholdPercent,omitempty"`
+}
+
+// ExposureResponse is the response from the exposure endpoint
+type ExposureResponse struct {
+	TimeZone         string          `json:"timeZone"`
+	ThresholdPercent float64         `json:"thresholdPercent"`
+	Days             []DailyExposure `json:"days"`
+}
+
+// DailyExposure is the exposure to each supplement present on one calendar day
+type DailyExposure struct {
+	Date        string               `json:"date"` // YYYY-MM-DD in the requested time zone
+	Supplements []SupplementExposure `json:"supplements"`
+}
+
+// SupplementExposure summarises one supplement's combined concentration over a day.
+// Concentrations are percentages of the single-dose Cmax.
+type SupplementExposure struct {
+	Supplement            SupplementInfo `json:"supplement"`
+	DoseCount             int            `json:"doseCount"`       // Doses logged on this day
+	AUCPercentHours       float64        `json:"aucPercentHours"` // Area under the curve (% × hours)
+	Peak                  float64        `json:"peak"`
+	PeakAt                time.Time      `json:"peakAt"`
+	MinutesAboveThreshold float64        `json:"minutesAboveThreshold"`
+}
+
+// ParseRequest is the request body for the parse endpoint
+type ParseRequest struct {
+	Text string `json:"text"` // e.g. "2 caps zinc at 9pm with dinner"
+	// Optional: IANA time zone for clock times such as "9pm", defaults to UTC
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// ParseResponse is the response from the parse endpoint
+type ParseResponse struct {
+	LoggedAt time.Time `json:"loggedAt"` // The parsed time, or now when none was given
+	// Matched time expression, e.g. "9pm" or "this morning"
+	TimeDescription *string      `json:"timeDescription,omitempty"`
+	MealContext     MealContext  `json:"mealContext,omitempty"`
+	Items           []ParsedItem `json:"items"`
+}
+
+// ParsedItem is one supplement mentioned in the parsed text
+type ParsedItem struct {
+	Query  string     `json:"query"` // Text naming the supplement
+	Amount *float64   `json:"amount,omitempty"`
+	Unit   DosageUnit `json:"unit,omitempty"`
+	// Set when the name resolved to a single supplement
+	Supplement *SupplementInfo `json:"supplement,omitempty"`
+	// Set when the supplement resolved and an amount was given. Serving units are
+	// converted to the amount per serving of Product, so the dosage is always loggable;
+	// servings no product resolves leave it unset.
+	Dosage *DosageInput `json:"dosage,omitempty"`
+	// Product whose serving size resolved a serving unit
+	Product *ProductInfo `json:"product,omitempty"`
+	// True when several supplements match the name about equally well
+	Ambiguous  bool                  `json:"ambiguous"`
+	Candidates []SupplementCandidate `json:"candidates"` // Best first
+}
+
+// SupplementCandidate is a supplement a parsed name may refer to
+type SupplementCandidate struct {
+	Supplement SupplementInfo `json:"supplement"`
+	Score      float64        `json:"score"` // 0-100
+}
+
+// SteadyStateRequest is the request body for the steady-state endpoint
+type SteadyStateRequest struct {
+	// Optional: restrict the analysis to these supplements (defaults to the whole protocol)
+	SupplementIDs []string `json:"supplementIds,omitempty"`
+}
+
+// SteadyStateResponse is the response from the steady-state endpoint
+type SteadyStateResponse struct {
+	Supplements []SupplementSteadyState `json:"supplements"`
+}
+
+// SupplementSteadyState describes accumulation of a supplement under the user's protocol.
+// Concentrations are percentages of the single-dose Cmax.
+type SupplementSteadyState struct {
+	Supplement             SupplementInfo `json:"supplement"`
+	DosesPerWeek           int            `json:"dosesPerWeek"`
+	HalfLifeHours          float64        `json:"halfLifeHours"`
+	AccumulationRatio      float64        `json:"accumulationRatio"`
+	TimeToSteadyStateHours float64        `json:"timeToSteadyStateHours"` // Time to reach 90% of steady state
+	DosesToSteadyState     int            `json:"dosesToSteadyState"`
+	SteadyStatePeak        float64        `json:"steadyStatePeak"`
+	SteadyStateTrough      float64        `json:"steadyStateTrough"`
+	SteadyStateAverage     float64        `json:"steadyStateAverage"`
+}
+
+// OptimizeDosingRequest is the request body for the dosing optimizer endpoint
+type OptimizeDosingRequest struct {
+	SupplementID string  `json:"supplementId"`
+	WindowStart  string  `json:"windowStart"` // "HH:MM"
+	WindowEnd    string  `json:"windowEnd"`   // "HH:MM"; before WindowStart wraps past midnight
+	MinPercent   float64 `json:"minPercent"`  // Minimum level as a percentage of the reference Cmax
+	MaxDailyDose float64 `json:"maxDailyDose"`
+	// Optional: unit of MaxDailyDose, DoseStep and ReferenceDose (defaults to mg)
+	Unit DosageUnit `json:"unit,omitempty"`
+	// Optional: candidate intake times ("HH:MM"), defaults to the protocol slot times
+	AllowedSlots []string `json:"allowedSlots,omitempty"`
+	// Optional: defaults to the number of allowed slots
+	MaxDosesPerDay int `json:"maxDosesPerDay,omitempty"`
+	// Optional: round each dose up to a multiple of this amount (e.g. capsule size)
+	DoseStep float64 `json:"doseStep,omitempty"`
+	// Optional: dose whose single-dose Cmax is 100%, defaults to MaxDailyDose
+	ReferenceDose float64 `json:"referenceDose,omitempty"`
+}
+
+// OptimizeDosingResponse is the recommended schedule with a single-dose comparison
+type OptimizeDosingResponse struct {
+	Supplement          SupplementInfo `json:"supplement"`
+	Recommended         DosingPlan     `json:"recommended"`
+	SingleDose          DosingPlan     `json:"singleDose"`
+	SplitSavingsPercent float64        `json:"splitSavingsPercent"` // Total dose saved by splitting
+}
+
+// DosingPlan is a daily schedule of equal doses
+type DosingPlan struct {
+	Doses                []PlannedDose `json:"doses"`
+	TotalDailyDoseMg     float64       `json:"totalDailyDoseMg"`
+	MinPercentInWindow   float64       `json:"minPercentInWindow"`   // Lowest steady-state level in the window
+	AbsorptionEfficiency float64       `json:"absorptionEfficiency"` // Absorbed / administered (0-1)
+	MeetsTarget          bool          `json:"meetsTarget"`
+}
+
+// PlannedDose is one dose of a dosing plan
+type PlannedDose struct {
+	Time   string  `json:"time"` // "HH:MM"
+	DoseMg float64 `json:"doseMg"`
+}
+
+// CalibrateRequest is the request body for the calibration endpoint
+type CalibrateRequest struct {
+	BiomarkerType string  `json:"biomarkerType"`
+	Value         float32 `json:"value"`
+	// Optional: must match the biomarker's reference unit when set
+	Unit string `json:"unit,omitempty"`
+	// Optional: defaults to now
+	MeasuredAt *time.Time `json:"measuredAt,omitempty"`
+}
+
+// CalibrateResponse is the fitted calibration for a blood result. It is not persisted.
+type CalibrateResponse struct {
+	BiomarkerType              string        `json:"biomarkerType"`
+	MeasuredValue              float32       `json:"measuredValue"`
+	PredictedValue             float64       `json:"predictedValue"`
+	IndividualAbsorptionFactor float64       `json:"individualAbsorptionFactor"`
+	CalibratedF                *float64      `json:"calibratedF"`  // Bioavailability multiplier (IAF < 1)
+	CalibratedCL               *float64      `json:"calibratedCL"` // Clearance multiplier (IAF > 1)
+	Confidence                 string        `json:"confidence"`   // "high", "medium" or "low"
+	DatapointsUsed             int           `json:"datapointsUsed"`
+	Status                     string        `json:"status"` // Measured value against the reference range
+	GoodnessOfFit              GoodnessOfFit `json:"goodnessOfFit"`
+}
+
+// GoodnessOfFit describes how well the fitted factor explains the user's measurements
+type GoodnessOfFit struct {
+	Measurements int      `json:"measurements"`
+	RSquared     *float64 `json:"rSquared"` // Null with fewer than two measurements
+	RMSE         float64  `json:"rmse"`
+}
+
 // AnalyzeResponse is the response from the analyze endpoint
 type AnalyzeResponse struct {
 	Status              TrafficLightStatus   `json:"status"`
@@ -119,6 +477,39 @@ type AnalyzeResponse struct {
 	TimingWarnings      []TimingWarning      `json:"timingWarnings,omitempty"`
 	RatioWarnings       []RatioWarning       `json:"ratioWarnings,omitempty"`
 	RatioEvaluationGaps []RatioEvaluationGap `json:"ratioEvaluationGaps,omitempty"`
+	MealAdvisories      []MealAdvisory       `json:"mealAdvisories,omitempty"`
+	MetabolicWarnings   []MetabolicWarning   `json:"metabolicWarnings,omitempty"`
+	// How each requested dosage was converted to mass
+	ResolvedDosages []ResolvedDosage `json:"resolvedDosages,omitempty"`
+	// Period of logged intake the ratio rules were evaluated against; unset when only the
+	// dosages were, including when the logged intake could not be loaded
+	RatioIntakeWindow *IntakeWindow `json:"ratioIntakeWindow,omitempty"`
+}
+
+// IntakeWindow is a period of logged intake
+type IntakeWindow struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// MetabolicWarning reports a substrate whose clearance is changed by CYP450 inhibitors
+// or inducers taken alongside it
+type MetabolicWarning struct {
+	Substrate             SupplementInfo         `json:"substrate"`
+	Perpetrators          []MetabolicPerpetrator `json:"perpetrators"`
+	ClearanceFactor       float64                `json:"clearanceFactor"` // <1 slower, >1 faster clearance
+	BaselineHalfLifeHours float64                `json:"baselineHalfLifeHours"`
+	AdjustedHalfLifeHours float64                `json:"adjustedHalfLifeHours"`
+	Message               string                 `json:"message"`
+}
+
+// MetabolicPerpetrator is an inhibitor or inducer acting on one of the substrate's enzymes
+type MetabolicPerpetrator struct {
+	Supplement SupplementInfo `json:"supplement"`
+	Enzyme     string         `json:"enzyme"`             // e.g. "CYP1A2"
+	Effect     string         `json:"effect"`             // "inhibitor" or "inducer"
+	Strength   *string        `json:"strength,omitempty"` // "strong", "moderate" or "weak"
+	Confidence float64        `json:"confidence"`
 }
 
 // TrafficLightStatus represents the overall safety status
@@ -138,6 +529,8 @@ type InteractionWarning struct {
 	Mechanism *string         `json:"mechanism,omitempty"`
 	Source    SupplementInfo  `json:"source"`
 	Target    SupplementInfo  `json:"target"`
+	// Competition only: estimated % of the target's absorbed dose lost when taken together
+	AbsorptionLossPercent *float64 `json:"absorptionLossPercent,omitempty"`
 }
 
 // TimingWarning represents a timing-related warning
@@ -155,15 +548,35 @@ type TimingWarning struct {
 
 // RatioWarning represents a ratio imbalance warning
 type RatioWarning struct {
-	ID             string         `json:"id"`
-	Severity       Severity       `json:"severity"`
-	CurrentRatio   float32        `json:"currentRatio"`
-	OptimalRatio   *float32       `json:"optimalRatio,omitempty"`
-	MinRatio       *float32       `json:"minRatio,omitempty"`
-	MaxRatio       *float32       `json:"maxRatio,omitempty"`
-	WarningMessage string         `json:"warningMessage"`
-	Source         SupplementInfo `json:"source"`
-	Target         SupplementInfo `json:"target"`
+	ID string `json:"id"`
+	// The rule's severity, raised by a level when the ratio is 1.5 times beyond the
+	// violated bound and by two when it is twice beyond it
+	Severity     Severity `json:"severity"`
+	CurrentRatio float64  `json:"currentRatio"`
+	OptimalRatio *float64 `json:"optimalRatio,omitempty"`
+	MinRatio     *float64 `json:"minRatio,omitempty"`
+	MaxRatio     *float64 `json:"maxRatio,omitempty"`
+	// Fraction beyond minRatio and maxRatio tolerated before warning
+	Tolerance float64 `json:"tolerance"`
+	// Current ratio minus the violated bound: negative below minRatio, positive above maxRatio
+	Deviation float64 `json:"deviation"`
+	// Current ratio minus the optimal ratio, when the rule has one
+	DistanceToOptimal *float64       `json:"distanceToOptimal,omitempty"`
+	WarningMessage    string         `json:"warningMessage"`
+	Source            SupplementInfo `json:"source"`
+	Target            SupplementInfo `json:"target"`
+}
+
+// MealAdvisory represents a suboptimal meal context for a supplement
+type MealAdvisory struct {
+	Supplement        SupplementInfo `json:"supplement"`
+	MealContext       MealContext    `json:"mealContext"`
+	OptimalContexts   []MealContext  `json:"optimalContexts"`
+	AbsorptionPercent float64        `json:"absorptionPercent"` // Estimated absorption relative to the optimal context
+	Message           string         `json:"message"`
+	Recommendation    string         `json:"recommendation"`
+	Mechanism         string         `json:"mechanism"`
+	ResearchURL       *string        `json:"researchUrl,omitempty"`
 }
 
 type RatioGapReason string
@@ -0,0 +1,68 @@
+// Package pharmacodynamics maps plasma concentrations from the kinetics package to
+// effect intensity.
+package pharmacodynamics
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrInvalidModel is returned for Emax models without positive parameters.
+var ErrInvalidModel = errors.New("pharmacodynamics: Emax, EC50 and Hill coefficient must be positive")
+
+// EmaxModel is the sigmoid Emax (Hill) concentration-effect model:
+//
+//	E(C) = Emax * C^n / (EC50^n + C^n)
+//
+// EC50 is the concentration (mg/L) producing half the maximal effect, and the Hill
+// coefficient n sets how steeply the effect rises around it.
+type EmaxModel struct {
+	Emax            float64 // Maximal effect, in the supplement's effect units
+	EC50            float64 // mg/L
+	HillCoefficient float64 // 1 for a hyperbolic curve when zero
+}
+
+// Validate reports whether the model can be evaluated.
+func (m EmaxModel) Validate() error {
+	if m.Emax <= 0 || m.EC50 <= 0 || m.HillCoefficient < 0 {
+		return ErrInvalidModel
+	}
+	return nil
+}
+
+// Effect returns the effect intensity at concentration c (mg/L).
+func (m EmaxModel) Effect(c float64) float64 {
+	if c <= 0 {
+		return 0
+	}
+	n := m.hill()
+	// Written as Emax / (1 + (EC50/C)^n) to avoid overflow of C^n
+	return m.Emax / (1 + math.Pow(m.EC50/c, n))
+}
+
+// PercentOfMax returns the effect at c as a percentage of Emax.
+func (m EmaxModel) PercentOfMax(c float64) float64 {
+	return 100 * m.Effect(c) / m.Emax
+}
+
+// ConcentrationFor returns the concentration (mg/L) producing the given effect:
+//
+//	C = EC50 * (E / (Emax - E))^(1/n)
+//
+// Effects at or above Emax are unreachable and return +Inf.
+func (m EmaxModel) ConcentrationFor(effect float64) float64 {
+	if effect <= 0 {
+		return 0
+	}
+	if effect >= m.Emax {
+		return math.Inf(1)
+	}
+	return m.EC50 * math.Pow(effect/(m.Emax-effect), 1/m.hill())
+}
+
+func (m EmaxModel) hill() float64 {
+	if m.HillCoefficient <= 0 {
+		return 1
+	}
+	return m.HillCoefficient
+}
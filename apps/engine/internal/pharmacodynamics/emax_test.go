@@ -0,0 +1,79 @@
+package pharmacodynamics
+
+import (
+	"math"
+	"testing"
+)
+
+func approxEqual(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func TestEmaxModel_HalfEffectAtEC50(t *testing.T) {
+	tests := []struct {
+		name  string
+		model EmaxModel
+	}{
+		{"hyperbolic", EmaxModel{Emax: 100, EC50: 4, HillCoefficient: 1}},
+		{"steep", EmaxModel{Emax: 100, EC50: 4, HillCoefficient: 3}},
+		{"default_hill", EmaxModel{Emax: 10, EC50: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.model.Effect(tt.model.EC50); !approxEqual(got, tt.model.Emax/2, 1e-9) {
+				t.Errorf("Effect(EC50) = %v, want %v", got, tt.model.Emax/2)
+			}
+			if got := tt.model.PercentOfMax(tt.model.EC50); !approxEqual(got, 50, 1e-9) {
+				t.Errorf("PercentOfMax(EC50) = %v, want 50", got)
+			}
+		})
+	}
+}
+
+func TestEmaxModel_HillSteepness(t *testing.T) {
+	shallow := EmaxModel{Emax: 100, EC50: 4, HillCoefficient: 1}
+	steep := EmaxModel{Emax: 100, EC50: 4, HillCoefficient: 4}
+
+	// Below EC50 a steeper curve gives less effect, above it more
+	if steep.Effect(2) >= shallow.Effect(2) {
+		t.Errorf("Steep curve should be lower below EC50: %v vs %v", steep.Effect(2), shallow.Effect(2))
+	}
+	if steep.Effect(8) <= shallow.Effect(8) {
+		t.Errorf("Steep curve should be higher above EC50: %v vs %v", steep.Effect(8), shallow.Effect(8))
+	}
+}
+
+func TestEmaxModel_Bounds(t *testing.T) {
+	model := EmaxModel{Emax: 100, EC50: 4, HillCoefficient: 2}
+
+	if got := model.Effect(0); got != 0 {
+		t.Errorf("Effect(0) = %v, want 0", got)
+	}
+	if got := model.Effect(1e6); !approxEqual(got, 100, 1e-6) {
+		t.Errorf("Effect at very high concentration = %v, want Emax", got)
+	}
+}
+
+func TestEmaxModel_ConcentrationForInvertsEffect(t *testing.T) {
+	model := EmaxModel{Emax: 80, EC50: 4.5, HillCoefficient: 1.7}
+
+	for _, effect := range []float64{8, 40, 72} {
+		c := model.ConcentrationFor(effect)
+		if got := model.Effect(c); !approxEqual(got, effect, 1e-9) {
+			t.Errorf("Effect(ConcentrationFor(%v)) = %v", effect, got)
+		}
+	}
+	if !math.IsInf(model.ConcentrationFor(80), 1) {
+		t.Errorf("Emax should be unreachable")
+	}
+}
+
+func TestEmaxModel_Validate(t *testing.T) {
+	if err := (EmaxModel{Emax: 100, EC50: 4}).Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := (EmaxModel{Emax: 100}).Validate(); err != ErrInvalidModel {
+		t.Errorf("missing EC50: err = %v, want %v", err, ErrInvalidModel)
+	}
+}
@@ -0,0 +1,233 @@
+package pkfit
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrSingular is returned when the normal equations cannot be solved, typically because
+// a parameter has no influence on the predictions.
+var ErrSingular = errors.New("pkfit: singular matrix; parameters are not identifiable from the data")
+
+// Levenberg-Marquardt settings
+const (
+	initialDamping  = 1e-3
+	maxDamping      = 1e10
+	costTolerance   = 1e-12
+	stepTolerance   = 1e-10
+	jacobianRelStep = 1e-6
+	defaultMaxIters = 200
+	dampingIncrease = 10
+	dampingDecrease = 10
+	minimumDiagonal = 1e-8
+)
+
+// residualFunc writes the residuals (observed - predicted) for parameters p into r.
+type residualFunc func(p []float64, r []float64)
+
+// lmResult is the minimum found by levenbergMarquardt.
+type lmResult struct {
+	Params     []float64
+	Residuals  []float64
+	Jacobian   [][]float64 // ∂r_i/∂p_j at Params
+	Iterations int
+}
+
+// levenbergMarquardt minimises Σ r_i² over the parameters, starting from p0.
+//
+// Each iteration solves the damped normal equations with Marquardt's diagonal scaling:
+//
+//	(JᵀJ + λ diag(JᵀJ)) δ = -Jᵀr
+//
+// λ shrinks after a step that lowers the cost and grows after one that does not.
+// The Jacobian is approximated with central differences.
+func levenbergMarquardt(f residualFunc, p0 []float64, m int, maxIterations int) lmResult {
+	if maxIterations <= 0 {
+		maxIterations = defaultMaxIters
+	}
+
+	n := len(p0)
+	p := append([]float64(nil), p0...)
+	r := make([]float64, m)
+	f(p, r)
+	cost := sumSquares(r)
+
+	trial := make([]float64, n)
+	trialResiduals := make([]float64, m)
+	damping := initialDamping
+
+	iterations := 0
+	for ; iterations < maxIterations; iterations++ {
+		jacobian := numericalJacobian(f, p, m)
+		jtj, jtr := normalEquations(jacobian, r)
+
+		improved := false
+		for damping <= maxDamping {
+			a := make([][]float64, n)
+			b := make([]float64, n)
+			for i := 0; i < n; i++ {
+				a[i] = append([]float64(nil), jtj[i]...)
+				a[i][i] += damping * math.Max(jtj[i][i], minimumDiagonal)
+				b[i] = -jtr[i]
+			}
+
+			step, err := solveLinear(a, b)
+			if err != nil {
+				damping *= dampingIncrease
+				continue
+			}
+
+			for i := range p {
+				trial[i] = p[i] + step[i]
+			}
+			f(trial, trialResiduals)
+			trialCost := sumSquares(trialResiduals)
+
+			if trialCost < cost && !math.IsNaN(trialCost) {
+				converged := cost-trialCost <= costTolerance*cost || norm(step) <= stepTolerance*(norm(p)+stepTolerance)
+				copy(p, trial)
+				copy(r, trialResiduals)
+				cost = trialCost
+				damping /= dampingDecrease
+				improved = true
+				if converged {
+					return lmResult{Params: p, Residuals: r, Jacobian: numericalJacobian(f, p, m), Iterations: iterations + 1}
+				}
+				break
+			}
+			damping *= dampingIncrease
+		}
+
+		// No step lowers the cost: p is a local minimum
+		if !improved {
+			break
+		}
+	}
+
+	return lmResult{Params: p, Residuals: r, Jacobian: numericalJacobian(f, p, m), Iterations: iterations}
+}
+
+// numericalJacobian returns ∂r_i/∂p_j by central differences.
+func numericalJacobian(f residualFunc, p []float64, m int) [][]float64 {
+	n := len(p)
+	jacobian := make([][]float64, m)
+	for i := range jacobian {
+		jacobian[i] = make([]float64, n)
+	}
+
+	shifted := append([]float64(nil), p...)
+	forward := make([]float64, m)
+	backward := make([]float64, m)
+	for j := 0; j < n; j++ {
+		h := jacobianRelStep * math.Max(1, math.Abs(p[j]))
+		shifted[j] = p[j] + h
+		f(shifted, forward)
+		shifted[j] = p[j] - h
+		f(shifted, backward)
+		shifted[j] = p[j]
+
+		for i := 0; i < m; i++ {
+			jacobian[i][j] = (forward[i] - backward[i]) / (2 * h)
+		}
+	}
+	return jacobian
+}
+
+// normalEquations returns JᵀJ and Jᵀr.
+func normalEquations(jacobian [][]float64, r []float64) ([][]float64, []float64) {
+	n := 0
+	if len(jacobian) > 0 {
+		n = len(jacobian[0])
+	}
+	jtj := make([][]float64, n)
+	for i := range jtj {
+		jtj[i] = make([]float64, n)
+	}
+	jtr := make([]float64, n)
+
+	for k, row := range jacobian {
+		for i := 0; i < n; i++ {
+			jtr[i] += row[i] * r[k]
+			for j := 0; j < n; j++ {
+				jtj[i][j] += row[i] * row[j]
+			}
+		}
+	}
+	return jtj, jtr
+}
+
+// solveLinear solves a·x = b by Gaussian elimination with partial pivoting.
+// a and b are overwritten.
+func solveLinear(a [][]float64, b []float64) ([]float64, error) {
+	n := len(b)
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-300 {
+			return nil, ErrSingular
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		b[col], b[pivot] = b[pivot], b[col]
+
+		for row := col + 1; row < n; row++ {
+			factor := a[row][col] / a[col][col]
+			for k := col; k < n; k++ {
+				a[row][k] -= factor * a[col][k]
+			}
+			b[row] -= factor * b[col]
+		}
+	}
+
+	x := make([]float64, n)
+	for row := n - 1; row >= 0; row-- {
+		sum := b[row]
+		for k := row + 1; k < n; k++ {
+			sum -= a[row][k] * x[k]
+		}
+		x[row] = sum / a[row][row]
+	}
+	return x, nil
+}
+
+// invert returns the inverse of the square matrix a.
+func invert(a [][]float64) ([][]float64, error) {
+	n := len(a)
+	inverse := make([][]float64, n)
+	for i := range inverse {
+		inverse[i] = make([]float64, n)
+	}
+
+	for col := 0; col < n; col++ {
+		work := make([][]float64, n)
+		for i := range a {
+			work[i] = append([]float64(nil), a[i]...)
+		}
+		unit := make([]float64, n)
+		unit[col] = 1
+
+		x, err := solveLinear(work, unit)
+		if err != nil {
+			return nil, err
+		}
+		for row := 0; row < n; row++ {
+			inverse[row][col] = x[row]
+		}
+	}
+	return inverse, nil
+}
+
+func sumSquares(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v * v
+	}
+	return sum
+}
+
+func norm(values []float64) float64 {
+	return math.Sqrt(sumSquares(values))
+}
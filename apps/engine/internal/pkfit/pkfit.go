@@ -0,0 +1,274 @@
+// Package pkfit estimates SupplementPK parameters from published concentration-time data
+// by nonlinear least squares.
+package pkfit
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/kinetics"
+)
+
+const (
+	// DefaultConfidence is the level of the reported confidence intervals.
+	DefaultConfidence = 0.95
+	// Fallback t½ when the elimination phase has too few positive points to estimate it
+	fallbackHalfLifeMinutes = kinetics.DefaultHalfLifeMinutes
+)
+
+var (
+	ErrTooFewObservations  = errors.New("pkfit: need more observations than fitted parameters")
+	ErrDoseRequired        = errors.New("pkfit: dose is required for Michaelis-Menten fits")
+	ErrUnsupportedKinetics = errors.New("pkfit: unsupported kinetics type")
+	ErrInvalidObservation  = errors.New("pkfit: observations need non-negative times and concentrations")
+	ErrInvalidConfidence   = errors.New("pkfit: confidence must be between 0 and 1")
+)
+
+// Observation is a measured concentration after a single dose at t = 0.
+// Concentrations may be in any unit; Cmax is fitted in the same unit.
+type Observation struct {
+	Minutes       float64
+	Concentration float64
+}
+
+// Options configures Fit. Zero values use the defaults.
+type Options struct {
+	KineticsType  kinetics.KineticsType // FirstOrder (default), OneCompartment or MichaelisMenten
+	Dose          float64               // Administered dose (mg); required for MichaelisMenten
+	Confidence    float64               // Confidence interval level (default 0.95)
+	MaxIterations int                   // Levenberg-Marquardt iteration limit (default 200)
+}
+
+// Parameter is a fitted value with its standard error and confidence interval.
+type Parameter struct {
+	Name     string // SupplementPK field name, or "Cmax"
+	Value    float64
+	StdError float64 // Delta-method standard error
+	Lower    float64
+	Upper    float64
+}
+
+// Result is a fitted model.
+type Result struct {
+	PK               kinetics.SupplementPK
+	Cmax             float64     // Peak concentration, in the observations' unit
+	Parameters       []Parameter // Cmax, PeakMinutes, HalfLifeMinutes[, Vmax, Km]
+	Predicted        []float64   // Model concentration at each observation
+	Residuals        []float64   // Observed - predicted
+	RMSE             float64
+	RSquared         float64
+	DegreesOfFreedom int
+	Iterations       int
+}
+
+// Fit estimates the PK parameters of the kinetics model from single-dose observations.
+//
+// The model concentration is Cmax * CalculateConcentration / 100, so the fitted parameters
+// reproduce the engine's curve exactly. Parameters are fitted on a log scale, which keeps
+// them positive; confidence intervals come from the asymptotic covariance
+// s² (JᵀJ)⁻¹ on that scale with Student's t quantiles, so they are asymmetric once
+// transformed back.
+func Fit(observations []Observation, opts Options) (Result, error) {
+	kineticsType := opts.KineticsType
+	if kineticsType == "" {
+		kineticsType = kinetics.FirstOrder
+	}
+
+	names := []string{"Cmax", "PeakMinutes", "HalfLifeMinutes"}
+	switch kineticsType {
+	case kinetics.FirstOrder, kinetics.OneCompartment:
+	case kinetics.MichaelisMenten:
+		if opts.Dose <= 0 {
+			return Result{}, ErrDoseRequired
+		}
+		names = append(names, "Vmax", "Km")
+	default:
+		return Result{}, ErrUnsupportedKinetics
+	}
+
+	confidence := opts.Confidence
+	if confidence == 0 {
+		confidence = DefaultConfidence
+	}
+	if confidence <= 0 || confidence >= 1 {
+		return Result{}, ErrInvalidConfidence
+	}
+
+	for _, o := range observations {
+		if o.Minutes < 0 || o.Concentration < 0 || math.IsNaN(o.Minutes) || math.IsNaN(o.Concentration) {
+			return Result{}, ErrInvalidObservation
+		}
+	}
+	m, n := len(observations), len(names)
+	if m <= n {
+		return Result{}, ErrTooFewObservations
+	}
+
+	model := func(logParams []float64, t float64) float64 {
+		pk := toPK(kineticsType, logParams)
+		return math.Exp(logParams[0]) * kinetics.CalculateConcentration(kinetics.ConcentrationParams{
+			Dose:                  opts.Dose,
+			MinutesSinceIngestion: t,
+			PK:                    pk,
+		}) / 100
+	}
+	residuals := func(logParams []float64, r []float64) {
+		for i, o := range observations {
+			r[i] = o.Concentration - model(logParams, o.Minutes)
+		}
+	}
+
+	// The piecewise curves have local minima in Tmax, so start from every sampled time
+	// and keep the best fit
+	var fit lmResult
+	bestCost := math.Inf(1)
+	for _, initial := range initialEstimates(observations, kineticsType, opts.Dose) {
+		logInitial := make([]float64, n)
+		for i, v := range initial {
+			logInitial[i] = math.Log(v)
+		}
+
+		candidate := levenbergMarquardt(residuals, logInitial, m, opts.MaxIterations)
+		if cost := sumSquares(candidate.Residuals); cost < bestCost {
+			fit, bestCost = candidate, cost
+		}
+	}
+
+	result := Result{
+		PK:               toPK(kineticsType, fit.Params),
+		Cmax:             math.Exp(fit.Params[0]),
+		Residuals:        fit.Residuals,
+		Predicted:        make([]float64, m),
+		DegreesOfFreedom: m - n,
+		Iterations:       fit.Iterations,
+	}
+	for i, o := range observations {
+		result.Predicted[i] = o.Concentration - fit.Residuals[i]
+	}
+
+	rss := sumSquares(fit.Residuals)
+	result.RMSE = math.Sqrt(rss / float64(m))
+	result.RSquared = rSquared(observations, rss)
+
+	jtj, _ := normalEquations(fit.Jacobian, fit.Residuals)
+	covariance, err := invert(jtj)
+	if err != nil {
+		return Result{}, err
+	}
+	variance := rss / float64(result.DegreesOfFreedom)
+	t := studentTQuantile(1-(1-confidence)/2, float64(result.DegreesOfFreedom))
+
+	for i, name := range names {
+		logSE := math.Sqrt(math.Max(variance*covariance[i][i], 0))
+		value := math.Exp(fit.Params[i])
+		result.Parameters = append(result.Parameters, Parameter{
+			Name:     name,
+			Value:    value,
+			StdError: value * logSE,
+			Lower:    math.Exp(fit.Params[i] - t*logSE),
+			Upper:    math.Exp(fit.Params[i] + t*logSE),
+		})
+	}
+
+	return result, nil
+}
+
+// toPK builds the model parameters from log-scale fit parameters.
+func toPK(kineticsType kinetics.KineticsType, logParams []float64) kinetics.SupplementPK {
+	pk := kinetics.SupplementPK{
+		KineticsType:    kineticsType,
+		PeakMinutes:     math.Exp(logParams[1]),
+		HalfLifeMinutes: math.Exp(logParams[2]),
+	}
+	if kineticsType == kinetics.MichaelisMenten {
+		pk.Vmax = math.Exp(logParams[3])
+		pk.Km = math.Exp(logParams[4])
+	}
+	return pk
+}
+
+// initialEstimates returns starting values in the order of the fitted parameters, one
+// set per candidate Tmax (each positive sample time). Cmax starts at the highest
+// observation and t½ at a log-linear regression over the points after it. For
+// Michaelis-Menten, Km starts at the dose and Vmax at the rate that would absorb it by Tmax.
+func initialEstimates(observations []Observation, kineticsType kinetics.KineticsType, dose float64) [][]float64 {
+	sorted := append([]Observation(nil), observations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Minutes < sorted[j].Minutes })
+
+	peak := 0
+	for i, o := range sorted {
+		if o.Concentration > sorted[peak].Concentration {
+			peak = i
+		}
+	}
+	cmax := math.Max(sorted[peak].Concentration, 1e-6)
+
+	halfLife := float64(fallbackHalfLifeMinutes)
+	if slope, ok := logLinearSlope(sorted[peak:]); ok && slope < 0 {
+		halfLife = math.Ln2 / -slope
+	}
+
+	var estimates [][]float64
+	for i, o := range sorted {
+		if o.Minutes <= 0 || (i > 0 && o.Minutes == sorted[i-1].Minutes) {
+			continue
+		}
+		tmax := o.Minutes
+		estimate := []float64{cmax, tmax, halfLife}
+		if kineticsType == kinetics.MichaelisMenten {
+			estimate = append(estimate, dose/tmax, dose)
+		}
+		estimates = append(estimates, estimate)
+	}
+	if len(estimates) == 0 {
+		estimate := []float64{cmax, kinetics.DefaultPeakMinutes, halfLife}
+		if kineticsType == kinetics.MichaelisMenten {
+			estimate = append(estimate, dose/kinetics.DefaultPeakMinutes, dose)
+		}
+		estimates = append(estimates, estimate)
+	}
+	return estimates
+}
+
+// logLinearSlope returns the least-squares slope of ln(C) against time over the
+// positive concentrations.
+func logLinearSlope(observations []Observation) (float64, bool) {
+	var sumT, sumY, sumTT, sumTY, count float64
+	for _, o := range observations {
+		if o.Concentration <= 0 {
+			continue
+		}
+		y := math.Log(o.Concentration)
+		sumT += o.Minutes
+		sumY += y
+		sumTT += o.Minutes * o.Minutes
+		sumTY += o.Minutes * y
+		count++
+	}
+	if count < 2 {
+		return 0, false
+	}
+	denominator := count*sumTT - sumT*sumT
+	if denominator == 0 {
+		return 0, false
+	}
+	return (count*sumTY - sumT*sumY) / denominator, true
+}
+
+func rSquared(observations []Observation, rss float64) float64 {
+	mean := 0.0
+	for _, o := range observations {
+		mean += o.Concentration
+	}
+	mean /= float64(len(observations))
+
+	tss := 0.0
+	for _, o := range observations {
+		tss += (o.Concentration - mean) * (o.Concentration - mean)
+	}
+	if tss == 0 {
+		return 0
+	}
+	return 1 - rss/tss
+}
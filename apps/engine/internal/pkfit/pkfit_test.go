@@ -0,0 +1,171 @@
+package pkfit
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/nikitalbnv/stochi/apps/engine/internal/kinetics"
+)
+
+// synthesize samples Cmax * CalculateConcentration / 100 with multiplicative noise.
+func synthesize(pk kinetics.SupplementPK, dose float64, cmax float64, times []float64, noise float64) []Observation {
+	rng := rand.New(rand.NewPCG(7, 0))
+	observations := make([]Observation, len(times))
+	for i, t := range times {
+		c := cmax * kinetics.CalculateConcentration(kinetics.ConcentrationParams{Dose: dose, MinutesSinceIngestion: t, PK: pk}) / 100
+		observations[i] = Observation{Minutes: t, Concentration: c * (1 + noise*rng.NormFloat64())}
+	}
+	return observations
+}
+
+func parameter(t *testing.T, result Result, name string) Parameter {
+	t.Helper()
+	for _, p := range result.Parameters {
+		if p.Name == name {
+			return p
+		}
+	}
+	t.Fatalf("parameter %s not reported", name)
+	return Parameter{}
+}
+
+var sampleTimes = []float64{15, 30, 45, 60, 90, 120, 180, 240, 360, 480, 600, 720}
+
+func TestFit_OneCompartmentRecoversParameters(t *testing.T) {
+	truth := kinetics.SupplementPK{KineticsType: kinetics.OneCompartment, PeakMinutes: 75, HalfLifeMinutes: 300}
+	observations := synthesize(truth, 0, 4.2, sampleTimes, 0.02)
+
+	result, err := Fit(observations, Options{KineticsType: kinetics.OneCompartment})
+	if err != nil {
+		t.Fatalf("Fit() error: %v", err)
+	}
+
+	for _, want := range []struct {
+		name  string
+		value float64
+	}{{"Cmax", 4.2}, {"PeakMinutes", 75}, {"HalfLifeMinutes", 300}} {
+		p := parameter(t, result, want.name)
+		if math.Abs(p.Value-want.value) > 0.1*want.value {
+			t.Errorf("%s = %v, want within 10%% of %v", want.name, p.Value, want.value)
+		}
+		if p.Lower > want.value || p.Upper < want.value {
+			t.Errorf("%s interval [%v, %v] should contain %v", want.name, p.Lower, p.Upper, want.value)
+		}
+		if p.Lower >= p.Value || p.Upper <= p.Value || p.StdError <= 0 {
+			t.Errorf("%s interval [%v, %v] should bracket %v with a positive standard error", want.name, p.Lower, p.Upper, p.Value)
+		}
+	}
+
+	if result.PK.PeakMinutes != parameter(t, result, "PeakMinutes").Value {
+		t.Errorf("PK should carry the fitted Tmax")
+	}
+	if len(result.Residuals) != len(observations) || result.DegreesOfFreedom != len(observations)-3 {
+		t.Errorf("Expected %d residuals and %d degrees of freedom, got %d and %d",
+			len(observations), len(observations)-3, len(result.Residuals), result.DegreesOfFreedom)
+	}
+	if result.RSquared < 0.99 {
+		t.Errorf("Expected R² > 0.99, got %v", result.RSquared)
+	}
+}
+
+func TestFit_FirstOrderExactData(t *testing.T) {
+	truth := kinetics.SupplementPK{KineticsType: kinetics.FirstOrder, PeakMinutes: 90, HalfLifeMinutes: 240}
+	observations := synthesize(truth, 0, 100, sampleTimes, 0)
+
+	result, err := Fit(observations, Options{})
+	if err != nil {
+		t.Fatalf("Fit() error: %v", err)
+	}
+	if math.Abs(result.PK.PeakMinutes-90) > 1 || math.Abs(result.PK.HalfLifeMinutes-240) > 1 {
+		t.Errorf("Expected Tmax 90 and t½ 240, got %v and %v", result.PK.PeakMinutes, result.PK.HalfLifeMinutes)
+	}
+	if result.RMSE > 0.01 {
+		t.Errorf("Expected a near-exact fit, got RMSE %v", result.RMSE)
+	}
+}
+
+func TestFit_MichaelisMenten(t *testing.T) {
+	truth := kinetics.SupplementPK{KineticsType: kinetics.MichaelisMenten, PeakMinutes: 180, HalfLifeMinutes: 600, Vmax: 2, Km: 200}
+	times := []float64{10, 20, 30, 45, 60, 90, 120, 150, 180, 240, 360, 480, 720}
+	observations := synthesize(truth, 500, 12, times, 0)
+
+	result, err := Fit(observations, Options{KineticsType: kinetics.MichaelisMenten, Dose: 500})
+	if err != nil {
+		t.Fatalf("Fit() error: %v", err)
+	}
+	if len(result.Parameters) != 5 {
+		t.Fatalf("Expected 5 parameters, got %d", len(result.Parameters))
+	}
+	if math.Abs(result.PK.Vmax-2) > 0.02 || math.Abs(result.PK.Km-200) > 2 {
+		t.Errorf("Expected Vmax 2 and Km 200 within 1%%, got %v and %v", result.PK.Vmax, result.PK.Km)
+	}
+	if math.Abs(result.PK.HalfLifeMinutes-600) > 6 {
+		t.Errorf("Expected t½ near 600, got %v", result.PK.HalfLifeMinutes)
+	}
+	if result.RSquared < 0.999 {
+		t.Errorf("Expected R² > 0.999, got %v", result.RSquared)
+	}
+
+	// A single dose barely separates Vmax from Km: with 2% noise the curve still fits
+	// but both intervals span over an order of magnitude
+	noisy, err := Fit(synthesize(truth, 500, 12, times, 0.02), Options{KineticsType: kinetics.MichaelisMenten, Dose: 500})
+	if err != nil {
+		t.Fatalf("Fit() error: %v", err)
+	}
+	for _, want := range []struct {
+		name  string
+		value float64
+	}{{"Vmax", 2}, {"Km", 200}} {
+		p := parameter(t, noisy, want.name)
+		if p.Lower > want.value || p.Upper < want.value {
+			t.Errorf("%s interval [%v, %v] should contain %v", want.name, p.Lower, p.Upper, want.value)
+		}
+		if p.Upper/p.Lower < 10 {
+			t.Errorf("%s interval [%v, %v] should reflect weak identifiability", want.name, p.Lower, p.Upper)
+		}
+	}
+	if hl := parameter(t, noisy, "HalfLifeMinutes"); hl.Lower > 600 || hl.Upper < 600 || hl.Upper/hl.Lower > 2 {
+		t.Errorf("t½ interval [%v, %v] should contain 600 and stay informative", hl.Lower, hl.Upper)
+	}
+}
+
+func TestFit_Errors(t *testing.T) {
+	few := []Observation{{30, 1}, {60, 2}, {120, 1}}
+	if _, err := Fit(few, Options{}); !errors.Is(err, ErrTooFewObservations) {
+		t.Errorf("Expected ErrTooFewObservations, got %v", err)
+	}
+
+	observations := synthesize(kinetics.SupplementPK{PeakMinutes: 60, HalfLifeMinutes: 240}, 0, 1, sampleTimes, 0)
+	if _, err := Fit(observations, Options{KineticsType: kinetics.MichaelisMenten}); !errors.Is(err, ErrDoseRequired) {
+		t.Errorf("Expected ErrDoseRequired, got %v", err)
+	}
+	if _, err := Fit(observations, Options{KineticsType: "zero_order"}); !errors.Is(err, ErrUnsupportedKinetics) {
+		t.Errorf("Expected ErrUnsupportedKinetics, got %v", err)
+	}
+	if _, err := Fit(observations, Options{Confidence: 95}); !errors.Is(err, ErrInvalidConfidence) {
+		t.Errorf("Expected ErrInvalidConfidence, got %v", err)
+	}
+	if _, err := Fit(append(observations, Observation{-5, 1}), Options{}); !errors.Is(err, ErrInvalidObservation) {
+		t.Errorf("Expected ErrInvalidObservation, got %v", err)
+	}
+}
+
+func TestStudentTQuantile(t *testing.T) {
+	tests := []struct {
+		p, df, want float64
+	}{
+		{0.975, 1, 12.7062},
+		{0.975, 5, 2.5706},
+		{0.975, 30, 2.0423},
+		{0.95, 10, 1.8125},
+		{0.025, 5, -2.5706},
+	}
+
+	for _, tt := range tests {
+		if got := studentTQuantile(tt.p, tt.df); math.Abs(got-tt.want) > 1e-3 {
+			t.Errorf("studentTQuantile(%v, %v) = %v, want %v", tt.p, tt.df, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,5 @@
+-- One-compartment (Bateman) oral absorption model
+ALTER TYPE "public"."kinetics_type" ADD VALUE 'one_compartment';--> statement-breakpoint
+
+-- Absorption rate constant ka (1/min); derived from peak_minutes and half_life_minutes when NULL
+ALTER TABLE "supplement" ADD COLUMN "absorption_rate_constant" real;
@@ -0,0 +1,5 @@
+-- Numerical compartment model solved by the engine's RK45 integrator; overrides kinetics_type when set
+ALTER TABLE "supplement" ADD COLUMN "compartment_model" text;--> statement-breakpoint
+
+-- Optional model rate constants: {"k12", "k21", "biliaryFraction", "bileReleaseRate"}
+ALTER TABLE "supplement" ADD COLUMN "compartment_params" jsonb;
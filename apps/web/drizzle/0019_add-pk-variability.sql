@@ -0,0 +1,7 @@
+-- Between-subject coefficients of variation (SD / median) for Monte Carlo uncertainty bands
+-- NULL uses the engine default of 0.3
+ALTER TABLE "supplement" ADD COLUMN "half_life_cv" real;--> statement-breakpoint
+ALTER TABLE "supplement" ADD COLUMN "peak_cv" real;--> statement-breakpoint
+ALTER TABLE "supplement" ADD COLUMN "bioavailability_cv" real;--> statement-breakpoint
+ALTER TABLE "supplement" ADD COLUMN "vmax_cv" real;--> statement-breakpoint
+ALTER TABLE "supplement" ADD COLUMN "km_cv" real;
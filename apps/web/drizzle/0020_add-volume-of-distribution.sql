@@ -0,0 +1,2 @@
+-- Volume of distribution (L/kg) for absolute plasma concentrations in mg/L
+ALTER TABLE "supplement" ADD COLUMN "volume_of_distribution" real;
@@ -0,0 +1,5 @@
+-- Pharmacodynamic Emax/Hill model parameters; EC50 is in mg/L
+ALTER TABLE "supplement" ADD COLUMN "effect_label" text;--> statement-breakpoint
+ALTER TABLE "supplement" ADD COLUMN "emax" real;--> statement-breakpoint
+ALTER TABLE "supplement" ADD COLUMN "ec50" real;--> statement-breakpoint
+ALTER TABLE "supplement" ADD COLUMN "hill_coefficient" real;
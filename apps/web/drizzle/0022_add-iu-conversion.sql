@@ -0,0 +1,5 @@
+-- mcg per IU of each supplement's form, e.g. 0.025 for vitamin D2/D3, 0.3 for retinol,
+-- 0.6 for beta-carotene, 670 for natural and 450 for synthetic vitamin E. Mixed
+-- tocopherols have no IU definition and stay NULL.
+ALTER TABLE "supplement" ADD COLUMN "mcg_per_iu" real;--> statement-breakpoint
+UPDATE "supplement" SET "mcg_per_iu" = 0.025 WHERE "form" IN ('Cholecalciferol', 'Ergocalciferol');
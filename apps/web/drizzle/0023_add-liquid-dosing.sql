@@ -0,0 +1,4 @@
+-- Liquid dosing: ml and drops convert to mass through the supplement's concentration
+ALTER TYPE "public"."dosage_unit" ADD VALUE 'drops';--> statement-breakpoint
+ALTER TABLE "supplement" ADD COLUMN "concentration_mg_per_ml" real;--> statement-breakpoint
+ALTER TABLE "supplement" ADD COLUMN "drops_per_ml" real;
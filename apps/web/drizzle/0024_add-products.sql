@@ -0,0 +1,25 @@
+-- Products: serving units (capsule, tablet, scoop, serving) resolve to a mass through the product's ingredients
+CREATE TYPE "public"."serving_unit" AS ENUM('capsule', 'tablet', 'scoop', 'serving');--> statement-breakpoint
+CREATE TABLE "product" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"user_id" text,
+	"name" text NOT NULL,
+	"brand" text,
+	"serving_unit" "serving_unit" NOT NULL,
+	"created_at" timestamp NOT NULL
+);
+--> statement-breakpoint
+CREATE TABLE "product_ingredient" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"product_id" uuid NOT NULL,
+	"supplement_id" uuid NOT NULL,
+	"amount" real NOT NULL,
+	"unit" "dosage_unit" NOT NULL
+);
+--> statement-breakpoint
+ALTER TABLE "product" ADD CONSTRAINT "product_user_id_user_id_fk" FOREIGN KEY ("user_id") REFERENCES "public"."user"("id") ON DELETE cascade ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "product_ingredient" ADD CONSTRAINT "product_ingredient_product_id_product_id_fk" FOREIGN KEY ("product_id") REFERENCES "public"."product"("id") ON DELETE cascade ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "product_ingredient" ADD CONSTRAINT "product_ingredient_supplement_id_supplement_id_fk" FOREIGN KEY ("supplement_id") REFERENCES "public"."supplement"("id") ON DELETE cascade ON UPDATE no action;--> statement-breakpoint
+CREATE INDEX "product_user_idx" ON "product" USING btree ("user_id");--> statement-breakpoint
+CREATE INDEX "product_ingredient_product_idx" ON "product_ingredient" USING btree ("product_id");--> statement-breakpoint
+CREATE INDEX "product_ingredient_supplement_idx" ON "product_ingredient" USING btree ("supplement_id");
@@ -0,0 +1,23 @@
+-- Molecular formulas let `elemental -audit` verify elemental_weight against atomic weights
+ALTER TABLE "supplement" ADD COLUMN "molecular_formula" text;--> statement-breakpoint
+ALTER TABLE "supplement" ADD COLUMN "elemental_symbol" text;--> statement-breakpoint
+UPDATE "supplement" SET "molecular_formula" = v.formula, "elemental_symbol" = v.symbol
+FROM (VALUES
+  ('Magnesium Glycinate', 'Mg(C2H4NO2)2', 'Mg'),
+  ('Magnesium Citrate', 'Mg3(C6H5O7)2', 'Mg'),
+  ('Magnesium L-Threonate', 'Mg(C4H7O5)2', 'Mg'),
+  ('Magnesium Oxide', 'MgO', 'Mg'),
+  ('Magnesium Malate', 'MgC4H4O5', 'Mg'),
+  ('Zinc Picolinate', 'Zn(C6H4NO2)2', 'Zn'),
+  ('Zinc Gluconate', 'C12H22O14Zn', 'Zn'),
+  ('Zinc Carnosine', 'C9H12N4O3Zn', 'Zn'),
+  ('Iron Bisglycinate', 'Fe(C2H4NO2)2', 'Fe'),
+  ('Copper Bisglycinate', 'Cu(C2H4NO2)2', 'Cu'),
+  ('Selenium', 'C5H11NO2Se', 'Se'),
+  ('Calcium', 'Ca3(C6H5O7)2', 'Ca'),
+  ('Potassium', 'K3C6H5O7', 'K'),
+  ('Iodine', 'KI', 'I'),
+  ('Chromium', 'Cr(C6H4NO2)3', 'Cr'),
+  ('Molybdenum', 'Na2MoO4·2H2O', 'Mo')
+) AS v(name, formula, symbol)
+WHERE "supplement"."name" = v.name;
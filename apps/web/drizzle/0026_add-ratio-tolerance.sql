@@ -0,0 +1,3 @@
+-- Per-rule ratio tolerance; null keeps the 15% default
+ALTER TABLE "ratio_rule" ADD COLUMN "tolerance" real;--> statement-breakpoint
+ALTER TABLE "ratio_rule" ADD CONSTRAINT "ratio_rule_tolerance_check" CHECK ("ratio_rule"."tolerance" >= 0 AND "ratio_rule"."tolerance" < 1);
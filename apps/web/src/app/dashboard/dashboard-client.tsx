@@ -63,7 +63,7 @@ type DashboardClientProps = {
       id: string;
       supplementId: string;
       dosage: number;
-      unit: "mg" | "mcg" | "g" | "IU" | "ml";
+      unit: "mg" | "mcg" | "g" | "IU" | "ml" | "drops";
       timeSlot: "morning" | "afternoon" | "evening" | "bedtime";
       frequency: "daily" | "specific_days" | "as_needed";
       daysOfWeek: string[] | null;
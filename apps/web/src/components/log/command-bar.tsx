@@ -53,7 +53,7 @@ type Supplement = {
 export type LogOptions = {
   supplementId: string;
   dosage: number;
-  unit: "mg" | "mcg" | "g" | "IU" | "ml";
+  unit: "mg" | "mcg" | "g" | "IU" | "ml" | "drops";
   route?: RouteOfAdministration;
   mealContext?: MealContext;
   /** Optional timestamp for when the supplement was taken */
@@ -67,7 +67,7 @@ type CommandBarProps = {
   isCoachPrimed?: boolean;
 };
 
-const UNITS = ["mg", "mcg", "g", "IU", "ml"] as const;
+const UNITS = ["mg", "mcg", "g", "IU", "ml", "drops"] as const;
 const UNIT_PATTERN = new RegExp(
   `^(\\d+(?:\\.\\d+)?)\\s*(${UNITS.join("|")})$`,
   "i",
@@ -79,7 +79,8 @@ function parseDosageInput(text: string): ParsedDosage | null {
 
   const value = parseFloat(match[1]);
   const unitRaw = match[2].toLowerCase();
-  const unit = unitRaw === "iu" ? "IU" : (unitRaw as "mg" | "mcg" | "g" | "ml");
+  const unit =
+    unitRaw === "iu" ? "IU" : (unitRaw as "mg" | "mcg" | "g" | "ml" | "drops");
 
   return { value, unit };
 }
@@ -30,7 +30,7 @@ type Supplement = {
   id: string;
   name: string;
   form: string | null;
-  defaultUnit: "mg" | "mcg" | "g" | "IU" | "ml" | null;
+  defaultUnit: "mg" | "mcg" | "g" | "IU" | "ml" | "drops" | null;
 };
 
 type TimeSlot = "morning" | "afternoon" | "evening" | "bedtime";
@@ -40,7 +40,7 @@ export type SelectedSupplement = {
   name: string;
   form: string | null;
   dosage: number;
-  unit: "mg" | "mcg" | "g" | "IU" | "ml";
+  unit: "mg" | "mcg" | "g" | "IU" | "ml" | "drops";
   timeSlot: TimeSlot;
 };
 
@@ -69,7 +69,7 @@ export function BuildStackStep({
   const [showDropdown, setShowDropdown] = useState(false);
   const [pendingDosage, setPendingDosage] = useState("");
   const [pendingUnit, setPendingUnit] = useState<
-    "mg" | "mcg" | "g" | "IU" | "ml"
+    "mg" | "mcg" | "g" | "IU" | "ml" | "drops"
   >("mg");
   const [pendingTimeSlot, setPendingTimeSlot] = useState<TimeSlot>("morning");
   const [pendingSupplement, setPendingSupplement] = useState<Supplement | null>(
@@ -176,7 +176,7 @@ export function BuildStackStep({
 
   function handleAddWithPreset(
     dosage: number,
-    unit: "mg" | "mcg" | "g" | "IU" | "ml",
+    unit: "mg" | "mcg" | "g" | "IU" | "ml" | "drops",
   ) {
     if (!pendingSupplement) return;
 
@@ -203,7 +203,13 @@ export function BuildStackStep({
       name: supplement.name,
       form: supplement.form,
       dosage: supplement.recommended.dosage,
-      unit: supplement.recommended.unit as "mg" | "mcg" | "g" | "IU" | "ml",
+      unit: supplement.recommended.unit as
+        | "mg"
+        | "mcg"
+        | "g"
+        | "IU"
+        | "ml"
+        | "drops",
       timeSlot: "morning",
     };
     onChangeSupplements([...selected, newSupplement]);
@@ -427,6 +433,7 @@ export function BuildStackStep({
                     <SelectItem value="g">g</SelectItem>
                     <SelectItem value="IU">IU</SelectItem>
                     <SelectItem value="ml">ml</SelectItem>
+                    <SelectItem value="drops">drops</SelectItem>
                   </SelectContent>
                 </Select>
                 <Select
@@ -25,7 +25,7 @@ type InteractionsStepProps = {
     id: string;
     name: string;
     form: string | null;
-    defaultUnit: "mg" | "mcg" | "g" | "IU" | "ml" | null;
+    defaultUnit: "mg" | "mcg" | "g" | "IU" | "ml" | "drops" | null;
   }>;
   onAddSupplement: (supplement: SelectedSupplement) => void;
   onComplete: () => Promise<void>;
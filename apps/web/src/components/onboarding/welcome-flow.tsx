@@ -32,7 +32,7 @@ type Supplement = {
   id: string;
   name: string;
   form: string | null;
-  defaultUnit: "mg" | "mcg" | "g" | "IU" | "ml" | null;
+  defaultUnit: "mg" | "mcg" | "g" | "IU" | "ml" | "drops" | null;
 };
 
 type WelcomeFlowProps = {
@@ -324,6 +324,7 @@ export function AddProtocolItemDialog({
                       <SelectItem value="g">g</SelectItem>
                       <SelectItem value="IU">IU</SelectItem>
                       <SelectItem value="ml">ml</SelectItem>
+                      <SelectItem value="drops">drops</SelectItem>
                     </SelectContent>
                   </Select>
                 </div>
@@ -255,6 +255,7 @@ export function EditProtocolItemDialog({
                   <SelectItem value="g">g</SelectItem>
                   <SelectItem value="IU">IU</SelectItem>
                   <SelectItem value="ml">ml</SelectItem>
+                  <SelectItem value="drops">drops</SelectItem>
                 </SelectContent>
               </Select>
             </div>
@@ -39,7 +39,7 @@ type AddSupplementsDialogProps = {
   children?: React.ReactNode;
 };
 
-type DosageUnit = "mg" | "mcg" | "g" | "IU" | "ml";
+type DosageUnit = "mg" | "mcg" | "g" | "IU" | "ml" | "drops";
 
 // Track dosage/unit state for each supplement in search results
 type SupplementDosageState = {
@@ -274,6 +274,7 @@ export function AddSupplementsDialog({
                             <SelectItem value="g">g</SelectItem>
                             <SelectItem value="IU">IU</SelectItem>
                             <SelectItem value="ml">ml</SelectItem>
+                            <SelectItem value="drops">drops</SelectItem>
                           </SelectContent>
                         </Select>
                         <Button
@@ -25,7 +25,7 @@ type EditStackItemDialogProps = {
   itemId: string;
   supplementName: string;
   currentDosage: number;
-  currentUnit: "mg" | "mcg" | "g" | "IU" | "ml";
+  currentUnit: "mg" | "mcg" | "g" | "IU" | "ml" | "drops";
 };
 
 export function EditStackItemDialog({
@@ -37,7 +37,7 @@ export function EditStackItemDialog({
   const { updateItemOptimistic, isPending } = useStackItemsContext();
   const [open, setOpen] = useState(false);
   const [dosage, setDosage] = useState(currentDosage.toString());
-  const [unit, setUnit] = useState<"mg" | "mcg" | "g" | "IU" | "ml">(
+  const [unit, setUnit] = useState<"mg" | "mcg" | "g" | "IU" | "ml" | "drops">(
     currentUnit,
   );
 
@@ -107,6 +107,7 @@ export function EditStackItemDialog({
                 <SelectItem value="g">g</SelectItem>
                 <SelectItem value="IU">IU</SelectItem>
                 <SelectItem value="ml">ml</SelectItem>
+                <SelectItem value="drops">drops</SelectItem>
               </SelectContent>
             </Select>
           </div>
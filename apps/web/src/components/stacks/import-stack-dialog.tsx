@@ -117,7 +117,7 @@ export function ImportStackDialog({ children }: ImportStackDialogProps) {
 
   function handleUpdateUnit(
     index: number,
-    unit: "mg" | "mcg" | "g" | "IU" | "ml",
+    unit: "mg" | "mcg" | "g" | "IU" | "ml" | "drops",
   ) {
     setEditingItems((prev) =>
       prev.map((item, i) =>
@@ -137,7 +137,7 @@ export function ImportStackDialog({ children }: ImportStackDialogProps) {
       ...Array.from(resolvedUnmatched.values()).map((item) => ({
         supplementId: item.supplementId,
         dosage: item.dosage,
-        unit: item.unit as "mg" | "mcg" | "g" | "IU" | "ml",
+        unit: item.unit as "mg" | "mcg" | "g" | "IU" | "ml" | "drops",
       })),
     ];
 
@@ -328,7 +328,7 @@ export function ImportStackDialog({ children }: ImportStackDialogProps) {
                           onValueChange={(v) =>
                             handleUpdateUnit(
                               index,
-                              v as "mg" | "mcg" | "g" | "IU" | "ml",
+                              v as "mg" | "mcg" | "g" | "IU" | "ml" | "drops",
                             )
                           }
                         >
@@ -341,6 +341,7 @@ export function ImportStackDialog({ children }: ImportStackDialogProps) {
                             <SelectItem value="g">g</SelectItem>
                             <SelectItem value="IU">IU</SelectItem>
                             <SelectItem value="ml">ml</SelectItem>
+                            <SelectItem value="drops">drops</SelectItem>
                           </SelectContent>
                         </Select>
                         <button
@@ -250,7 +250,9 @@ export function StackDetailClient({
                     itemId={item.id}
                     supplementName={item.supplement.name}
                     currentDosage={item.dosage}
-                    currentUnit={item.unit as "mg" | "mcg" | "g" | "IU" | "ml"}
+                    currentUnit={
+                      item.unit as "mg" | "mcg" | "g" | "IU" | "ml" | "drops"
+                    }
                   />
                   <button
                     type="button"
@@ -23,7 +23,7 @@ const RELATIVE_TIME_PATTERN =
 
 export type ParsedDosage = {
   value: number;
-  unit: "mg" | "mcg" | "g" | "IU" | "ml";
+  unit: "mg" | "mcg" | "g" | "IU" | "ml" | "drops";
 };
 
 export type ParsedQuantity = {
@@ -25,7 +25,7 @@ type SupplementSnapshot = {
   safetyCategory: string | null;
   peakMinutes: number | null;
   halfLifeMinutes: number | null;
-  kineticsType: "first_order" | "michaelis_menten" | null;
+  kineticsType: "first_order" | "michaelis_menten" | "one_compartment" | null;
   vmax: number | null;
   km: number | null;
   rdaAmount: number | null;
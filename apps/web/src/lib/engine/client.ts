@@ -13,7 +13,19 @@ export type TrafficLightStatus = "green" | "yellow" | "red";
 /**
  * Dosage unit type matching the Go engine
  */
-export type DosageUnit = "mg" | "mcg" | "g" | "IU" | "ml";
+export type DosageUnit =
+  | "mg"
+  | "mcg"
+  | "g"
+  | "IU"
+  | "ml"
+  | "drops"
+  | ServingUnit;
+
+/**
+ * Serving units, resolved to a mass through a product definition
+ */
+export type ServingUnit = "capsule" | "tablet" | "scoop" | "serving";
 
 /**
  * Dosage input for ratio calculations
@@ -22,6 +34,31 @@ export type DosageInput = {
   supplementId: string;
   amount: number;
   unit: DosageUnit;
+  // Product to resolve serving units with; defaults to the first match
+  productId?: string;
+};
+
+/**
+ * Dosage as interpreted by the Go engine, with its mass when resolvable
+ */
+export type ResolvedDosage = {
+  supplementId: string;
+  amount: number;
+  unit: DosageUnit;
+  product?: {
+    id: string;
+    name: string;
+    brand?: string;
+  };
+  amountMg?: number;
+};
+
+/**
+ * Product dose, expanded by the Go engine into its ingredients
+ */
+export type ProductDosageInput = {
+  productId: string;
+  servings: number;
 };
 
 /**
@@ -34,6 +71,12 @@ export type RatioWarning = {
   optimalRatio?: number;
   minRatio?: number;
   maxRatio?: number;
+  /** Fraction beyond minRatio and maxRatio tolerated before warning */
+  tolerance: number;
+  /** Ratio minus the violated bound (negative below min, positive above max) */
+  deviation: number;
+  /** Ratio minus the optimal ratio, when the rule has one */
+  distanceToOptimal?: number;
   warningMessage: string;
   source: {
     id: string;
@@ -57,8 +100,20 @@ export type AnalyzeResponse = {
   timingWarnings?: TimingWarning[];
   ratioWarnings?: RatioWarning[];
   ratioEvaluationGaps?: RatioEvaluationGap[];
+  resolvedDosages?: ResolvedDosage[];
+  /**
+   * Period of logged intake the ratio rules were evaluated against; absent when only
+   * the dosages were, including when the logged intake could not be loaded
+   */
+  ratioIntakeWindow?: { from: string; to: string };
 };
 
+/**
+ * What ratio rules are evaluated against: the request's dosages, or the
+ * user's logged intake plus the request's dosages as planned intake
+ */
+export type RatioIntake = "dosages" | "logged";
+
 /**
  * Check if the Go engine is configured (URL and internal key both set)
  */
@@ -97,6 +152,10 @@ function getInternalAuthHeaders(userId: string): Record<string, string> {
  * @param options - Optional parameters for analysis
  * @param options.includeTiming - Whether to include timing analysis
  * @param options.dosages - Dosage data for ratio calculations
+ * @param options.products - Multi-ingredient products taken
+ * @param options.ratioIntake - Evaluate ratios against logged daily intake
+ * @param options.intakeWindowHours - Rolling window instead of the current day
+ * @param options.timeZone - IANA time zone the current day starts in
  * @returns Analysis response from the engine
  */
 export async function analyzeInteractions(
@@ -105,6 +164,10 @@ export async function analyzeInteractions(
   options: {
     includeTiming?: boolean;
     dosages?: DosageInput[];
+    products?: ProductDosageInput[];
+    ratioIntake?: RatioIntake;
+    intakeWindowHours?: number;
+    timeZone?: string;
   } = {},
 ): Promise<AnalyzeResponse> {
   const engineUrl = getEngineUrl();
@@ -119,6 +182,10 @@ export async function analyzeInteractions(
       supplementIds,
       includeTiming: options.includeTiming ?? false,
       dosages: options.dosages,
+      products: options.products,
+      ratioIntake: options.ratioIntake,
+      intakeWindowHours: options.intakeWindowHours,
+      timeZone: options.timeZone,
     }),
   });
 
@@ -247,3 +314,77 @@ export async function checkTimingViaEngine(
 
   return response.json() as Promise<TimingCheckResponse>;
 }
+
+/**
+ * Supplement a parsed name may refer to, scored from 0 to 100
+ */
+export type SupplementCandidate = {
+  supplement: {
+    id: string;
+    name: string;
+    form?: string;
+  };
+  score: number;
+};
+
+/**
+ * Supplement mentioned in parsed command text
+ */
+export type ParsedItem = {
+  query: string;
+  amount?: number;
+  unit?: DosageUnit;
+  supplement?: SupplementCandidate["supplement"];
+  // Set when the supplement resolved and an amount was given. Serving units are
+  // converted to the amount per serving of `product`, so the unit is always loggable.
+  dosage?: {
+    supplementId: string;
+    amount: number;
+    unit: Exclude<DosageUnit, ServingUnit>;
+  };
+  product?: ResolvedDosage["product"];
+  ambiguous: boolean;
+  candidates: SupplementCandidate[];
+};
+
+/**
+ * Response from the Go engine parse endpoint
+ */
+export type ParseResponse = {
+  loggedAt: string;
+  timeDescription?: string;
+  mealContext?: "fasted" | "with_meal" | "with_fat" | "post_meal";
+  items: ParsedItem[];
+};
+
+/**
+ * Parse free-text command bar input into dosages via the Go engine
+ *
+ * @param userId - The authenticated user's ID
+ * @param text - The raw input, e.g. "2 caps zinc at 9pm"
+ * @param timeZone - IANA time zone for clock times, defaults to UTC
+ * @returns Parsed items with ranked supplement candidates
+ */
+export async function parseCommandViaEngine(
+  userId: string,
+  text: string,
+  timeZone?: string,
+): Promise<ParseResponse> {
+  const engineUrl = getEngineUrl();
+
+  const response = await fetch(`${engineUrl}/api/parse`, {
+    method: "POST",
+    headers: {
+      "Content-Type": "application/json",
+      ...getInternalAuthHeaders(userId),
+    },
+    body: JSON.stringify({ text, timeZone }),
+  });
+
+  if (!response.ok) {
+    const error = await response.text();
+    throw new Error(`Engine parse failed: ${response.status} ${error}`);
+  }
+
+  return response.json() as Promise<ParseResponse>;
+}
@@ -26,7 +26,7 @@ export type MatchedItem = ParsedItem & {
   supplementId: string;
   supplementName: string;
   resolvedDosage: number;
-  resolvedUnit: "mg" | "mcg" | "g" | "IU" | "ml";
+  resolvedUnit: "mg" | "mcg" | "g" | "IU" | "ml" | "drops";
 };
 
 export type UnmatchedItem = ParsedItem & {
@@ -43,7 +43,10 @@ export type ParseResult = {
 // Constants
 // ============================================================================
 
-const UNIT_NORMALIZATIONS: Record<string, "mg" | "mcg" | "g" | "IU" | "ml"> = {
+const UNIT_NORMALIZATIONS: Record<
+  string,
+  "mg" | "mcg" | "g" | "IU" | "ml" | "drops"
+> = {
   mg: "mg",
   milligram: "mg",
   milligrams: "mg",
@@ -63,12 +66,16 @@ const UNIT_NORMALIZATIONS: Record<string, "mg" | "mcg" | "g" | "IU" | "ml"> = {
   milliliter: "ml",
   milliliters: "ml",
   cc: "ml",
+  drop: "drops",
+  drops: "drops",
+  gtt: "drops",
+  gtts: "drops",
 };
 
 // Default dosages when user doesn't provide one (common serving sizes)
 const DEFAULT_DOSAGES: Record<
   string,
-  { dosage: number; unit: "mg" | "mcg" | "g" | "IU" | "ml" }
+  { dosage: number; unit: "mg" | "mcg" | "g" | "IU" | "ml" | "drops" }
 > = {
   "Vitamin D3": { dosage: 5000, unit: "IU" },
   "Vitamin K2 MK-7": { dosage: 100, unit: "mcg" },
@@ -236,7 +243,9 @@ function cleanSupplementName(name: string): string {
 /**
  * Normalize unit string to standard format
  */
-function normalizeUnit(unit: string): "mg" | "mcg" | "g" | "IU" | "ml" | null {
+function normalizeUnit(
+  unit: string,
+): "mg" | "mcg" | "g" | "IU" | "ml" | "drops" | null {
   const normalized = unit.toLowerCase().trim();
   return UNIT_NORMALIZATIONS[normalized] ?? null;
 }
@@ -278,7 +287,7 @@ async function matchSupplements(parsed: ParsedItem[]): Promise<ParseResult> {
       const defaultDosage = DEFAULT_DOSAGES[bestMatch.name];
       const resolvedDosage = item.dosage ?? defaultDosage?.dosage ?? 100;
       const resolvedUnit =
-        (item.unit as "mg" | "mcg" | "g" | "IU" | "ml") ??
+        (item.unit as "mg" | "mcg" | "g" | "IU" | "ml" | "drops") ??
         defaultDosage?.unit ??
         bestMatch.defaultUnit ??
         "mg";
@@ -375,7 +384,7 @@ export async function createStackFromImport(
   items: Array<{
     supplementId: string;
     dosage: number;
-    unit: "mg" | "mcg" | "g" | "IU" | "ml";
+    unit: "mg" | "mcg" | "g" | "IU" | "ml" | "drops";
   }>,
 ): Promise<{ success: boolean; stackId?: string; error?: string }> {
   const session = await getSession();
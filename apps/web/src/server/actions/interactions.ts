@@ -428,10 +428,10 @@ export async function checkRatioWarnings(
     // Note: This is simplified - real implementation would normalize units
     const ratio = sourceDosage.dosage / targetDosage.dosage;
 
-    // Check if ratio is outside acceptable range with a 15% tolerance buffer
+    // Check if ratio is outside acceptable range with the rule's tolerance buffer
     // This prevents warnings for ratios that are close enough (e.g., 33:1 when min is 40:1)
-    // Ratios within 15% of the boundary are considered acceptable
-    const toleranceFactor = 0.15;
+    // Ratios within 15% of the boundary are acceptable unless the rule sets its own
+    const toleranceFactor = rule.tolerance ?? 0.15;
     const effectiveMinRatio =
       rule.minRatio !== null ? rule.minRatio * (1 - toleranceFactor) : null;
     const effectiveMaxRatio =
@@ -20,7 +20,7 @@ import {
   type MealContextCheckResult,
 } from "~/server/services/safety";
 
-const VALID_UNITS = ["mg", "mcg", "g", "IU", "ml"] as const;
+const VALID_UNITS = ["mg", "mcg", "g", "IU", "ml", "drops"] as const;
 type DosageUnit = (typeof VALID_UNITS)[number];
 type RouteOfAdministration = (typeof routeEnum.enumValues)[number];
 type MealContext = (typeof mealContextEnum.enumValues)[number];
@@ -109,6 +109,9 @@ export async function createLog(
       id: true,
       name: true,
       elementalWeight: true,
+      mcgPerIu: true,
+      concentrationMgPerMl: true,
+      dropsPerMl: true,
       safetyCategory: true,
       defaultUnit: true,
       route: true,
@@ -271,6 +274,9 @@ export async function preCheckSafety(
       id: true,
       name: true,
       elementalWeight: true,
+      mcgPerIu: true,
+      concentrationMgPerMl: true,
+      dropsPerMl: true,
       safetyCategory: true,
       defaultUnit: true,
     },
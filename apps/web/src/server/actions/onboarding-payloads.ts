@@ -1,6 +1,6 @@
 import { type GoalKey } from "~/server/data/goal-recommendations";
 
-type DosageUnit = "mg" | "mcg" | "g" | "IU" | "ml";
+type DosageUnit = "mg" | "mcg" | "g" | "IU" | "ml" | "drops";
 type TimeSlot = "morning" | "afternoon" | "evening" | "bedtime";
 
 interface TemplateSupplementInput {
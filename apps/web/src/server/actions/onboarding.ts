@@ -272,7 +272,7 @@ export async function createStackFromOnboarding(data: {
   supplements: Array<{
     supplementId: string;
     dosage: number;
-    unit: "mg" | "mcg" | "g" | "IU" | "ml";
+    unit: "mg" | "mcg" | "g" | "IU" | "ml" | "drops";
     timeSlot?: "morning" | "afternoon" | "evening" | "bedtime";
   }>;
   goals?: GoalKey[];
@@ -27,7 +27,7 @@ const VALID_TIME_SLOTS: TimeSlot[] = [
   "bedtime",
 ];
 const VALID_FREQUENCIES: Frequency[] = ["daily", "specific_days", "as_needed"];
-const VALID_UNITS: DosageUnit[] = ["mg", "mcg", "g", "IU", "ml"];
+const VALID_UNITS: DosageUnit[] = ["mg", "mcg", "g", "IU", "ml", "drops"];
 const VALID_DAYS = [
   "monday",
   "tuesday",
@@ -13,7 +13,7 @@ import {
   type SupplementWithSafety,
 } from "~/server/services/safety";
 
-const VALID_UNITS = ["mg", "mcg", "g", "IU", "ml"] as const;
+const VALID_UNITS = ["mg", "mcg", "g", "IU", "ml", "drops"] as const;
 type DosageUnit = (typeof VALID_UNITS)[number];
 
 export type LogStackResult =
@@ -360,6 +360,9 @@ export async function logStackWithSafetyCheck(
               id: true,
               name: true,
               elementalWeight: true,
+              mcgPerIu: true,
+              concentrationMgPerMl: true,
+              dropsPerMl: true,
               safetyCategory: true,
               defaultUnit: true,
             },
@@ -464,6 +467,9 @@ export async function preCheckStackSafety(
               id: true,
               name: true,
               elementalWeight: true,
+              mcgPerIu: true,
+              concentrationMgPerMl: true,
+              dropsPerMl: true,
               safetyCategory: true,
               defaultUnit: true,
             },
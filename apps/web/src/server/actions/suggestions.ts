@@ -10,7 +10,7 @@ export type Suggestion = {
   supplementName: string;
   reason: string;
   dosage: number;
-  unit: "mg" | "mcg" | "g" | "IU" | "ml";
+  unit: "mg" | "mcg" | "g" | "IU" | "ml" | "drops";
   priority: "high" | "medium" | "low";
 };
 
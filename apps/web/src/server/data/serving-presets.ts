@@ -6,7 +6,7 @@
 export type ServingPreset = {
   label: string;
   dosage: number;
-  unit: "mg" | "mcg" | "g" | "IU" | "ml";
+  unit: "mg" | "mcg" | "g" | "IU" | "ml" | "drops";
 };
 
 export const SERVING_PRESETS: Record<string, ServingPreset[]> = {
@@ -1,6 +1,7 @@
-import { relations } from "drizzle-orm";
+import { relations, sql } from "drizzle-orm";
 import {
   boolean,
+  check,
   customType,
   index,
   integer,
@@ -57,6 +58,15 @@ export const dosageUnitEnum = pgEnum("dosage_unit", [
   "g",
   "IU",
   "ml",
+  "drops",
+]);
+
+// Units counted in servings of a product; see product_ingredient
+export const servingUnitEnum = pgEnum("serving_unit", [
+  "capsule",
+  "tablet",
+  "scoop",
+  "serving",
 ]);
 
 export const routeEnum = pgEnum("route_of_administration", [
@@ -81,6 +91,7 @@ export const mealContextEnum = pgEnum("meal_context", [
 export const kineticsTypeEnum = pgEnum("kinetics_type", [
   "first_order", // Standard exponential decay (default)
   "michaelis_menten", // Capacity-limited saturable transport
+  "one_compartment", // Bateman oral model (first-order absorption + elimination)
 ]);
 
 // Biomarker type for blood-work calibration
@@ -163,24 +174,35 @@ export const suggestionFilterLevelEnum = pgEnum("suggestion_filter_level", [
 // Ratio Rules (for stoichiometric imbalance detection)
 // ============================================================================
 
-export const ratioRule = pgTable("ratio_rule", {
-  id: uuid("id").primaryKey().defaultRandom(),
-  sourceSupplementId: uuid("source_supplement_id")
-    .notNull()
-    .references(() => supplement.id, { onDelete: "cascade" }),
-  targetSupplementId: uuid("target_supplement_id")
-    .notNull()
-    .references(() => supplement.id, { onDelete: "cascade" }),
-  minRatio: real("min_ratio"), // source:target min (e.g., 8:1 for Zn:Cu)
-  maxRatio: real("max_ratio"), // source:target max (e.g., 15:1 for Zn:Cu)
-  optimalRatio: real("optimal_ratio"), // optimal ratio (e.g., 10:1 for Zn:Cu)
-  warningMessage: text("warning_message").notNull(),
-  severity: severityEnum("severity").notNull(),
-  researchUrl: text("research_url"), // Link to Examine.com or study for grounding
-  createdAt: timestamp("created_at")
-    .$defaultFn(() => new Date())
-    .notNull(),
-});
+export const ratioRule = pgTable(
+  "ratio_rule",
+  {
+    id: uuid("id").primaryKey().defaultRandom(),
+    sourceSupplementId: uuid("source_supplement_id")
+      .notNull()
+      .references(() => supplement.id, { onDelete: "cascade" }),
+    targetSupplementId: uuid("target_supplement_id")
+      .notNull()
+      .references(() => supplement.id, { onDelete: "cascade" }),
+    minRatio: real("min_ratio"), // source:target min (e.g., 8:1 for Zn:Cu)
+    maxRatio: real("max_ratio"), // source:target max (e.g., 15:1 for Zn:Cu)
+    optimalRatio: real("optimal_ratio"), // optimal ratio (e.g., 10:1 for Zn:Cu)
+    // Fraction beyond min/max tolerated before warning (null = 0.15)
+    tolerance: real("tolerance"),
+    warningMessage: text("warning_message").notNull(),
+    severity: severityEnum("severity").notNull(),
+    researchUrl: text("research_url"), // Link to Examine.com or study for grounding
+    createdAt: timestamp("created_at")
+      .$defaultFn(() => new Date())
+      .notNull(),
+  },
+  (t) => [
+    check(
+      "ratio_rule_tolerance_check",
+      sql`${t.tolerance} >= 0 AND ${t.tolerance} < 1`,
+    ),
+  ],
+);
 
 // ============================================================================
 // Auth Tables (BetterAuth)
@@ -278,6 +300,21 @@ export type SuggestionProfile = {
   minExperienceLevel: "beginner" | "intermediate" | "advanced";
 };
 
+/**
+ * Optional rate constants for the engine's compartment models (1/min).
+ * Omitted keys fall back to engine defaults.
+ */
+export type CompartmentParams = {
+  /** Central → peripheral transfer rate (two_compartment) */
+  k12?: number;
+  /** Peripheral → central transfer rate (two_compartment) */
+  k21?: number;
+  /** Fraction of elimination excreted into bile, 0-1 (enterohepatic) */
+  biliaryFraction?: number;
+  /** Gallbladder → gut release rate (enterohepatic) */
+  bileReleaseRate?: number;
+};
+
 export const supplement = pgTable(
   "supplement",
   {
@@ -285,6 +322,15 @@ export const supplement = pgTable(
     name: text("name").notNull().unique(),
     form: text("form"),
     elementalWeight: real("elemental_weight"),
+    // Formula of the form (e.g. "Mg(C2H4NO2)2") and the element elementalWeight
+    // refers to; `elemental -audit` checks the two agree
+    molecularFormula: text("molecular_formula"),
+    elementalSymbol: text("elemental_symbol"),
+    // mcg per IU of this form (e.g. 0.025 for cholecalciferol); null when IU doesn't apply
+    mcgPerIu: real("mcg_per_iu"),
+    // Liquids: mg of the compound per ml, and the dropper's drops per ml (null = 20)
+    concentrationMgPerMl: real("concentration_mg_per_ml"),
+    dropsPerMl: real("drops_per_ml"),
     defaultUnit: dosageUnitEnum("default_unit").default("mg"),
     // New fields for Supplement Intelligence
     description: text("description"), // Bio-hacker benefit (1 sentence)
@@ -312,6 +358,8 @@ export const supplement = pgTable(
     halfLifeMinutes: integer("half_life_minutes"), // Elimination half-life (t½)
     absorptionWindowMinutes: integer("absorption_window_minutes"), // Active absorption period
     bioavailabilityPercent: real("bioavailability_percent"), // % systemic availability
+    // Volume of distribution (L/kg); enables absolute mg/L concentrations
+    volumeOfDistribution: real("volume_of_distribution"),
     // Michaelis-Menten kinetics parameters (for saturable transporters)
     // Used for Vitamin C, Magnesium, Iron, and other capacity-limited supplements
     kineticsType: kineticsTypeEnum("kinetics_type").default("first_order"),
@@ -319,6 +367,26 @@ export const supplement = pgTable(
     km: real("km"), // Michaelis constant (mg) - substrate concentration at half Vmax
     absorptionSaturationDose: real("absorption_saturation_dose"), // Dose (mg) above which absorption efficiency drops
     rdaAmount: real("rda_amount"), // Recommended Daily Allowance (mg) - for heuristic dampening
+    // One-compartment (Bateman) absorption rate constant ka (1/min)
+    // Derived from peakMinutes and halfLifeMinutes by the engine when NULL
+    absorptionRateConstant: real("absorption_rate_constant"),
+    // Numerical compartment model solved by the engine (e.g. "two_compartment",
+    // "enterohepatic"); overrides kineticsType when set
+    compartmentModel: text("compartment_model"),
+    compartmentParams: jsonb("compartment_params").$type<CompartmentParams>(),
+    // Pharmacodynamic Emax/Hill model: E = Emax * C^n / (EC50^n + C^n)
+    // Requires volumeOfDistribution, since EC50 is in mg/L
+    effectLabel: text("effect_label"), // e.g. "alertness", "relaxation"
+    emax: real("emax"), // Maximal effect (effect units, e.g. 0-100 score)
+    ec50: real("ec50"), // Concentration (mg/L) producing half the maximal effect
+    hillCoefficient: real("hill_coefficient"), // Curve steepness (1 when NULL)
+    // Between-subject coefficients of variation (SD / median) for uncertainty
+    // bands; the engine assumes 0.3 when NULL
+    halfLifeCv: real("half_life_cv"),
+    peakCv: real("peak_cv"),
+    bioavailabilityCv: real("bioavailability_cv"),
+    vmaxCv: real("vmax_cv"),
+    kmCv: real("km_cv"),
     // Protocol frequency suggestions (for Master Protocol feature)
     // System recommendation for how often to take this supplement
     suggestedFrequency: frequencyEnum("suggested_frequency"),
@@ -740,6 +808,49 @@ export const protocolItem = pgTable(
   ],
 );
 
+/**
+ * Product - A branded supplement product dosed in servings (capsules,
+ * tablets, scoops). Catalog products have no user; user products are private.
+ */
+export const product = pgTable(
+  "product",
+  {
+    id: uuid("id").primaryKey().defaultRandom(),
+    userId: text("user_id").references(() => user.id, { onDelete: "cascade" }),
+    name: text("name").notNull(),
+    brand: text("brand"),
+    servingUnit: servingUnitEnum("serving_unit").notNull(),
+    createdAt: timestamp("created_at")
+      .$defaultFn(() => new Date())
+      .notNull(),
+  },
+  (t) => [index("product_user_idx").on(t.userId)],
+);
+
+/**
+ * Product Ingredient - The amount of a supplement in one serving of a product,
+ * e.g. 5000 IU of Vitamin D3 per capsule.
+ */
+export const productIngredient = pgTable(
+  "product_ingredient",
+  {
+    id: uuid("id").primaryKey().defaultRandom(),
+    productId: uuid("product_id")
+      .notNull()
+      .references(() => product.id, { onDelete: "cascade" }),
+    supplementId: uuid("supplement_id")
+      .notNull()
+      .references(() => supplement.id, { onDelete: "cascade" }),
+    // Amount of the supplement compound per serving, before elemental weight
+    amount: real("amount").notNull(),
+    unit: dosageUnitEnum("unit").notNull(),
+  },
+  (t) => [
+    index("product_ingredient_product_idx").on(t.productId),
+    index("product_ingredient_supplement_idx").on(t.supplementId),
+  ],
+);
+
 // ============================================================================
 // Relations
 // ============================================================================
@@ -754,6 +865,7 @@ export const userRelations = relations(user, ({ many, one }) => ({
   preference: one(userPreference),
   dismissedSuggestions: many(dismissedSuggestion),
   protocol: one(protocol),
+  products: many(product),
 }));
 
 export const accountRelations = relations(account, ({ one }) => ({
@@ -776,6 +888,7 @@ export const supplementRelations = relations(supplement, ({ many }) => ({
   logs: many(log),
   knowledge: many(supplementKnowledge),
   protocolItems: many(protocolItem),
+  productIngredients: many(productIngredient),
 }));
 
 export const interactionRelations = relations(interaction, ({ one }) => ({
@@ -896,3 +1009,22 @@ export const protocolItemRelations = relations(protocolItem, ({ one }) => ({
     references: [supplement.id],
   }),
 }));
+
+export const productRelations = relations(product, ({ one, many }) => ({
+  user: one(user, { fields: [product.userId], references: [user.id] }),
+  ingredients: many(productIngredient),
+}));
+
+export const productIngredientRelations = relations(
+  productIngredient,
+  ({ one }) => ({
+    product: one(product, {
+      fields: [productIngredient.productId],
+      references: [product.id],
+    }),
+    supplement: one(supplement, {
+      fields: [productIngredient.supplementId],
+      references: [supplement.id],
+    }),
+  }),
+);
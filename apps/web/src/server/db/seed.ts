@@ -1,4 +1,4 @@
-import { eq } from "drizzle-orm";
+import { eq, isNull } from "drizzle-orm";
 import { db } from "./index";
 import {
   supplement,
@@ -8,6 +8,8 @@ import {
   user,
   stack,
   stackItem,
+  product,
+  productIngredient,
   type SuggestionProfile,
 } from "./schema";
 
@@ -120,6 +122,8 @@ const supplements = [
     name: "Magnesium Glycinate",
     form: "Magnesium Bisglycinate",
     elementalWeight: 14.1,
+    molecularFormula: "Mg(C2H4NO2)2",
+    elementalSymbol: "Mg",
     defaultUnit: "mg" as const,
     aliases: [
       "mag glycinate",
@@ -152,6 +156,8 @@ const supplements = [
     name: "Magnesium Citrate",
     form: "Magnesium Citrate",
     elementalWeight: 16.2,
+    molecularFormula: "Mg3(C6H5O7)2",
+    elementalSymbol: "Mg",
     defaultUnit: "mg" as const,
     aliases: ["mag citrate", "natural calm"],
     description:
@@ -177,6 +183,8 @@ const supplements = [
     name: "Magnesium L-Threonate",
     form: "Magnesium L-Threonate",
     elementalWeight: 8.3,
+    molecularFormula: "Mg(C4H7O5)2",
+    elementalSymbol: "Mg",
     defaultUnit: "mg" as const,
     aliases: ["mag threonate", "magtein", "brain magnesium"],
     description:
@@ -204,6 +212,8 @@ const supplements = [
     name: "Magnesium Oxide",
     form: "Magnesium Oxide",
     elementalWeight: 60.3,
+    molecularFormula: "MgO",
+    elementalSymbol: "Mg",
     defaultUnit: "mg" as const,
     aliases: ["mag oxide", "magox"],
     description:
@@ -230,6 +240,8 @@ const supplements = [
     name: "Magnesium Malate",
     form: "Magnesium Malate",
     elementalWeight: 15.5,
+    molecularFormula: "MgC4H4O5",
+    elementalSymbol: "Mg",
     defaultUnit: "mg" as const,
     aliases: ["mag malate", "malic acid magnesium"],
     description:
@@ -261,6 +273,8 @@ const supplements = [
     name: "Zinc Picolinate",
     form: "Zinc Picolinate",
     elementalWeight: 21.0,
+    molecularFormula: "Zn(C6H4NO2)2",
+    elementalSymbol: "Zn",
     defaultUnit: "mg" as const,
     aliases: ["zinc", "zn picolinate"],
     description:
@@ -276,11 +290,14 @@ const supplements = [
     halfLifeMinutes: 300, // 5h plasma half-life
     absorptionWindowMinutes: 180,
     bioavailabilityPercent: 60,
+    absorptionSaturationDose: 25, // mg elemental - Fractional absorption falls steeply above ~25mg per dose
   },
   {
     name: "Zinc Gluconate",
     form: "Zinc Gluconate",
     elementalWeight: 14.3,
+    molecularFormula: "C12H22O14Zn",
+    elementalSymbol: "Zn",
     defaultUnit: "mg" as const,
     aliases: ["zinc gluconate", "zn gluconate"],
     description: "Common zinc form found in lozenges; supports immune function",
@@ -294,6 +311,7 @@ const supplements = [
     halfLifeMinutes: 300,
     absorptionWindowMinutes: 180,
     bioavailabilityPercent: 40,
+    absorptionSaturationDose: 25,
   },
 
   // ============================================
@@ -426,13 +444,14 @@ const supplements = [
   },
 
   // ============================================
-  // OTHER VITAMINS (4)
+  // OTHER VITAMINS (8)
   // PK data from Examine.com & clinical studies
   // ============================================
   {
     name: "Vitamin D3",
     form: "Cholecalciferol",
     elementalWeight: 100,
+    mcgPerIu: 0.025, // 40 IU = 1 mcg
     defaultUnit: "IU" as const,
     aliases: [
       "d3",
@@ -508,6 +527,8 @@ const supplements = [
     name: "Vitamin E",
     form: "Mixed Tocopherols",
     elementalWeight: 100,
+    // No mcgPerIu: IU is only defined for alpha-tocopherol, and mixed tocopherols are
+    // mostly gamma and delta forms
     defaultUnit: "IU" as const,
     aliases: ["vit e", "tocopherol", "e"],
     description:
@@ -524,6 +545,87 @@ const supplements = [
     absorptionWindowMinutes: 480,
     bioavailabilityPercent: 40,
   },
+  {
+    name: "Vitamin E (Synthetic)",
+    form: "dl-Alpha-Tocopherol",
+    elementalWeight: 100,
+    mcgPerIu: 450, // Synthetic dl-alpha-tocopherol: 1 IU = 0.45 mg
+    defaultUnit: "IU" as const,
+    aliases: ["dl-alpha-tocopherol", "synthetic vitamin e", "all-rac-tocopherol"],
+    description:
+      "Synthetic vitamin E with about half the activity of the natural form per mg",
+    mechanism:
+      "Only the RRR stereoisomer is retained by hepatic alpha-tocopherol transfer protein",
+    researchUrl: "https://examine.com/supplements/vitamin-e/",
+    category: "vitamin" as SupplementCategory,
+    commonGoals: ["health"],
+    safetyCategory: "vitamin-e" as SafetyCategoryKey,
+    peakMinutes: 480,
+    halfLifeMinutes: 2880,
+    absorptionWindowMinutes: 480,
+    bioavailabilityPercent: 40,
+  },
+  {
+    name: "Vitamin D2",
+    form: "Ergocalciferol",
+    elementalWeight: 100,
+    mcgPerIu: 0.025, // 40 IU = 1 mcg, same as D3
+    defaultUnit: "IU" as const,
+    aliases: ["d2", "ergocalciferol", "vegan vitamin d"],
+    description:
+      "Plant-derived vitamin D that raises serum 25(OH)D less durably than D3",
+    mechanism:
+      "Hydroxylated to 25(OH)D2 and calcitriol analogues that bind VDR receptors",
+    researchUrl: "https://examine.com/supplements/vitamin-d/",
+    category: "vitamin" as SupplementCategory,
+    commonGoals: ["health"],
+    safetyCategory: "vitamin-d3" as SafetyCategoryKey,
+    peakMinutes: 720,
+    halfLifeMinutes: 12960, // Shorter than D3
+    absorptionWindowMinutes: 1440,
+    bioavailabilityPercent: 70,
+  },
+  {
+    name: "Vitamin A",
+    form: "Retinyl Palmitate",
+    elementalWeight: 100,
+    mcgPerIu: 0.3, // As retinol: 1 IU = 0.3 mcg
+    defaultUnit: "IU" as const,
+    aliases: ["vit a", "retinol", "retinyl palmitate", "preformed vitamin a"],
+    description:
+      "Preformed vitamin A essential for vision, immunity, and epithelial health",
+    mechanism:
+      "Retinoic acid binds RAR/RXR nuclear receptors to regulate gene expression",
+    researchUrl: "https://examine.com/supplements/vitamin-a/",
+    category: "vitamin" as SupplementCategory,
+    commonGoals: ["health"],
+    safetyCategory: "vitamin-a" as SafetyCategoryKey,
+    peakMinutes: 240,
+    halfLifeMinutes: 1440, // Plasma; liver stores turn over far more slowly
+    absorptionWindowMinutes: 360,
+    bioavailabilityPercent: 75,
+  },
+  {
+    name: "Beta-Carotene",
+    form: "Beta-Carotene",
+    elementalWeight: 100,
+    mcgPerIu: 0.6, // Supplemental beta-carotene: 1 IU = 0.6 mcg
+    defaultUnit: "IU" as const,
+    aliases: ["provitamin a", "carotene", "b-carotene"],
+    description:
+      "Provitamin A carotenoid converted to retinol as needed, without retinol toxicity",
+    mechanism:
+      "Cleaved by BCO1 in the gut into retinal; conversion is down-regulated when stores are full",
+    researchUrl: "https://examine.com/supplements/beta-carotene/",
+    category: "vitamin" as SupplementCategory,
+    commonGoals: ["health"],
+    // The vitamin A UL applies to preformed retinol only
+    safetyCategory: null as SafetyCategoryKey,
+    peakMinutes: 360,
+    halfLifeMinutes: 7200, // ~5 days
+    absorptionWindowMinutes: 480,
+    bioavailabilityPercent: 15,
+  },
 
   // ============================================
   // MINERALS (8)
@@ -533,6 +635,8 @@ const supplements = [
     name: "Iron Bisglycinate",
     form: "Ferrous Bisglycinate",
     elementalWeight: 27.4,
+    molecularFormula: "Fe(C2H4NO2)2",
+    elementalSymbol: "Fe",
     defaultUnit: "mg" as const,
     aliases: ["iron", "ferrous", "fe", "gentle iron"],
     description:
@@ -566,6 +670,8 @@ const supplements = [
     name: "Copper Bisglycinate",
     form: "Copper Bisglycinate",
     elementalWeight: 30.0,
+    molecularFormula: "Cu(C2H4NO2)2",
+    elementalSymbol: "Cu",
     defaultUnit: "mg" as const,
     aliases: ["copper", "cu"],
     description:
@@ -590,6 +696,8 @@ const supplements = [
     name: "Selenium",
     form: "Selenomethionine",
     elementalWeight: 40.3,
+    molecularFormula: "C5H11NO2Se",
+    elementalSymbol: "Se",
     defaultUnit: "mcg" as const,
     aliases: ["se", "selenomethionine"],
     description:
@@ -610,6 +718,8 @@ const supplements = [
     name: "Calcium",
     form: "Calcium Citrate",
     elementalWeight: 24.1,
+    molecularFormula: "Ca3(C6H5O7)2",
+    elementalSymbol: "Ca",
     defaultUnit: "mg" as const,
     aliases: ["ca", "calcium citrate", "cal"],
     description:
@@ -625,11 +735,14 @@ const supplements = [
     halfLifeMinutes: 480, // 8h plasma
     absorptionWindowMinutes: 240,
     bioavailabilityPercent: 25,
+    absorptionSaturationDose: 500, // mg elemental - Active transport saturates above ~500mg per dose
   },
   {
     name: "Potassium",
     form: "Potassium Citrate",
     elementalWeight: 38.3,
+    molecularFormula: "K3C6H5O7",
+    elementalSymbol: "K",
     defaultUnit: "mg" as const,
     aliases: ["k", "potassium citrate"],
     description:
@@ -669,6 +782,8 @@ const supplements = [
     name: "Iodine",
     form: "Potassium Iodide",
     elementalWeight: 76.5,
+    molecularFormula: "KI",
+    elementalSymbol: "I",
     defaultUnit: "mcg" as const,
     aliases: ["iodide", "potassium iodide", "ki"],
     description:
@@ -693,6 +808,8 @@ const supplements = [
     name: "Chromium",
     form: "Chromium Picolinate",
     elementalWeight: 12.4,
+    molecularFormula: "Cr(C6H4NO2)3",
+    elementalSymbol: "Cr",
     defaultUnit: "mcg" as const,
     aliases: ["chromium picolinate", "cr"],
     description:
@@ -712,6 +829,8 @@ const supplements = [
     name: "Molybdenum",
     form: "Sodium Molybdate",
     elementalWeight: 39.7,
+    molecularFormula: "Na2MoO4·2H2O",
+    elementalSymbol: "Mo",
     defaultUnit: "mcg" as const,
     aliases: ["molybdenum", "molybdate", "moly", "mo", "sodium molybdate"],
     description:
@@ -1736,6 +1855,8 @@ const supplements = [
     name: "Zinc Carnosine",
     form: "Zinc L-Carnosine",
     elementalWeight: 23,
+    molecularFormula: "C9H12N4O3Zn",
+    elementalSymbol: "Zn",
     defaultUnit: "mg" as const,
     aliases: ["zinc carnosine", "pepzin gi", "polaprezinc"],
     description:
@@ -1847,6 +1968,9 @@ async function seed() {
         set: {
           form: supp.form,
           elementalWeight: supp.elementalWeight,
+          molecularFormula: supp.molecularFormula ?? null,
+          elementalSymbol: supp.elementalSymbol ?? null,
+          mcgPerIu: supp.mcgPerIu ?? null,
           defaultUnit: supp.defaultUnit,
           description: supp.description,
           mechanism: supp.mechanism,
@@ -2704,6 +2828,63 @@ async function seed() {
     console.log("Created Foundational Longevity protocol");
   }
 
+  // ============================================
+  // CATALOG PRODUCTS
+  // ============================================
+  console.log("Seeding catalog products...");
+
+  // Delete existing catalog products (to refresh their ingredients)
+  await db.delete(product).where(isNull(product.userId));
+
+  // Ingredient amounts are of the supplement compound per serving; the engine
+  // applies each supplement's elemental weight
+  const [multivitamin] = await db
+    .insert(product)
+    .values({ name: "Daily Multivitamin", servingUnit: "tablet" })
+    .returning();
+
+  if (multivitamin) {
+    await db.insert(productIngredient).values([
+      {
+        productId: multivitamin.id,
+        supplementId: supplementMap.get("Zinc Picolinate")!,
+        amount: 71, // 15mg elemental zinc
+        unit: "mg" as const,
+      },
+      {
+        productId: multivitamin.id,
+        supplementId: supplementMap.get("Copper Bisglycinate")!,
+        amount: 3.3, // 1mg elemental copper
+        unit: "mg" as const,
+      },
+      {
+        productId: multivitamin.id,
+        supplementId: supplementMap.get("Selenium")!,
+        amount: 136, // 55mcg elemental selenium
+        unit: "mcg" as const,
+      },
+      {
+        productId: multivitamin.id,
+        supplementId: supplementMap.get("Vitamin D3")!,
+        amount: 1000,
+        unit: "IU" as const,
+      },
+      {
+        productId: multivitamin.id,
+        supplementId: supplementMap.get("Vitamin C")!,
+        amount: 90,
+        unit: "mg" as const,
+      },
+      {
+        productId: multivitamin.id,
+        supplementId: supplementMap.get("Vitamin B6")!,
+        amount: 2,
+        unit: "mg" as const,
+      },
+    ]);
+    console.log("Created Daily Multivitamin product");
+  }
+
   console.log("Seed completed!");
 }
 
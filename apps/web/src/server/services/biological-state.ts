@@ -136,8 +136,15 @@ export type TimelineDataPoint = {
 // Pharmacokinetic Calculations
 // ============================================================================
 
-/** Kinetics type for dispatch */
-export type KineticsType = "first_order" | "michaelis_menten";
+/**
+ * Kinetics type for dispatch.
+ * "one_compartment" is modeled by the engine; this path approximates it as
+ * first-order.
+ */
+export type KineticsType =
+  | "first_order"
+  | "michaelis_menten"
+  | "one_compartment";
 
 /** Parameters for concentration calculation with extended PK data */
 export type ConcentrationParams = {
@@ -452,8 +452,8 @@ async function checkRatioIssues(
 
     const ratio = sourceElemental / targetElemental;
 
-    // Check if ratio is outside acceptable range with 15% tolerance
-    const toleranceFactor = 0.15;
+    // Check if ratio is outside acceptable range with the rule's tolerance
+    const toleranceFactor = rule.tolerance ?? 0.15;
     const effectiveMinRatio =
       rule.minRatio !== null ? rule.minRatio * (1 - toleranceFactor) : null;
     const effectiveMaxRatio =
@@ -46,6 +46,12 @@ export type SupplementWithSafety = {
   id: string;
   name: string;
   elementalWeight: number | null;
+  /** mcg per IU of this form; null when IU doesn't apply */
+  mcgPerIu: number | null;
+  /** mg of the compound per ml, for liquids dosed in ml or drops */
+  concentrationMgPerMl: number | null;
+  /** Drops per ml of the product's dropper; null uses DEFAULT_DROPS_PER_ML */
+  dropsPerMl: number | null;
   safetyCategory: string | null;
   defaultUnit: string | null;
   isResearchChemical?: boolean;
@@ -55,11 +61,27 @@ export type SupplementWithSafety = {
 // Unit Conversion
 // ============================================================================
 
+/** Metric dropper convention: 1 drop = 0.05 ml */
+const DEFAULT_DROPS_PER_ML = 20;
+
+/** Supplement-specific factors for units without a fixed mass */
+type UnitConversion = {
+  mcgPerIu?: number | null;
+  concentrationMgPerMl?: number | null;
+  dropsPerMl?: number | null;
+};
+
 /**
  * Convert a dosage to milligrams.
- * IU cannot be converted (requires requiredUnit enforcement).
+ * IU converts only with the supplement's mcg per IU, and ml and drops only
+ * with its concentration in mg/ml.
  */
-function convertToMg(dosage: number, unit: string): number | null {
+function convertToMg(
+  dosage: number,
+  unit: string,
+  conversion: UnitConversion = {},
+): number | null {
+  const { mcgPerIu, concentrationMgPerMl, dropsPerMl } = conversion;
   switch (unit) {
     case "mg":
       return dosage;
@@ -68,8 +90,14 @@ function convertToMg(dosage: number, unit: string): number | null {
     case "g":
       return dosage * 1000;
     case "IU":
-      // IU cannot be converted - must use requiredUnit enforcement
-      return null;
+      // IU is form-specific - without a factor, requiredUnit enforcement applies
+      return mcgPerIu ? (dosage * mcgPerIu) / 1000 : null;
+    case "ml":
+      return concentrationMgPerMl ? dosage * concentrationMgPerMl : null;
+    case "drops":
+      return concentrationMgPerMl
+        ? (dosage / (dropsPerMl || DEFAULT_DROPS_PER_ML)) * concentrationMgPerMl
+        : null;
     default:
       return null;
   }
@@ -77,21 +105,20 @@ function convertToMg(dosage: number, unit: string): number | null {
 
 /**
  * Convert dosage to the target unit for comparison.
- * Returns null if conversion is not possible (e.g., IU to mg).
+ * Returns null if conversion is not possible (e.g., IU to mg without mcgPerIu,
+ * or ml without concentrationMgPerMl).
  */
 function convertDosage(
   dosage: number,
   fromUnit: string,
   toUnit: string,
+  conversion: UnitConversion = {},
 ): number | null {
   // Same unit, no conversion needed
   if (fromUnit === toUnit) return dosage;
 
-  // IU cannot be converted
-  if (fromUnit === "IU" || toUnit === "IU") return null;
-
   // Convert to mg first, then to target
-  const inMg = convertToMg(dosage, fromUnit);
+  const inMg = convertToMg(dosage, fromUnit, conversion);
   if (inMg === null) return null;
 
   switch (toUnit) {
@@ -101,6 +128,8 @@ function convertDosage(
       return inMg * 1000;
     case "g":
       return inMg / 1000;
+    case "IU":
+      return conversion.mcgPerIu ? (inMg * 1000) / conversion.mcgPerIu : null;
     default:
       return null;
   }
@@ -201,6 +230,9 @@ export async function getElementalTotal(
     columns: {
       id: true,
       elementalWeight: true,
+      mcgPerIu: true,
+      concentrationMgPerMl: true,
+      dropsPerMl: true,
     },
   });
 
@@ -210,6 +242,7 @@ export async function getElementalTotal(
   const elementalWeightMap = new Map(
     supplementsInCategory.map((s) => [s.id, s.elementalWeight]),
   );
+  const conversionMap = new Map(supplementsInCategory.map((s) => [s.id, s]));
 
   // Get today's logs for these supplements
   const logs = await db.query.log.findMany({
@@ -241,7 +274,12 @@ export async function getElementalTotal(
     );
 
     // Convert to target unit
-    const converted = convertDosage(elementalDosage, logEntry.unit, targetUnit);
+    const converted = convertDosage(
+      elementalDosage,
+      logEntry.unit,
+      targetUnit,
+      conversionMap.get(logEntry.supplementId),
+    );
     if (converted !== null) {
       total += converted;
     } else if (logEntry.unit === targetUnit) {
@@ -322,8 +360,12 @@ export async function checkSafetyLimit(
     };
   }
 
-  // Check for required unit (IU supplements must be logged in IU)
-  if (safetyLimit.requiredUnit && unit !== safetyLimit.requiredUnit) {
+  // Check for required unit (IU supplements without an mcg per IU must be logged in IU)
+  if (
+    safetyLimit.requiredUnit &&
+    unit !== safetyLimit.requiredUnit &&
+    !supplementData.mcgPerIu
+  ) {
     return {
       isSafe: false,
       isHardLimit: true,
@@ -349,6 +391,7 @@ export async function checkSafetyLimit(
     elementalDosage,
     unit,
     safetyLimit.unit,
+    supplementData,
   );
 
   // If we can't convert (IU mismatch), check if units match directly
@@ -453,7 +496,11 @@ export async function checkStackSafety(
     if (!safetyLimit) continue;
 
     // Check required unit
-    if (safetyLimit.requiredUnit && item.unit !== safetyLimit.requiredUnit) {
+    if (
+      safetyLimit.requiredUnit &&
+      item.unit !== safetyLimit.requiredUnit &&
+      !item.supplement.mcgPerIu
+    ) {
       return {
         isSafe: false,
         isHardLimit: true,
@@ -479,6 +526,7 @@ export async function checkStackSafety(
       elementalDosage,
       item.unit,
       safetyLimit.unit,
+      item.supplement,
     );
     const doseInLimitUnit =
       convertedDosage !== null